@@ -3,7 +3,6 @@ package main
 import (
 	"log"
 
-	"github.com/Rakhulsr/foodcourt/config"
 	"github.com/Rakhulsr/foodcourt/pkg/server"
 	"github.com/joho/godotenv"
 )
@@ -16,8 +15,6 @@ func main() {
 		log.Println("No .env file found or failed to load")
 	}
 
-	config.InitXendit()
-
 	if err := server.Run(); err != nil {
 		log.Fatal("Server failed:", err)
 	}
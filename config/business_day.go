@@ -0,0 +1,30 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BusinessDay returns the business day t falls in, as "2006-01-02". A day starts at
+// BUSINESS_DAY_START_HOUR (0-23, default 0), so a food court open past midnight keeps
+// counting queue numbers until it closes.
+func BusinessDay(t time.Time) string {
+	return t.Add(-time.Duration(businessDayStartHour()) * time.Hour).Format("2006-01-02")
+}
+
+func businessDayStartHour() int {
+	raw := strings.TrimSpace(os.Getenv("BUSINESS_DAY_START_HOUR"))
+	if raw == "" {
+		return 0
+	}
+
+	hour, err := strconv.Atoi(raw)
+	if err != nil || hour < 0 || hour > 23 {
+		log.Printf("Warning: invalid BUSINESS_DAY_START_HOUR=%q, using 0", raw)
+		return 0
+	}
+	return hour
+}
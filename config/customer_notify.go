@@ -0,0 +1,59 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CustomerMessageTemplate returns the text/template for one customer update, read
+// from CUSTOMER_MSG_<EVENT> (e.g. CUSTOMER_MSG_ORDER_READY) with "\n" for line
+// breaks. It is empty when the built-in text should be used.
+func CustomerMessageTemplate(event string) string {
+	raw := os.Getenv("CUSTOMER_MSG_" + strings.ToUpper(event))
+	return strings.ReplaceAll(raw, `\n`, "\n")
+}
+
+// QuietHours returns the hours during which customers are not messaged, read from
+// CUSTOMER_QUIET_HOURS as "start-end" in local time (e.g. "22-7"). ok is false when
+// no quiet hours are set.
+func QuietHours() (start int, end int, ok bool) {
+	raw := strings.TrimSpace(os.Getenv("CUSTOMER_QUIET_HOURS"))
+	if raw == "" {
+		return 0, 0, false
+	}
+
+	from, to, found := strings.Cut(raw, "-")
+	start, errStart := strconv.Atoi(strings.TrimSpace(from))
+	end, errEnd := strconv.Atoi(strings.TrimSpace(to))
+	if !found || errStart != nil || errEnd != nil || start < 0 || start > 23 || end < 0 || end > 23 || start == end {
+		log.Printf("Warning: invalid CUSTOMER_QUIET_HOURS=%q, quiet hours off", raw)
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// QuietUntil reports whether t falls in the quiet hours and, if so, when they end.
+func QuietUntil(t time.Time) (time.Time, bool) {
+	start, end, ok := QuietHours()
+	if !ok {
+		return time.Time{}, false
+	}
+
+	hour := t.Hour()
+	quiet := hour >= start && hour < end
+	if start > end {
+		quiet = hour >= start || hour < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(t.Year(), t.Month(), t.Day(), end, 0, 0, 0, t.Location())
+	if !until.After(t) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
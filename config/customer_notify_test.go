@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietUntil(t *testing.T) {
+	day := func(d, hour, min int) time.Time {
+		return time.Date(2026, 10, d, hour, min, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name      string
+		hours     string
+		now       time.Time
+		wantQuiet bool
+		wantUntil time.Time
+	}{
+		{"off", "", day(18, 23, 0), false, time.Time{}},
+		{"invalid", "22", day(18, 23, 0), false, time.Time{}},
+		{"same hour", "7-7", day(18, 7, 0), false, time.Time{}},
+		{"before overnight window", "22-7", day(18, 21, 59), false, time.Time{}},
+		{"overnight evening", "22-7", day(18, 22, 0), true, day(19, 7, 0)},
+		{"overnight after midnight", "22-7", day(19, 3, 30), true, day(19, 7, 0)},
+		{"overnight end", "22-7", day(19, 7, 0), false, time.Time{}},
+		{"daytime window", "13-15", day(18, 14, 10), true, day(18, 15, 0)},
+		{"outside daytime window", "13-15", day(18, 15, 0), false, time.Time{}},
+		{"spaces", " 22 - 7 ", day(18, 23, 0), true, day(19, 7, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CUSTOMER_QUIET_HOURS", tt.hours)
+
+			until, quiet := QuietUntil(tt.now)
+			if quiet != tt.wantQuiet {
+				t.Fatalf("quiet = %v, want %v", quiet, tt.wantQuiet)
+			}
+			if !until.Equal(tt.wantUntil) {
+				t.Errorf("until = %v, want %v", until, tt.wantUntil)
+			}
+		})
+	}
+}
@@ -30,6 +30,8 @@ func GetDB() (*gorm.DB, error) {
 	)
 
 	gormConfig := &gorm.Config{
+		// TranslateError turns duplicate key errors into gorm.ErrDuplicatedKey.
+		TranslateError: true,
 		Logger: logger.New(
 			log.New(os.Stdout, "\r\n", log.LstdFlags),
 			logger.Config{
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KitchenTicketTime returns how long a booth takes for one ticket on average, read
+// from KITCHEN_TICKET_MINUTES (default 8). Customers see ready times estimated from it.
+func KitchenTicketTime() time.Duration {
+	const fallback = 8
+
+	raw := strings.TrimSpace(os.Getenv("KITCHEN_TICKET_MINUTES"))
+	if raw == "" {
+		return fallback * time.Minute
+	}
+
+	minutes, err := strconv.Atoi(raw)
+	if err != nil || minutes <= 0 {
+		log.Printf("Warning: invalid KITCHEN_TICKET_MINUTES=%q, using %d", raw, fallback)
+		return fallback * time.Minute
+	}
+	return time.Duration(minutes) * time.Minute
+}
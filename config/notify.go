@@ -0,0 +1,63 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WhatsAppEnabled reports whether the WhatsApp client should be started. Set
+// WHATSAPP_ENABLED=false to run without a WhatsApp session.
+func WhatsAppEnabled() bool {
+	return strings.ToLower(strings.TrimSpace(os.Getenv("WHATSAPP_ENABLED"))) != "false"
+}
+
+// NotifyWebhookSecret signs the body of webhook notifications when set, read from
+// NOTIFY_WEBHOOK_SECRET.
+func NotifyWebhookSecret() string {
+	return os.Getenv("NOTIFY_WEBHOOK_SECRET")
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTP returns the mail server for email notifications, read from SMTP_HOST,
+// SMTP_PORT (default 587), SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM. The bool is
+// false when no server is configured.
+func SMTP() (SMTPConfig, bool) {
+	cfg := SMTPConfig{
+		Host:     strings.TrimSpace(os.Getenv("SMTP_HOST")),
+		Port:     strings.TrimSpace(os.Getenv("SMTP_PORT")),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     strings.TrimSpace(os.Getenv("SMTP_FROM")),
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	return cfg, cfg.Host != "" && cfg.From != ""
+}
+
+// MessageWorkers returns how many queued notifications are delivered in parallel,
+// read from MESSAGE_WORKERS (default 4).
+func MessageWorkers() int {
+	const fallback = 4
+
+	raw := strings.TrimSpace(os.Getenv("MESSAGE_WORKERS"))
+	if raw == "" {
+		return fallback
+	}
+
+	workers, err := strconv.Atoi(raw)
+	if err != nil || workers <= 0 {
+		log.Printf("Warning: invalid MESSAGE_WORKERS=%q, using %d", raw, fallback)
+		return fallback
+	}
+	return workers
+}
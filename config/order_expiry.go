@@ -0,0 +1,32 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var defaultOrderExpiry = map[string]time.Duration{
+	"cash": 30 * time.Minute,
+	"qris": 60 * time.Minute,
+}
+
+// OrderExpiry returns how long an unpaid order with the given payment method may stay
+// pending, read from ORDER_EXPIRY_<METHOD> (e.g. ORDER_EXPIRY_CASH=45m). Zero means
+// such orders never expire.
+func OrderExpiry(paymentMethod string) time.Duration {
+	fallback := defaultOrderExpiry[paymentMethod]
+
+	raw := strings.TrimSpace(os.Getenv("ORDER_EXPIRY_" + strings.ToUpper(paymentMethod)))
+	if raw == "" {
+		return fallback
+	}
+
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl < 0 {
+		log.Printf("Warning: invalid ORDER_EXPIRY_%s=%q, using %s", strings.ToUpper(paymentMethod), raw, fallback)
+		return fallback
+	}
+	return ttl
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Charge levels. A line charge is worked out for every order item, an order charge
+// once on the order total.
+const (
+	ChargeLevelLine  = "line"
+	ChargeLevelOrder = "order"
+)
+
+// Charge codes stored on order charges.
+const (
+	ChargeCodePackaging = "packaging"
+	ChargeCodeService   = "service"
+	ChargeCodeTax       = "tax"
+)
+
+// Charge is a fee added on top of the discounted subtotal. RateBP is a rate in basis
+// points (1000 = 10%) and Flat a fixed amount per unit (line) or per order (order).
+// A Compound charge is also taken over the charges before it, which is how PB1 is
+// levied on top of the service charge.
+type Charge struct {
+	Code       string
+	Label      string
+	Level      string
+	RateBP     int
+	Flat       int
+	PackedOnly bool
+	Compound   bool
+}
+
+// OrderCharges returns the fees applied to every order, in the order they are
+// computed: PACKAGING_FEE rupiah per unit of a takeaway or pickup order (default 2000),
+// SERVICE_CHARGE_PERCENT (default 5) and PB1_TAX_PERCENT (default 10). A zero value
+// turns a fee off.
+func OrderCharges() []Charge {
+	var charges []Charge
+
+	if fee := envRupiah("PACKAGING_FEE", 2000); fee > 0 {
+		charges = append(charges, Charge{
+			Code: ChargeCodePackaging, Label: "Biaya Kemasan", Level: ChargeLevelLine,
+			Flat: fee, PackedOnly: true,
+		})
+	}
+	if rate := envPercentBP("SERVICE_CHARGE_PERCENT", 500); rate > 0 {
+		charges = append(charges, Charge{
+			Code: ChargeCodeService, Label: "Biaya Layanan", Level: ChargeLevelOrder,
+			RateBP: rate,
+		})
+	}
+	if rate := envPercentBP("PB1_TAX_PERCENT", 1000); rate > 0 {
+		charges = append(charges, Charge{
+			Code: ChargeCodeTax, Label: "Pajak PB1", Level: ChargeLevelOrder,
+			RateBP: rate, Compound: true,
+		})
+	}
+	return charges
+}
+
+// envPercentBP reads a percentage such as "10" or "2.5" and returns it in basis points.
+func envPercentBP(key string, fallback int) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+
+	percent, err := strconv.ParseFloat(raw, 64)
+	if err != nil || percent < 0 || percent > 100 {
+		log.Printf("Warning: invalid %s=%q, using %d bp", key, raw, fallback)
+		return fallback
+	}
+	return int(math.Round(percent * 100))
+}
+
+func envRupiah(key string, fallback int) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+
+	amount, err := strconv.Atoi(raw)
+	if err != nil || amount < 0 {
+		log.Printf("Warning: invalid %s=%q, using %d", key, raw, fallback)
+		return fallback
+	}
+	return amount
+}
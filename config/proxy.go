@@ -0,0 +1,19 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// TrustedProxies returns the reverse proxies allowed to set X-Forwarded-For, read
+// from TRUSTED_PROXIES as comma-separated IPs or CIDRs. It is nil by default, so
+// the client IP is always the address of the connection.
+func TrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
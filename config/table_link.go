@@ -0,0 +1,21 @@
+package config
+
+import (
+	"log"
+	"os"
+)
+
+// TableLinkSecret returns the key that signs table QR links, read from
+// TABLE_LINK_SECRET and falling back to SECRET_KEY. Changing it invalidates every
+// printed table QR code. Without either, links would be signed with an empty key
+// anyone can reproduce, so startup is refused.
+func TableLinkSecret() []byte {
+	if secret := os.Getenv("TABLE_LINK_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	if secret := os.Getenv("SECRET_KEY"); secret != "" {
+		return []byte(secret)
+	}
+	log.Fatal("TABLE_LINK_SECRET or SECRET_KEY must be set to sign table QR links")
+	return nil
+}
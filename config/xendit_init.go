@@ -1,21 +1,42 @@
 package config
 
 import (
+	"errors"
+	"log"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 	xendit "github.com/xendit/xendit-go/v7"
 )
 
-var XenditClient *xendit.APIClient
+const (
+	PaymentProviderXendit = "xendit"
+	PaymentProviderMock   = "mock"
+)
 
-func InitXendit() {
+func NewXenditClient() (*xendit.APIClient, error) {
 	godotenv.Load()
 
 	apiKey := os.Getenv("XENDIT_SECRET_KEY")
 	if apiKey == "" {
-		panic("XENDIT_SECRET_KEY is missing in .env")
+		return nil, errors.New("XENDIT_SECRET_KEY is missing in .env")
+	}
+
+	return xendit.NewClient(apiKey), nil
+}
+
+// PaymentProvider returns the configured PAYMENT_PROVIDER, Xendit by default. The
+// mock lets anyone mark their own invoice paid, so it has to be asked for and is
+// refused outside APP_ENV=development.
+func PaymentProvider() string {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("PAYMENT_PROVIDER")))
+	if provider == "" {
+		return PaymentProviderXendit
 	}
 
-	XenditClient = xendit.NewClient(apiKey)
+	if provider == PaymentProviderMock && os.Getenv("APP_ENV") != "development" {
+		log.Fatal("PAYMENT_PROVIDER=mock is only allowed with APP_ENV=development")
+	}
+	return provider
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/utils"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -15,10 +16,133 @@ type MigrationRecord struct {
 	AppliedAt time.Time `gorm:"autoCreateTime"`
 }
 
+// migrationStep is a schema change applied once on top of the initial setup.
+type migrationStep struct {
+	Version string
+	Up      func(db *gorm.DB) error
+}
+
+var migrationSteps = []migrationStep{
+	{Version: "v1.1.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.OrderStatusHistory{})
+	}},
+	{Version: "v1.2.0", Up: func(db *gorm.DB) error {
+		if err := db.AutoMigrate(&model.BoothTicket{}, &model.OrderStatusHistory{}); err != nil {
+			return err
+		}
+		// Backfill one ticket per booth for orders created before tickets existed.
+		return db.Exec(`
+			INSERT INTO booth_tickets (order_id, booth_id, status, created_at, updated_at)
+			SELECT DISTINCT oi.order_id, oi.booth_id,
+				CASE o.order_status WHEN 'confirmed' THEN 'pending' ELSE o.order_status END,
+				o.created_at, o.updated_at
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE NOT EXISTS (
+				SELECT 1 FROM booth_tickets bt WHERE bt.order_id = oi.order_id AND bt.booth_id = oi.booth_id
+			)`).Error
+	}},
+	{Version: "v1.3.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.Menu{}, &model.OrderItem{})
+	}},
+	{Version: "v1.4.0", Up: func(db *gorm.DB) error {
+		if err := db.AutoMigrate(&model.PaymentOutbox{}); err != nil {
+			return err
+		}
+		// Queue an invoice for QRIS orders that were saved without one.
+		return db.Exec(`
+			INSERT INTO payment_outboxes (order_id, action, status, attempts, next_attempt_at, created_at, updated_at)
+			SELECT id, 'create_invoice', 'pending', 0, NOW(), NOW(), NOW()
+			FROM orders
+			WHERE payment_method = 'qris' AND payment_status = 'pending' AND order_status = 'pending'
+				AND (xendit_invoice_id IS NULL OR xendit_invoice_id = '')`).Error
+	}},
+	{Version: "v1.5.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.IdempotencyKey{})
+	}},
+	{Version: "v1.6.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.Order{}, &model.PaymentEvent{})
+	}},
+	{Version: "v1.7.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.PaymentEvent{})
+	}},
+	{Version: "v1.8.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.MenuOptionGroup{}, &model.MenuOption{}, &model.OrderItemOption{})
+	}},
+	{Version: "v1.9.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.Bundle{}, &model.BundleItem{}, &model.OrderItem{})
+	}},
+	{Version: "v1.10.0", Up: func(db *gorm.DB) error {
+		if err := db.AutoMigrate(&model.Order{}, &model.OrderItem{}, &model.Voucher{}, &model.VoucherRedemption{}); err != nil {
+			return err
+		}
+		return db.Exec("UPDATE orders SET subtotal = total_amount WHERE subtotal = 0").Error
+	}},
+	{Version: "v1.11.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.Order{}, &model.OrderCharge{})
+	}},
+	{Version: "v1.12.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.Table{}, &model.Order{})
+	}},
+	{Version: "v1.13.0", Up: func(db *gorm.DB) error {
+		if err := db.AutoMigrate(&model.Booth{}); err != nil {
+			return err
+		}
+
+		var booths []model.Booth
+		if err := db.Where("kitchen_token = '' OR kitchen_token IS NULL").Find(&booths).Error; err != nil {
+			return err
+		}
+		for _, booth := range booths {
+			if err := db.Model(&booth).Update("kitchen_token", utils.SecureToken(24)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{Version: "v1.14.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.QueueCounter{}, &model.Order{}, &model.BoothTicket{})
+	}},
+	{Version: "v1.15.0", Up: func(db *gorm.DB) error {
+		if err := db.AutoMigrate(&model.Order{}); err != nil {
+			return err
+		}
+
+		var orders []model.Order
+		if err := db.Select("id").Where("access_token = '' OR access_token IS NULL").Find(&orders).Error; err != nil {
+			return err
+		}
+		for _, order := range orders {
+			if err := db.Model(&order).Update("access_token", utils.SecureToken(16)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{Version: "v1.16.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.Booth{})
+	}},
+	{Version: "v1.17.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.OutboundMessage{}, &model.Order{})
+	}},
+	{Version: "v1.18.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.Order{})
+	}},
+	{Version: "v1.19.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.Order{})
+	}},
+	{Version: "v1.20.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.IdempotencyKey{})
+	}},
+	{Version: "v1.21.0", Up: func(db *gorm.DB) error {
+		return db.AutoMigrate(&model.Menu{})
+	}},
+}
+
 func Migrate(db *gorm.DB) error {
 	if db.Migrator().HasTable(&MigrationRecord{}) {
 		log.Println("Database already initialized. Skipping AutoMigrate.")
-		return nil
+		return applyPendingSteps(db)
 	}
 
 	log.Println("First time setup: Running AutoMigrate...")
@@ -43,6 +167,26 @@ func Migrate(db *gorm.DB) error {
 
 	db.Create(&MigrationRecord{Version: "v1.0.0"})
 	log.Println("AutoMigrate completed! Database ready.")
+	return applyPendingSteps(db)
+}
+
+func applyPendingSteps(db *gorm.DB) error {
+	for _, step := range migrationSteps {
+		var count int64
+		db.Model(&MigrationRecord{}).Where("version = ?", step.Version).Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		log.Printf("Applying migration %s...", step.Version)
+		if err := step.Up(db); err != nil {
+			return err
+		}
+
+		if err := db.Create(&MigrationRecord{Version: step.Version}).Error; err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -140,3 +140,15 @@ func (h *BoothHandler) Delete(c *gin.Context) {
 
 	c.HTML(http.StatusOK, "flash.html", gin.H{"Type": "success", "Message": "Booth dihapus!"})
 }
+
+// RotateKitchenToken issues a new kitchen display link; screens on the old link stop working.
+func (h *BoothHandler) RotateKitchenToken(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := h.uc.RotateKitchenToken(uint(id)); err != nil {
+		utils.SetFlash(c, "error", "Gagal membuat ulang link dapur: "+err.Error())
+	} else {
+		utils.SetFlash(c, "success", "Link layar dapur diperbarui. Buka ulang layar dapur dengan link baru.")
+	}
+	c.Redirect(http.StatusFound, "/api/admin/booths")
+}
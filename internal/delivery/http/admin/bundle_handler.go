@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/usecase"
+	"github.com/Rakhulsr/foodcourt/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type BundleHandler struct {
+	bundleUC usecase.BundleUseCase
+	menuUC   usecase.MenuUseCase
+}
+
+func NewBundleHandler(buc usecase.BundleUseCase, muc usecase.MenuUseCase) *BundleHandler {
+	return &BundleHandler{bundleUC: buc, menuUC: muc}
+}
+
+func (h *BundleHandler) List(c *gin.Context) {
+	bundles, err := h.bundleUC.ListAll()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	menus, err := h.menuUC.ListAll()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin_bundle_list.html", gin.H{
+		"Bundles":        bundles,
+		"Menus":          menus.Menus,
+		"ComponentSlots": []int{1, 2, 3, 4},
+		"Title":          "Paket Menu",
+		"ActiveMenu":     "bundle",
+
+		"FlashMessage": c.GetString("FlashMessage"),
+		"FlashType":    c.GetString("FlashType"),
+		"csrf_token":   c.GetString("csrf_token"),
+	})
+}
+
+func (h *BundleHandler) Create(c *gin.Context) {
+	var req dto.BundleCreateRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetFlash(c, "error", "Input paket tidak valid")
+		c.Redirect(http.StatusFound, "/api/admin/bundles")
+		return
+	}
+
+	if err := h.bundleUC.Create(req); err != nil {
+		utils.SetFlash(c, "error", "Gagal membuat paket: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/bundles")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Paket berhasil dibuat!")
+	c.Redirect(http.StatusFound, "/api/admin/bundles")
+}
+
+func (h *BundleHandler) ToggleActive(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := h.bundleUC.ToggleActive(uint(id)); err != nil {
+		utils.SetFlash(c, "error", "Gagal mengubah status paket: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/bundles")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Status paket diperbarui!")
+	c.Redirect(http.StatusFound, "/api/admin/bundles")
+}
+
+func (h *BundleHandler) Delete(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := h.bundleUC.Delete(uint(id)); err != nil {
+		utils.SetFlash(c, "error", "Gagal menghapus paket: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/bundles")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Paket berhasil dihapus!")
+	c.Redirect(http.StatusFound, "/api/admin/bundles")
+}
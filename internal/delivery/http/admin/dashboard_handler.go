@@ -4,15 +4,17 @@ import (
 	"net/http"
 
 	"github.com/Rakhulsr/foodcourt/internal/repository"
+	"github.com/Rakhulsr/foodcourt/internal/usecase"
 	"github.com/gin-gonic/gin"
 )
 
 type DashboardHandler struct {
-	orderRepo repository.OrderRepository
+	orderRepo    repository.OrderRepository
+	orderUsecase usecase.OrderUsecase
 }
 
-func NewDashboardHandler(or repository.OrderRepository) *DashboardHandler {
-	return &DashboardHandler{orderRepo: or}
+func NewDashboardHandler(or repository.OrderRepository, ou usecase.OrderUsecase) *DashboardHandler {
+	return &DashboardHandler{orderRepo: or, orderUsecase: ou}
 }
 
 func (h *DashboardHandler) Dashboard(c *gin.Context) {
@@ -23,6 +25,18 @@ func (h *DashboardHandler) Dashboard(c *gin.Context) {
 		return
 	}
 
+	boothIncome, err := h.orderRepo.GetBoothIncomeToday()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	charges, err := h.orderRepo.GetChargesCollectedToday()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
 	totalOrder, err := h.orderRepo.CountOrdersToday()
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
@@ -35,14 +49,24 @@ func (h *DashboardHandler) Dashboard(c *gin.Context) {
 		return
 	}
 
+	reconciliation, err := h.orderUsecase.ReconciliationReport()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
 	c.HTML(http.StatusOK, "admin_dashboard.html", gin.H{
-		"Title":      "Dashboard",
-		"ActiveMenu": "dashboard",
-		"AdminName":  "Admin",
-		"Income":     income,
-		"Outcome":    0,
-		"TotalOrder": totalOrder,
-		"Orders":     orders,
+		"Title":       "Dashboard",
+		"ActiveMenu":  "dashboard",
+		"AdminName":   "Admin",
+		"Income":      income,
+		"BoothIncome": boothIncome,
+		"Charges":     charges,
+		"Outcome":     0,
+		"TotalOrder":  totalOrder,
+		"Orders":      orders,
+
+		"Reconciliation": reconciliation,
 
 		"csrf_token": c.GetString("csrf_token"),
 	})
@@ -203,3 +203,152 @@ func (h *MenuHandler) Delete(c *gin.Context) {
 		"Message": "Menu berhasil dihapus!",
 	})
 }
+
+func (h *MenuHandler) ShowStock(c *gin.Context) {
+	resp, err := h.menuUC.ListAll()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin_menu_stock.html", gin.H{
+		"Menus":      resp.Menus,
+		"Title":      "Stok Menu",
+		"ActiveMenu": "menu",
+
+		"FlashMessage": c.GetString("FlashMessage"),
+		"FlashType":    c.GetString("FlashType"),
+		"csrf_token":   c.GetString("csrf_token"),
+	})
+}
+
+func (h *MenuHandler) Restock(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 32)
+
+	var req dto.MenuStockRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetFlash(c, "error", "Input stok tidak valid")
+		c.Redirect(http.StatusFound, "/api/admin/menus/stock")
+		return
+	}
+
+	if err := h.menuUC.Restock(uint(id), req); err != nil {
+		utils.SetFlash(c, "error", "Gagal update stok: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/menus/stock")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Stok berhasil diperbarui!")
+	c.Redirect(http.StatusFound, "/api/admin/menus/stock")
+}
+
+func (h *MenuHandler) ShowOptions(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	menu, err := h.menuUC.GetByID(uint(id))
+	if err != nil {
+		utils.SetFlash(c, "error", "Menu tidak ditemukan")
+		c.Redirect(http.StatusFound, "/api/admin/menus")
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin_menu_options.html", gin.H{
+		"Menu":       menu,
+		"Title":      "Opsi Menu",
+		"ActiveMenu": "menu",
+
+		"FlashMessage": c.GetString("FlashMessage"),
+		"FlashType":    c.GetString("FlashType"),
+		"csrf_token":   c.GetString("csrf_token"),
+	})
+}
+
+func (h *MenuHandler) AddOptionGroup(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	redirectURL := "/api/admin/menus/" + c.Param("id") + "/options"
+
+	var req dto.MenuOptionGroupRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetFlash(c, "error", "Input grup opsi tidak valid")
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+	req.Required = c.PostForm("required") == "on"
+	req.MultiSelect = c.PostForm("multi_select") == "on"
+
+	if err := h.menuUC.AddOptionGroup(uint(id), req); err != nil {
+		utils.SetFlash(c, "error", "Gagal menambah grup opsi: "+err.Error())
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	utils.SetFlash(c, "success", "Grup opsi berhasil ditambahkan!")
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+func (h *MenuHandler) DeleteOptionGroup(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	groupID, _ := strconv.ParseUint(c.Param("groupId"), 10, 32)
+	redirectURL := "/api/admin/menus/" + c.Param("id") + "/options"
+
+	if err := h.menuUC.DeleteOptionGroup(uint(id), uint(groupID)); err != nil {
+		utils.SetFlash(c, "error", "Gagal menghapus grup opsi: "+err.Error())
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	utils.SetFlash(c, "success", "Grup opsi dihapus")
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+func (h *MenuHandler) AddOption(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	groupID, _ := strconv.ParseUint(c.Param("groupId"), 10, 32)
+	redirectURL := "/api/admin/menus/" + c.Param("id") + "/options"
+
+	var req dto.MenuOptionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetFlash(c, "error", "Input opsi tidak valid")
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	if err := h.menuUC.AddOption(uint(id), uint(groupID), req); err != nil {
+		utils.SetFlash(c, "error", "Gagal menambah opsi: "+err.Error())
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	utils.SetFlash(c, "success", "Opsi berhasil ditambahkan!")
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+func (h *MenuHandler) ToggleOption(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	optionID, _ := strconv.ParseUint(c.Param("optionId"), 10, 32)
+	redirectURL := "/api/admin/menus/" + c.Param("id") + "/options"
+
+	if err := h.menuUC.ToggleOption(uint(id), uint(optionID)); err != nil {
+		utils.SetFlash(c, "error", "Gagal mengubah opsi: "+err.Error())
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+func (h *MenuHandler) DeleteOption(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	optionID, _ := strconv.ParseUint(c.Param("optionId"), 10, 32)
+	redirectURL := "/api/admin/menus/" + c.Param("id") + "/options"
+
+	if err := h.menuUC.DeleteOption(uint(id), uint(optionID)); err != nil {
+		utils.SetFlash(c, "error", "Gagal menghapus opsi: "+err.Error())
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	utils.SetFlash(c, "success", "Opsi dihapus")
+	c.Redirect(http.StatusFound, redirectURL)
+}
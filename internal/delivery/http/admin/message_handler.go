@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/usecase"
+	"github.com/Rakhulsr/foodcourt/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type MessageHandler struct {
+	queue usecase.MessageQueue
+}
+
+func NewMessageHandler(queue usecase.MessageQueue) *MessageHandler {
+	return &MessageHandler{queue: queue}
+}
+
+func (h *MessageHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page <= 0 {
+		page = 1
+	}
+	status := c.Query("status")
+	const limit = 20
+
+	messages, total, err := h.queue.List(page, limit, status)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	counts, err := h.queue.CountByStatus()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin_message_list.html", gin.H{
+		"Title":      "Pesan Keluar",
+		"ActiveMenu": "message",
+		"Messages":   messages,
+		"Counts":     counts,
+		"Status":     status,
+		"Statuses": []string{
+			model.MessageStatusQueued, model.MessageStatusSending, model.MessageStatusSent,
+			model.MessageStatusDead, model.MessageStatusCancelled,
+		},
+		"Total":   total,
+		"Page":    page,
+		"HasNext": int64(page*limit) < total,
+
+		"FlashMessage": c.GetString("FlashMessage"),
+		"FlashType":    c.GetString("FlashType"),
+		"csrf_token":   c.GetString("csrf_token"),
+	})
+}
+
+// Status renders the delivery badges for ?ids=1,2,3; the partial keeps polling
+// itself until every message is final.
+func (h *MessageHandler) Status(c *gin.Context) {
+	var ids []uint
+	for _, raw := range strings.Split(c.Query("ids"), ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 32)
+		if err == nil && id > 0 {
+			ids = append(ids, uint(id))
+		}
+	}
+
+	messages, err := h.queue.FindByIDs(ids)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.HTML(http.StatusOK, "message_status.html", gin.H{"Messages": messages})
+}
+
+func (h *MessageHandler) Requeue(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := h.queue.Requeue(uint(id)); err != nil {
+		utils.SetFlash(c, "error", "Gagal mengirim ulang: "+err.Error())
+		c.Redirect(http.StatusFound, messageListURL(c))
+		return
+	}
+
+	utils.SetFlash(c, "success", "Pesan masuk antrean lagi.")
+	c.Redirect(http.StatusFound, messageListURL(c))
+}
+
+func (h *MessageHandler) Cancel(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := h.queue.Cancel(uint(id)); err != nil {
+		utils.SetFlash(c, "error", "Gagal membatalkan: "+err.Error())
+		c.Redirect(http.StatusFound, messageListURL(c))
+		return
+	}
+
+	utils.SetFlash(c, "success", "Pesan dibatalkan.")
+	c.Redirect(http.StatusFound, messageListURL(c))
+}
+
+// messageListURL keeps the status filter the admin was looking at.
+func messageListURL(c *gin.Context) string {
+	if status := c.PostForm("status"); status != "" {
+		return "/api/admin/messages?status=" + url.QueryEscape(status)
+	}
+	return "/api/admin/messages"
+}
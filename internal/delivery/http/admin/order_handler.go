@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
 	"github.com/Rakhulsr/foodcourt/internal/usecase"
 	"github.com/gin-gonic/gin"
 )
@@ -50,7 +51,7 @@ func (h *OrderHandler) AdminUpdateStatus(c *gin.Context) {
 		return
 	}
 
-	err := h.orderUsecase.UpdateOrderStatus(code, req.Status)
+	err := h.orderUsecase.UpdateOrderStatus(code, req.Status, adminActor(c), req.Reason)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -67,16 +68,92 @@ func (h *OrderHandler) AdminUpdateStatus(c *gin.Context) {
 	c.HTML(http.StatusOK, "admin_order_row.html", data)
 }
 
+func (h *OrderHandler) AdminUpdateTicketStatus(c *gin.Context) {
+	code := c.Param("code")
+	ticketID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	var req dto.UpdateStatusRequest
+
+	if err := c.ShouldBind(&req); err != nil {
+		c.String(http.StatusBadRequest, "Invalid Data")
+		return
+	}
+
+	err := h.orderUsecase.UpdateTicketStatus(code, uint(ticketID), req.Status, adminActor(c), req.Reason)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	updatedOrder, _ := h.orderUsecase.GetOrderByCode(code)
+
+	c.HTML(http.StatusOK, "admin_order_row.html", gin.H{
+		"Order":     updatedOrder,
+		"CsrfToken": c.GetString("csrf_token"),
+	})
+}
+
+func (h *OrderHandler) RecheckPayment(c *gin.Context) {
+	code := c.Param("code")
+
+	event, err := h.orderUsecase.RecheckPayment(code, adminActor(c))
+	if err != nil {
+
+		c.Header("HX-Trigger", `{"showMessage": {"type": "error", "message": "Gagal cek pembayaran: `+err.Error()+`"}}`)
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	message := "Invoice masih menunggu pembayaran"
+	if event != nil {
+		message = "Status invoice " + event.Status + ": " + event.Outcome
+	}
+	c.Header("HX-Trigger", `{"showMessage": {"type": "info", "message": "`+message+`"}}`)
+
+	updatedOrder, _ := h.orderUsecase.GetOrderByCode(code)
+
+	c.HTML(http.StatusOK, "admin_order_row.html", gin.H{
+		"Order":     updatedOrder,
+		"CsrfToken": c.GetString("csrf_token"),
+	})
+}
+
+// adminActor builds the status actor from the admin_id claim set by JWTAuth.
+func adminActor(c *gin.Context) model.StatusActor {
+	actor := model.StatusActor{Type: model.ActorAdmin}
+	if v, ok := c.Get("admin_id"); ok {
+		if id, ok := v.(float64); ok {
+			adminID := uint(id)
+			actor.AdminID = &adminID
+		}
+	}
+	return actor
+}
+
 func (h *OrderHandler) SendNotification(c *gin.Context) {
 	code := c.Param("code")
 
-	err := h.orderUsecase.SendOrderNotificationToSeller(code)
+	messages, err := h.orderUsecase.SendOrderNotificationToSeller(code)
+	if err != nil {
+
+		c.Header("HX-Trigger", `{"showMessage": {"type": "error", "message": "Gagal mengantrekan pesan: `+err.Error()+`"}}`)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.HTML(http.StatusOK, "message_status.html", gin.H{"Messages": messages})
+}
+
+func (h *OrderHandler) SendTicketNotification(c *gin.Context) {
+	code := c.Param("code")
+	ticketID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	messages, err := h.orderUsecase.SendTicketNotification(code, uint(ticketID))
 	if err != nil {
 
-		c.Header("HX-Trigger", `{"showMessage": {"type": "error", "message": "Gagal kirim WA: `+err.Error()+`"}}`)
+		c.Header("HX-Trigger", `{"showMessage": {"type": "error", "message": "Gagal mengantrekan pesan: `+err.Error()+`"}}`)
 		c.Status(http.StatusInternalServerError)
 		return
 	}
 
-	c.HTML(http.StatusOK, "button_notify_sent.html", nil)
+	c.HTML(http.StatusOK, "message_status.html", gin.H{"Messages": messages})
 }
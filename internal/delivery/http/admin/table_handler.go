@@ -0,0 +1,168 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/usecase"
+	"github.com/Rakhulsr/foodcourt/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type TableHandler struct {
+	tableUC usecase.TableUseCase
+}
+
+func NewTableHandler(tuc usecase.TableUseCase) *TableHandler {
+	return &TableHandler{tableUC: tuc}
+}
+
+func (h *TableHandler) List(c *gin.Context) {
+	tables, err := h.tableUC.ListAll()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin_table_list.html", gin.H{
+		"Tables":     tables,
+		"Title":      "Daftar Meja",
+		"ActiveMenu": "table",
+
+		"FlashMessage": c.GetString("FlashMessage"),
+		"FlashType":    c.GetString("FlashType"),
+		"csrf_token":   c.GetString("csrf_token"),
+	})
+}
+
+// Board shows every active table with the dine-in orders still open there.
+func (h *TableHandler) Board(c *gin.Context) {
+	zones, err := h.tableUC.Board()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin_table_board.html", gin.H{
+		"Zones":      zones,
+		"Title":      "Papan Meja",
+		"ActiveMenu": "table",
+
+		"FlashMessage": c.GetString("FlashMessage"),
+		"FlashType":    c.GetString("FlashType"),
+		"csrf_token":   c.GetString("csrf_token"),
+	})
+}
+
+// tableQR is a table on the printable QR sheet.
+type tableQR struct {
+	Table model.Table
+	Link  string
+}
+
+// QRSheet is a printable page with the QR code of every active table.
+func (h *TableHandler) QRSheet(c *gin.Context) {
+	tables, err := h.tableUC.ListActive()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	codes := make([]tableQR, 0, len(tables))
+	for _, table := range tables {
+		codes = append(codes, tableQR{Table: table, Link: h.tableUC.Link(table)})
+	}
+
+	c.HTML(http.StatusOK, "admin_table_qr_sheet.html", gin.H{
+		"Title":  "QR Meja",
+		"Tables": codes,
+	})
+}
+
+// QRCode serves a table's QR code as PNG, or as SVG with ?format=svg.
+func (h *TableHandler) QRCode(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	format := c.DefaultQuery("format", "png")
+
+	image, err := h.tableUC.QRCode(uint(id), format)
+	if err != nil {
+		c.String(http.StatusNotFound, err.Error())
+		return
+	}
+
+	contentType := "image/png"
+	if format == "svg" {
+		contentType = "image/svg+xml"
+	}
+	if c.Query("download") != "" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=meja-%d.%s", id, format))
+	}
+	c.Data(http.StatusOK, contentType, image)
+}
+
+func (h *TableHandler) Create(c *gin.Context) {
+	var req dto.TableRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetFlash(c, "error", "Input meja tidak valid")
+		c.Redirect(http.StatusFound, "/api/admin/tables")
+		return
+	}
+
+	if err := h.tableUC.Create(req); err != nil {
+		utils.SetFlash(c, "error", "Gagal menambah meja: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/tables")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Meja berhasil ditambahkan!")
+	c.Redirect(http.StatusFound, "/api/admin/tables")
+}
+
+func (h *TableHandler) Update(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var req dto.TableRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetFlash(c, "error", "Input meja tidak valid")
+		c.Redirect(http.StatusFound, "/api/admin/tables")
+		return
+	}
+
+	if err := h.tableUC.Update(uint(id), req); err != nil {
+		utils.SetFlash(c, "error", "Gagal memperbarui meja: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/tables")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Meja berhasil diperbarui!")
+	c.Redirect(http.StatusFound, "/api/admin/tables")
+}
+
+func (h *TableHandler) ToggleActive(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := h.tableUC.ToggleActive(uint(id)); err != nil {
+		utils.SetFlash(c, "error", "Gagal mengubah status meja: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/tables")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Status meja diperbarui!")
+	c.Redirect(http.StatusFound, "/api/admin/tables")
+}
+
+func (h *TableHandler) Delete(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := h.tableUC.Delete(uint(id)); err != nil {
+		utils.SetFlash(c, "error", "Gagal menghapus meja: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/tables")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Meja dihapus!")
+	c.Redirect(http.StatusFound, "/api/admin/tables")
+}
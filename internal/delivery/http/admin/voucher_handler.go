@@ -0,0 +1,195 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/usecase"
+	"github.com/Rakhulsr/foodcourt/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type VoucherHandler struct {
+	voucherUC usecase.VoucherUseCase
+	boothUC   usecase.BoothUseCase
+}
+
+func NewVoucherHandler(vuc usecase.VoucherUseCase, buc usecase.BoothUseCase) *VoucherHandler {
+	return &VoucherHandler{voucherUC: vuc, boothUC: buc}
+}
+
+func (h *VoucherHandler) List(c *gin.Context) {
+	vouchers, err := h.voucherUC.ListAll()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin_voucher_list.html", gin.H{
+		"Vouchers":   vouchers,
+		"Title":      "Voucher",
+		"ActiveMenu": "voucher",
+
+		"FlashMessage": c.GetString("FlashMessage"),
+		"FlashType":    c.GetString("FlashType"),
+		"csrf_token":   c.GetString("csrf_token"),
+	})
+}
+
+func (h *VoucherHandler) ShowCreateForm(c *gin.Context) {
+	h.renderForm(c, http.StatusOK, "create", nil, "")
+}
+
+func (h *VoucherHandler) ShowEditForm(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	voucher, err := h.voucherUC.GetByID(uint(id))
+	if err != nil {
+		utils.SetFlash(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/vouchers")
+		return
+	}
+
+	h.renderForm(c, http.StatusOK, "edit", voucher, "")
+}
+
+func (h *VoucherHandler) Create(c *gin.Context) {
+	req, err := bindVoucherRequest(c)
+	if err == nil {
+		err = h.voucherUC.Create(req)
+	}
+	if err != nil {
+		h.renderForm(c, http.StatusBadRequest, "create", formVoucher(req), err.Error())
+		return
+	}
+
+	utils.SetFlash(c, "success", "Voucher berhasil dibuat!")
+	c.Redirect(http.StatusFound, "/api/admin/vouchers")
+}
+
+func (h *VoucherHandler) Update(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	req, err := bindVoucherRequest(c)
+	if err == nil {
+		err = h.voucherUC.Update(uint(id), req)
+	}
+	if err != nil {
+		voucher := formVoucher(req)
+		voucher.ID = uint(id)
+		h.renderForm(c, http.StatusBadRequest, "edit", voucher, err.Error())
+		return
+	}
+
+	utils.SetFlash(c, "success", "Voucher berhasil diperbarui!")
+	c.Redirect(http.StatusFound, "/api/admin/vouchers")
+}
+
+func (h *VoucherHandler) ToggleActive(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := h.voucherUC.ToggleActive(uint(id)); err != nil {
+		utils.SetFlash(c, "error", "Gagal mengubah status voucher: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/vouchers")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Status voucher diperbarui!")
+	c.Redirect(http.StatusFound, "/api/admin/vouchers")
+}
+
+func (h *VoucherHandler) Delete(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	if err := h.voucherUC.Delete(uint(id)); err != nil {
+		utils.SetFlash(c, "error", "Gagal menghapus voucher: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/vouchers")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Voucher berhasil dihapus!")
+	c.Redirect(http.StatusFound, "/api/admin/vouchers")
+}
+
+func (h *VoucherHandler) Report(c *gin.Context) {
+	report, err := h.voucherUC.Report(50)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin_voucher_report.html", gin.H{
+		"Report":     report,
+		"Title":      "Laporan Voucher",
+		"ActiveMenu": "voucher",
+		"csrf_token": c.GetString("csrf_token"),
+	})
+}
+
+func (h *VoucherHandler) renderForm(c *gin.Context, status int, formType string, voucher *model.Voucher, errMsg string) {
+	booths, err := h.boothUC.ListAll()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	title := "Tambah Voucher"
+	if formType == "edit" {
+		title = "Edit Voucher"
+	}
+
+	data := gin.H{
+		"Type":       formType,
+		"Title":      title,
+		"ActiveMenu": "voucher",
+		"Booths":     booths.Booths,
+		"BoothID":    uint(0),
+		"Error":      errMsg,
+		"csrf_token": c.GetString("csrf_token"),
+	}
+	if voucher != nil {
+		data["Data"] = voucher
+		if voucher.BoothID != nil {
+			data["BoothID"] = *voucher.BoothID
+		}
+		if voucher.StartsAt != nil {
+			data["StartsAt"] = voucher.StartsAt.Format("2006-01-02T15:04")
+		}
+		if voucher.EndsAt != nil {
+			data["EndsAt"] = voucher.EndsAt.Format("2006-01-02T15:04")
+		}
+	}
+	c.HTML(status, "admin_voucher_form.html", data)
+}
+
+func bindVoucherRequest(c *gin.Context) (dto.VoucherRequest, error) {
+	var req dto.VoucherRequest
+	err := c.ShouldBind(&req)
+	req.Stackable = c.PostForm("stackable") == "on"
+	req.IsActive = c.PostForm("is_active") == "on"
+	return req, err
+}
+
+// formVoucher refills the form from a rejected request so the admin does not lose input.
+func formVoucher(req dto.VoucherRequest) *model.Voucher {
+	voucher := &model.Voucher{
+		Code:             req.Code,
+		Description:      req.Description,
+		Type:             req.Type,
+		Value:            req.Value,
+		MaxDiscount:      req.MaxDiscount,
+		MinSpend:         req.MinSpend,
+		Category:         req.Category,
+		UsageLimit:       req.UsageLimit,
+		PerCustomerLimit: req.PerCustomerLimit,
+		Stackable:        req.Stackable,
+		IsActive:         req.IsActive,
+	}
+	if req.BoothID != 0 {
+		boothID := req.BoothID
+		voucher.BoothID = &boothID
+	}
+	return voucher
+}
@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/usecase"
+	"github.com/Rakhulsr/foodcourt/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// WhatsAppHandler manages the linked WhatsApp session. wa is nil when WhatsApp
+// is turned off.
+type WhatsAppHandler struct {
+	wa *usecase.WhatsAppUsecase
+}
+
+func NewWhatsAppHandler(wa *usecase.WhatsAppUsecase) *WhatsAppHandler {
+	return &WhatsAppHandler{wa: wa}
+}
+
+func (h *WhatsAppHandler) Show(c *gin.Context) {
+	data := gin.H{
+		"Title":      "WhatsApp",
+		"ActiveMenu": "whatsapp",
+		"Enabled":    h.wa != nil,
+
+		"FlashMessage": c.GetString("FlashMessage"),
+		"FlashType":    c.GetString("FlashType"),
+		"csrf_token":   c.GetString("csrf_token"),
+	}
+	if h.wa != nil {
+		data["Status"] = h.wa.Status()
+	}
+
+	c.HTML(http.StatusOK, "admin_whatsapp.html", data)
+}
+
+// Status renders the session card, which polls itself while the state is changing.
+func (h *WhatsAppHandler) Status(c *gin.Context) {
+	if h.wa == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.HTML(http.StatusOK, "whatsapp_status.html", gin.H{
+		"Status":     h.wa.Status(),
+		"csrf_token": c.GetString("csrf_token"),
+	})
+}
+
+// QRCode serves the current pairing code as a PNG.
+func (h *WhatsAppHandler) QRCode(c *gin.Context) {
+	if h.wa == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	code := h.wa.Status().QRCode
+	if code == "" {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	image, err := utils.QRPNG(code, 6)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	// The page asks for a new URL per code, so a short private cache avoids
+	// reloading the image on every status poll.
+	c.Header("Cache-Control", "private, max-age=30")
+	c.Data(http.StatusOK, "image/png", image)
+}
+
+func (h *WhatsAppHandler) Connect(c *gin.Context) {
+	if h.wa == nil {
+		utils.SetFlash(c, "error", "WhatsApp tidak aktif (WHATSAPP_ENABLED=false)")
+		c.Redirect(http.StatusFound, "/api/admin/whatsapp")
+		return
+	}
+
+	if err := h.wa.Connect(); err != nil {
+		utils.SetFlash(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/whatsapp")
+		return
+	}
+
+	utils.SetFlash(c, "success", "Menghubungkan WhatsApp...")
+	c.Redirect(http.StatusFound, "/api/admin/whatsapp")
+}
+
+func (h *WhatsAppHandler) Logout(c *gin.Context) {
+	if h.wa == nil {
+		utils.SetFlash(c, "error", "WhatsApp tidak aktif (WHATSAPP_ENABLED=false)")
+		c.Redirect(http.StatusFound, "/api/admin/whatsapp")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 20*time.Second)
+	defer cancel()
+
+	if err := h.wa.Logout(ctx); err != nil {
+		utils.SetFlash(c, "error", "Gagal logout WhatsApp: "+err.Error())
+		c.Redirect(http.StatusFound, "/api/admin/whatsapp")
+		return
+	}
+
+	utils.SetFlash(c, "success", "WhatsApp dilepas. Tautkan ulang dengan scan QR baru.")
+	c.Redirect(http.StatusFound, "/api/admin/whatsapp")
+}
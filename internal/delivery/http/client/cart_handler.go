@@ -9,25 +9,167 @@ import (
 	"strings"
 
 	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
 	"github.com/Rakhulsr/foodcourt/internal/usecase"
 	"github.com/Rakhulsr/foodcourt/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type CartHandler struct {
-	menuUC usecase.MenuUseCase
+	menuUC   usecase.MenuUseCase
+	bundleUC usecase.BundleUseCase
+	orderUC  usecase.OrderUsecase
+	tableUC  usecase.TableUseCase
 }
 
-func NewCartHandler(muc usecase.MenuUseCase) *CartHandler {
-	return &CartHandler{menuUC: muc}
+func NewCartHandler(muc usecase.MenuUseCase, buc usecase.BundleUseCase, ouc usecase.OrderUsecase, tuc usecase.TableUseCase) *CartHandler {
+	return &CartHandler{menuUC: muc, bundleUC: buc, orderUC: ouc, tableUC: tuc}
+}
+
+// cartLine is a cart cookie entry priced against the current menu or bundle.
+type cartLine struct {
+	Name      string
+	Options   string
+	ImagePath string
+	BoothName string
+	UnitPrice int
+}
+
+func (h *CartHandler) resolveLine(item dto.CartItemCookie) (*cartLine, error) {
+	if item.BundleID != 0 {
+		bundle, err := h.bundleUC.GetByID(item.BundleID)
+		if err != nil {
+			return nil, err
+		}
+		if !bundle.IsOrderable {
+			return nil, fmt.Errorf("paket '%s' sedang tidak tersedia", bundle.Name)
+		}
+		return &cartLine{
+			Name:      bundle.Name,
+			Options:   bundle.Summary(),
+			BoothName: "Paket",
+			UnitPrice: bundle.Price,
+		}, nil
+	}
+
+	selection, err := h.menuUC.ResolveSelection(item.MenuID, item.OptionIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &cartLine{
+		Name:      selection.Menu.Name,
+		Options:   selection.Summary(),
+		ImagePath: selection.Menu.ImagePath,
+		BoothName: selection.Menu.Booth.Name,
+		UnitPrice: selection.UnitPrice,
+	}, nil
 }
 
 const (
 	CartCookieName     = "user_cart"
 	CustomerNameCookie = "temp_customer_name"
 	TableNumberCookie  = "temp_table_number"
+	VoucherCodeCookie  = "temp_voucher_code"
+	OrderTypeCookie    = "temp_order_type"
+
+	CustomerPhoneCookie = "temp_customer_phone"
 )
 
+// tableLockMaxAge keeps a scanned table for a whole visit, across several orders.
+// The lock lives in TableNumberCookie, so a table can no longer be typed in.
+const tableLockMaxAge = 4 * 3600
+
+// ScanTable is where a table QR code leads. It locks the table in the customer's
+// cookie and opens the menu.
+func (h *CartHandler) ScanTable(c *gin.Context) {
+	table, lock, err := h.tableUC.Scan(c.Param("code"), c.Query("sig"))
+	if err != nil {
+		utils.SetFlash(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/")
+		return
+	}
+
+	c.SetCookie(TableNumberCookie, lock, tableLockMaxAge, "/", "", false, true)
+	c.SetCookie(OrderTypeCookie, model.OrderTypeDineIn, 3600, "/", "", false, false)
+	utils.SetFlash(c, "success", fmt.Sprintf("Anda memesan dari meja %s", table.Code))
+	c.Redirect(http.StatusFound, "/")
+}
+
+// lockedTable returns the table scanned by the customer, or nil when none was.
+func (h *CartHandler) lockedTable(c *gin.Context) *model.Table {
+	lock, _ := c.Cookie(TableNumberCookie)
+	if lock == "" {
+		return nil
+	}
+	table, err := h.tableUC.LockedTable(lock)
+	if err != nil {
+		return nil
+	}
+	return table
+}
+
+// orderTypeChoice is an order type button on the cart page.
+type orderTypeChoice struct {
+	Value string
+	Label string
+	Icon  string
+}
+
+var orderTypeChoices = []orderTypeChoice{
+	{model.OrderTypeDineIn, model.OrderTypeLabel(model.OrderTypeDineIn), "utensils"},
+	{model.OrderTypeTakeaway, model.OrderTypeLabel(model.OrderTypeTakeaway), "shopping-bag"},
+	{model.OrderTypePickup, model.OrderTypeLabel(model.OrderTypePickup), "store"},
+}
+
+// orderTypeOf returns a known order type, falling back to dine-in.
+func orderTypeOf(raw string) string {
+	if model.IsValidOrderType(raw) {
+		return raw
+	}
+	return model.OrderTypeDineIn
+}
+
+// quoteCart prices the cart with its fees for the cart page. A voucher that no longer
+// applies is left out of the preview; ProceedCheckout reports it. It returns nil when
+// the cart cannot be priced at all.
+func (h *CartHandler) quoteCart(items []dto.CartItemCookie, customerPhone, voucherCode, orderType string) *dto.OrderQuote {
+	if len(items) == 0 {
+		return nil
+	}
+
+	req := dto.CreateOrderRequest{
+		Items:         cartOrderItems(items),
+		VoucherCode:   voucherCode,
+		OrderType:     orderType,
+		CustomerPhone: customerPhone,
+	}
+	quote, err := h.orderUC.QuoteOrder(req)
+	if err != nil && voucherCode != "" {
+		req.VoucherCode = ""
+		quote, err = h.orderUC.QuoteOrder(req)
+	}
+	if err != nil {
+		return nil
+	}
+	return quote
+}
+
+// cartOrderItems turns the cart cookie into the items of an order request.
+func cartOrderItems(items []dto.CartItemCookie) []dto.CreateOrderItemRequest {
+	var reqItems []dto.CreateOrderItemRequest
+	for _, item := range items {
+		reqItems = append(reqItems, dto.CreateOrderItemRequest{
+			MenuID:    item.MenuID,
+			BundleID:  item.BundleID,
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+			OptionIDs: item.OptionIDs,
+		})
+	}
+	return reqItems
+}
+
 func (h *CartHandler) AddToCart(c *gin.Context) {
 	var req dto.AddToCartRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -37,21 +179,34 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 	if req.Quantity <= 0 {
 		req.Quantity = 1
 	}
+	req.OptionIDs = append(req.OptionIDs, singleChoiceOptions(c)...)
+
+	newItem := dto.CartItemCookie{MenuID: req.MenuID, Quantity: req.Quantity, OptionIDs: req.OptionIDs}
+	if req.BundleID != 0 {
+		newItem = dto.CartItemCookie{BundleID: req.BundleID, Quantity: req.Quantity}
+	}
+
+	if _, err := h.resolveLine(newItem); err != nil {
+		c.HTML(http.StatusOK, "flash.html", gin.H{
+			"Type":    "error",
+			"Message": err.Error(),
+		})
+		return
+	}
 
 	cartItems := h.getCartFromCookie(c)
+	key := newItem.Key()
 	found := false
 
 	for i, item := range cartItems {
-		if item.MenuID == req.MenuID {
+		if item.Key() == key {
 			cartItems[i].Quantity += req.Quantity
 			found = true
 			break
 		}
 	}
 	if !found {
-		cartItems = append(cartItems, dto.CartItemCookie{
-			MenuID: req.MenuID, Quantity: req.Quantity,
-		})
+		cartItems = append(cartItems, newItem)
 	}
 
 	h.saveCartToCookie(c, cartItems)
@@ -62,10 +217,10 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 	for _, item := range cartItems {
 		totalQty += item.Quantity
 
-		menu, err := h.menuUC.GetByID(item.MenuID)
+		line, err := h.resolveLine(item)
 		if err == nil {
 
-			itemNames = append(itemNames, fmt.Sprintf("%s x%d", menu.Name, item.Quantity))
+			itemNames = append(itemNames, fmt.Sprintf("%s x%d", line.Name, item.Quantity))
 		}
 	}
 
@@ -84,41 +239,77 @@ func (h *CartHandler) AddToCart(c *gin.Context) {
 	})
 }
 
+// singleChoiceOptions collects the single-choice picks, which are posted as one
+// radio group per option group (option_ids_<groupID>) so they don't clear each other.
+func singleChoiceOptions(c *gin.Context) []uint {
+	var ids []uint
+	for field, values := range c.Request.PostForm {
+		if !strings.HasPrefix(field, "option_ids_") {
+			continue
+		}
+		for _, value := range values {
+			if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+				ids = append(ids, uint(id))
+			}
+		}
+	}
+	return ids
+}
+
 func (h *CartHandler) ShowCart(c *gin.Context) {
 	cookieItems := h.getCartFromCookie(c)
 
 	customerName, _ := c.Cookie(CustomerNameCookie)
-	tableNumber, _ := c.Cookie(TableNumberCookie)
+	customerPhone, _ := c.Cookie(CustomerPhoneCookie)
+	voucherCode, _ := c.Cookie(VoucherCodeCookie)
+	orderType, _ := c.Cookie(OrderTypeCookie)
+	orderType = orderTypeOf(orderType)
+
+	tableNumber := ""
+	if table := h.lockedTable(c); table != nil {
+		tableNumber = table.Code
+	}
 
 	var finalItems []map[string]interface{}
 	totalAmount := 0
 	totalQty := 0
 
 	for _, item := range cookieItems {
-		menu, err := h.menuUC.GetByID(item.MenuID)
+		line, err := h.resolveLine(item)
 		if err != nil {
 			continue
 		}
 
-		subTotal := menu.Price * item.Quantity
+		subTotal := line.UnitPrice * item.Quantity
 		totalAmount += subTotal
 		totalQty += item.Quantity
 
 		finalItems = append(finalItems, map[string]interface{}{
-			"MenuID":    menu.ID,
-			"Name":      menu.Name,
-			"Price":     menu.Price,
-			"ImagePath": menu.ImagePath,
-			"BoothName": menu.Booth.Name,
+			"MenuID":    item.MenuID,
+			"BundleID":  item.BundleID,
+			"Key":       item.Key(),
+			"Name":      line.Name,
+			"Options":   line.Options,
+			"Price":     line.UnitPrice,
+			"ImagePath": line.ImagePath,
+			"BoothName": line.BoothName,
 			"Quantity":  item.Quantity,
 			"SubTotal":  subTotal,
 			"Notes":     item.Notes,
 		})
 	}
 
+	quote := h.quoteCart(cookieItems, customerPhone, voucherCode, orderType)
+	if quote != nil {
+		totalAmount = quote.Total
+	}
+
 	c.HTML(http.StatusOK, "client_cart.html", gin.H{
 		"Title":        "Keranjang Pesanan",
 		"CartItems":    finalItems,
+		"Quote":        quote,
+		"OrderType":    orderType,
+		"OrderTypes":   orderTypeChoices,
 		"TotalAmount":  totalAmount,
 		"TotalQty":     totalQty,
 		"ActiveTab":    "cart",
@@ -127,6 +318,9 @@ func (h *CartHandler) ShowCart(c *gin.Context) {
 		"FlashType":    c.GetString("FlashType"),
 		"CustomerName": customerName,
 		"TableNumber":  tableNumber,
+		"VoucherCode":  voucherCode,
+
+		"CustomerPhone": customerPhone,
 	})
 }
 
@@ -148,10 +342,20 @@ func (h *CartHandler) saveCartToCookie(c *gin.Context, items []dto.CartItemCooki
 }
 
 func (h *CartHandler) UpdateCartItem(c *gin.Context) {
-	menuID, _ := strconv.Atoi(c.PostForm("menu_id"))
+	key := c.PostForm("key")
+	if key == "" {
+		key = c.PostForm("menu_id")
+	}
 	action := c.PostForm("action")
 	note := c.PostForm("note")
 
+	orderType, _ := c.Cookie(OrderTypeCookie)
+	if action == "set_order_type" {
+		orderType = c.PostForm("order_type")
+		c.SetCookie(OrderTypeCookie, orderTypeOf(orderType), 3600, "/", "", false, false)
+	}
+	orderType = orderTypeOf(orderType)
+
 	cartItems := h.getCartFromCookie(c)
 	var newItems []dto.CartItemCookie
 
@@ -159,7 +363,7 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 	var itemSubTotal int
 
 	for _, item := range cartItems {
-		if item.MenuID == uint(menuID) {
+		if item.Key() == key {
 
 			if action == "update_note" {
 				item.Notes = note
@@ -191,12 +395,14 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 	totalQty := 0
 
 	for _, item := range newItems {
-		menu, _ := h.menuUC.GetByID(item.MenuID)
-		subTotal := menu.Price * item.Quantity
+		subTotal := 0
+		if line, err := h.resolveLine(item); err == nil {
+			subTotal = line.UnitPrice * item.Quantity
+		}
 		totalAmount += subTotal
 		totalQty += item.Quantity
 
-		if item.MenuID == uint(menuID) {
+		if item.Key() == key {
 			itemSubTotal = subTotal
 
 			if action != "delete" {
@@ -205,10 +411,20 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 		}
 	}
 
+	customerPhone, _ := c.Cookie(CustomerPhoneCookie)
+	voucherCode, _ := c.Cookie(VoucherCodeCookie)
+	quote := h.quoteCart(newItems, customerPhone, voucherCode, orderType)
+	grandTotal := totalAmount
+	if quote != nil {
+		grandTotal = quote.Total
+	}
+
 	c.HTML(http.StatusOK, "cart_update.html", gin.H{
 		"Item":         currentItem,
 		"ItemSubTotal": itemSubTotal,
 		"TotalAmount":  totalAmount,
+		"Quote":        quote,
+		"GrandTotal":   grandTotal,
 		"TotalQty":     totalQty,
 		"Action":       action,
 		"CsrfToken":    c.PostForm("csrf_token"),
@@ -217,7 +433,6 @@ func (h *CartHandler) UpdateCartItem(c *gin.Context) {
 
 func (h *CartHandler) ProceedCheckout(c *gin.Context) {
 	customerName := c.PostForm("customer_name")
-	tableNumber := c.PostForm("table_number")
 
 	if customerName == "" {
 		utils.SetFlash(c, "error", "Nama pemesan wajib diisi!")
@@ -226,7 +441,45 @@ func (h *CartHandler) ProceedCheckout(c *gin.Context) {
 	}
 
 	c.SetCookie("temp_customer_name", customerName, 3600, "/", "", false, false)
-	c.SetCookie(TableNumberCookie, tableNumber, 3600, "/", "", false, false)
+
+	// The WhatsApp number is optional and only used for order updates.
+	customerPhone := strings.TrimSpace(c.PostForm("customer_phone"))
+	if customerPhone != "" {
+		customerPhone = utils.NormalizePhone(customerPhone)
+		if !utils.IsValidPhone(customerPhone) {
+			utils.SetFlash(c, "error", "Nomor WhatsApp tidak valid, contoh: 08123456789")
+			c.Redirect(http.StatusFound, "/cart")
+			return
+		}
+	}
+	c.SetCookie(CustomerPhoneCookie, customerPhone, 3600, "/", "", false, true)
+
+	orderType := orderTypeOf(c.PostForm("order_type"))
+	c.SetCookie(OrderTypeCookie, orderType, 3600, "/", "", false, false)
+
+	if orderType == model.OrderTypeDineIn && h.lockedTable(c) == nil {
+		utils.SetFlash(c, "error", "Silakan scan QR di meja Anda untuk makan di sini, atau pilih bawa pulang")
+		c.Redirect(http.StatusFound, "/cart")
+		return
+	}
+
+	voucherCode := strings.TrimSpace(c.PostForm("voucher_code"))
+	if voucherCode != "" {
+		_, err := h.orderUC.QuoteOrder(dto.CreateOrderRequest{
+			CustomerName:  customerName,
+			Items:         cartOrderItems(h.getCartFromCookie(c)),
+			VoucherCode:   voucherCode,
+			OrderType:     orderType,
+			CustomerPhone: customerPhone,
+		})
+		if err != nil {
+			c.SetCookie(VoucherCodeCookie, "", -1, "/", "", false, false)
+			utils.SetFlash(c, "error", "Voucher tidak dapat dipakai: "+err.Error())
+			c.Redirect(http.StatusFound, "/cart")
+			return
+		}
+	}
+	c.SetCookie(VoucherCodeCookie, voucherCode, 3600, "/", "", false, false)
 
 	c.Redirect(http.StatusFound, "/checkout")
 }
@@ -235,7 +488,21 @@ func (h *CartHandler) ShowCheckoutPage(c *gin.Context) {
 	cookieItems := h.getCartFromCookie(c)
 
 	customerName, _ := c.Cookie(CustomerNameCookie)
-	tableNumber, _ := c.Cookie(TableNumberCookie)
+	customerPhone, _ := c.Cookie(CustomerPhoneCookie)
+	voucherCode, _ := c.Cookie(VoucherCodeCookie)
+	orderType, _ := c.Cookie(OrderTypeCookie)
+	orderType = orderTypeOf(orderType)
+
+	tableNumber := ""
+	if orderType == model.OrderTypeDineIn {
+		table := h.lockedTable(c)
+		if table == nil {
+			utils.SetFlash(c, "error", "Silakan scan QR di meja Anda untuk makan di sini, atau pilih bawa pulang")
+			c.Redirect(http.StatusFound, "/cart")
+			return
+		}
+		tableNumber = table.Code
+	}
 
 	if len(cookieItems) == 0 {
 		c.Redirect(http.StatusFound, "")
@@ -249,36 +516,56 @@ func (h *CartHandler) ShowCheckoutPage(c *gin.Context) {
 	}
 
 	var finalItems []map[string]interface{}
-	totalAmount := 0
 	totalQty := 0
 
 	for _, item := range cookieItems {
-		menu, err := h.menuUC.GetByID(item.MenuID)
+		line, err := h.resolveLine(item)
 		if err != nil {
 			continue
 		}
 
-		subTotal := menu.Price * item.Quantity
-		totalAmount += subTotal
+		subTotal := line.UnitPrice * item.Quantity
 		totalQty += item.Quantity
 
 		finalItems = append(finalItems, map[string]interface{}{
 
-			"Name":     menu.Name,
-			"Price":    menu.Price,
+			"Name":     line.Name,
+			"Options":  line.Options,
+			"Price":    line.UnitPrice,
 			"Quantity": item.Quantity,
 			"SubTotal": subTotal,
 			"Notes":    item.Notes,
 		})
 	}
 
+	quote, err := h.orderUC.QuoteOrder(dto.CreateOrderRequest{
+		CustomerName:  customerName,
+		Items:         cartOrderItems(cookieItems),
+		VoucherCode:   voucherCode,
+		OrderType:     orderType,
+		CustomerPhone: customerPhone,
+	})
+	if err != nil {
+		c.SetCookie(VoucherCodeCookie, "", -1, "/", "", false, false)
+		utils.SetFlash(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/cart")
+		return
+	}
+
 	c.HTML(http.StatusOK, "client_checkout.html", gin.H{
 		"Title":        "Konfirmasi Pesanan",
 		"CartItems":    finalItems,
-		"TotalAmount":  totalAmount,
+		"Quote":        quote,
+		"VoucherCode":  voucherCode,
+		"OrderType":    orderType,
+		"OrderLabel":   model.OrderTypeLabel(orderType),
+		"TotalAmount":  quote.Total,
 		"TotalQty":     totalQty,
 		"CustomerName": customerName,
 		"TableNumber":  tableNumber,
 		"csrf_token":   c.GetString("csrf_token"),
+
+		"IdempotencyKey": uuid.New().String(),
+		"CustomerPhone":  customerPhone,
 	})
 }
@@ -14,12 +14,13 @@ import (
 )
 
 type MenuHandler struct {
-	menuUc  usecase.MenuUseCase
-	boothUC usecase.BoothUseCase
+	menuUc   usecase.MenuUseCase
+	boothUC  usecase.BoothUseCase
+	bundleUC usecase.BundleUseCase
 }
 
-func NewMenuHandler(uc usecase.MenuUseCase, bu usecase.BoothUseCase) *MenuHandler {
-	return &MenuHandler{menuUc: uc, boothUC: bu}
+func NewMenuHandler(uc usecase.MenuUseCase, bu usecase.BoothUseCase, bndu usecase.BundleUseCase) *MenuHandler {
+	return &MenuHandler{menuUc: uc, boothUC: bu, bundleUC: bndu}
 }
 
 func (h *MenuHandler) ListActive(c *gin.Context) {
@@ -128,6 +129,15 @@ func (h *MenuHandler) ClientHome(c *gin.Context) {
 		return
 	}
 
+	var bundles []dto.BundleResponse
+	if keyword == "" {
+		bundles, err = h.bundleUC.ListOrderable()
+		if err != nil {
+			c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	cookie, _ := c.Cookie("user_cart")
 	totalQty := 0
 	summaryText := ""
@@ -141,6 +151,13 @@ func (h *MenuHandler) ClientHome(c *gin.Context) {
 		for _, item := range items {
 			totalQty += item.Quantity
 
+			for _, b := range bundles {
+				if item.BundleID != 0 && b.ID == item.BundleID {
+					itemNames = append(itemNames, fmt.Sprintf("%s x%d", b.Name, item.Quantity))
+					break
+				}
+			}
+
 			for _, m := range menusResp.Menus {
 				if m.ID == item.MenuID {
 
@@ -163,6 +180,7 @@ func (h *MenuHandler) ClientHome(c *gin.Context) {
 		"Title":        "Beranda",
 		"Booths":       boothsResp.Booths,
 		"Menus":        menusResp.Menus,
+		"Bundles":      bundles,
 		"Keyword":      keyword,
 		"FlashMessage": c.GetString("FlashMessage"),
 		"FlashType":    c.GetString("FlashType"),
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/Rakhulsr/foodcourt/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// MockPaymentHandler serves the hosted payment page of the offline mock gateway.
+type MockPaymentHandler struct {
+	gateway *usecase.MockPaymentGateway
+}
+
+func NewMockPaymentHandler(gateway *usecase.MockPaymentGateway) *MockPaymentHandler {
+	return &MockPaymentHandler{gateway: gateway}
+}
+
+func (h *MockPaymentHandler) Show(c *gin.Context) {
+	inv, err := h.gateway.Detail(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Invoice tidak ditemukan")
+		return
+	}
+
+	c.HTML(http.StatusOK, "mock_payment.html", gin.H{
+		"Title":      "Simulasi Pembayaran",
+		"Invoice":    inv,
+		"csrf_token": c.GetString("csrf_token"),
+	})
+}
+
+func (h *MockPaymentHandler) Pay(c *gin.Context) {
+	inv, err := h.gateway.Detail(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Invoice tidak ditemukan")
+		return
+	}
+
+	if _, err := h.gateway.Pay(inv.ID); err != nil {
+		c.String(http.StatusConflict, "Gagal membayar invoice: "+err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusFound, inv.SuccessURL)
+}
+
+func (h *MockPaymentHandler) Expire(c *gin.Context) {
+	inv, err := h.gateway.Detail(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Invoice tidak ditemukan")
+		return
+	}
+
+	if _, err := h.gateway.Expire(inv.ID); err != nil {
+		c.String(http.StatusConflict, "Gagal membatalkan invoice: "+err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusFound, inv.FailureURL)
+}
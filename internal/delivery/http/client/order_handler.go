@@ -2,6 +2,7 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 
@@ -13,10 +14,11 @@ import (
 
 type OrderHandler struct {
 	orderUsecase usecase.OrderUsecase
+	tableUC      usecase.TableUseCase
 }
 
-func NewOrderHandler(ou usecase.OrderUsecase) *OrderHandler {
-	return &OrderHandler{orderUsecase: ou}
+func NewOrderHandler(ou usecase.OrderUsecase, tuc usecase.TableUseCase) *OrderHandler {
+	return &OrderHandler{orderUsecase: ou, tableUC: tuc}
 }
 
 func (h *OrderHandler) Create(c *gin.Context) {
@@ -28,8 +30,20 @@ func (h *OrderHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		req.IdempotencyKey = key
+	}
+
 	cookie, _ := c.Cookie("user_cart")
 	if cookie == "" {
+		// A retry whose first attempt already cleared the cart still gets its order back.
+		if req.IdempotencyKey != "" {
+			if res, err := h.orderUsecase.ReplayOrder(req.IdempotencyKey); err == nil && res != nil {
+				h.redirectAfterOrder(c, res)
+				return
+			}
+		}
+
 		utils.SetFlash(c, "error", "Keranjang kosong")
 		c.Redirect(http.StatusFound, "")
 		return
@@ -45,12 +59,14 @@ func (h *OrderHandler) Create(c *gin.Context) {
 		return
 	}
 
-	for _, item := range cartItems {
-		req.Items = append(req.Items, dto.CreateOrderItemRequest{
-			MenuID:   item.MenuID,
-			Quantity: item.Quantity,
-			Notes:    item.Notes,
-		})
+	req.Items = cartOrderItems(cartItems)
+
+	// Dine-in orders go to the table the customer scanned, never a typed one.
+	req.TableNumber = ""
+	if lock, _ := c.Cookie(TableNumberCookie); lock != "" {
+		if table, err := h.tableUC.LockedTable(lock); err == nil {
+			req.TableNumber = table.Code
+		}
 	}
 
 	res, err := h.orderUsecase.CreateOrder(req)
@@ -61,56 +77,115 @@ func (h *OrderHandler) Create(c *gin.Context) {
 		return
 	}
 
+	h.redirectAfterOrder(c, res)
+}
+
+func (h *OrderHandler) redirectAfterOrder(c *gin.Context, res *dto.CreateOrderResponse) {
 	c.SetCookie("user_cart", "", -1, "/", "", false, false)
 	c.SetCookie("temp_customer_name", "", -1, "/", "", false, false)
-	c.SetCookie("temp_table_number", "", -1, "/", "", false, false)
+	c.SetCookie(CustomerPhoneCookie, "", -1, "/", "", false, true)
+	c.SetCookie(VoucherCodeCookie, "", -1, "/", "", false, false)
 
 	if res.PaymentURL != "" {
 		c.Redirect(http.StatusFound, res.PaymentURL)
 	} else {
 
-		c.Redirect(http.StatusFound, "/order/success/"+res.OrderCode)
+		c.Redirect(http.StatusFound, "/order/success/"+res.OrderCode+"?token="+res.AccessToken)
 	}
 }
 
 func (h *OrderHandler) ShowSuccessPage(c *gin.Context) {
-	orderCode := c.Param("code")
-
-	order, err := h.orderUsecase.GetOrderByCode(orderCode)
+	order, err := h.orderUsecase.GetCustomerOrder(c.Param("code"), c.Query("token"))
 	if err != nil {
 		c.String(http.StatusNotFound, "Order tidak ditemukan")
 		return
 	}
 
+	expiresAt, expires := usecase.OrderExpiresAt(order)
+
 	c.HTML(http.StatusOK, "order_success.html", gin.H{
 		"Title":        "Pesanan Berhasil",
 		"Order":        order,
+		"Expires":      expires,
+		"ExpiresAt":    expiresAt,
 		"FlashMessage": c.GetString("FlashMessage"),
 		"FlashType":    c.GetString("FlashType"),
 	})
 }
 
+// ShowTracking is the bookmarkable tracking page. It needs nothing but the order
+// code and access token in the link, so it keeps working after the cart cookies are gone.
+func (h *OrderHandler) ShowTracking(c *gin.Context) {
+	tracking, err := h.orderUsecase.TrackOrder(c.Param("code"), c.Query("token"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Order tidak ditemukan")
+		return
+	}
+
+	c.HTML(http.StatusOK, "order_tracking.html", gin.H{
+		"Title":    "Lacak Pesanan",
+		"Tracking": tracking,
+	})
+}
+
+// TrackingStatus renders the progress block only; the tracking page reloads it on
+// every event.
+func (h *OrderHandler) TrackingStatus(c *gin.Context) {
+	tracking, err := h.orderUsecase.TrackOrder(c.Param("code"), c.Query("token"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Order tidak ditemukan")
+		return
+	}
+
+	c.HTML(http.StatusOK, "order_tracking_status.html", gin.H{"Tracking": tracking})
+}
+
+// GetOrderDetail returns the tracking breakdown of an order as JSON.
 func (h *OrderHandler) GetOrderDetail(c *gin.Context) {
-	code := c.Param("code")
-	order, err := h.orderUsecase.GetOrderByCode(code)
+	tracking, err := h.orderUsecase.TrackOrder(c.Param("code"), c.Query("token"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
 	}
-	c.JSON(http.StatusOK, order)
+	c.JSON(http.StatusOK, tracking)
+}
+
+// TrackingEvents streams a "status" event whenever the order changes.
+func (h *OrderHandler) TrackingEvents(c *gin.Context) {
+	code := c.Param("code")
+	if _, err := h.orderUsecase.GetCustomerOrder(code, c.Query("token")); err != nil {
+		c.String(http.StatusNotFound, "Order tidak ditemukan")
+		return
+	}
+
+	events, unsubscribe := h.orderUsecase.Subscribe()
+	defer unsubscribe()
+
+	utils.StreamEvents(c, events, func(event usecase.OrderEvent) {
+		if event.OrderCode == code {
+			c.SSEvent("status", "")
+		}
+	})
 }
 
 func (h *OrderHandler) HandleXenditWebhook(c *gin.Context) {
 
-	var req dto.XenditCallbackRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	body, err := c.GetRawData()
+	if err != nil {
 
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payload"})
 		return
 	}
 
-	err := h.orderUsecase.ProcessXenditCallback(req)
-	if err != nil {
+	err = h.orderUsecase.ProcessXenditCallback(c.GetHeader("x-callback-token"), body)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCallbackToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid callback token"})
+		return
+	case errors.Is(err, usecase.ErrInvalidCallbackPayload):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payload"})
+		return
+	case err != nil:
 
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process"})
 		return
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Rakhulsr/foodcourt/internal/usecase"
+	"github.com/Rakhulsr/foodcourt/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DisplayHandler serves the public "now serving" screen shown on the food court TV.
+// It is read-only and shows no customer details.
+type DisplayHandler struct {
+	displayUC usecase.DisplayUseCase
+	orderUC   usecase.OrderUsecase
+}
+
+func NewDisplayHandler(duc usecase.DisplayUseCase, ouc usecase.OrderUsecase) *DisplayHandler {
+	return &DisplayHandler{displayUC: duc, orderUC: ouc}
+}
+
+func (h *DisplayHandler) Show(c *gin.Context) {
+	board, err := h.displayUC.Board()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	c.HTML(http.StatusOK, "now_serving.html", gin.H{
+		"Title": "Status Pesanan",
+		"Board": board,
+	})
+}
+
+// Board renders the two columns only; the screen reloads them on every event.
+func (h *DisplayHandler) Board(c *gin.Context) {
+	board, err := h.displayUC.Board()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.HTML(http.StatusOK, "now_serving_board.html", gin.H{"Board": board})
+}
+
+// Events streams an empty "order" event for every order change. Nothing about the
+// order is sent since the stream is public.
+func (h *DisplayHandler) Events(c *gin.Context) {
+	events, unsubscribe := h.orderUC.Subscribe()
+	defer unsubscribe()
+
+	utils.StreamEvents(c, events, func(usecase.OrderEvent) {
+		c.SSEvent("order", "")
+	})
+}
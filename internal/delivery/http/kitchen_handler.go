@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/usecase"
+	"github.com/Rakhulsr/foodcourt/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// KitchenHandler serves a booth's kitchen display. Routes sit behind
+// middleware.BoothTokenAuth, which puts the booth in the context.
+type KitchenHandler struct {
+	kitchenUC usecase.KitchenUseCase
+	orderUC   usecase.OrderUsecase
+}
+
+func NewKitchenHandler(kuc usecase.KitchenUseCase, ouc usecase.OrderUsecase) *KitchenHandler {
+	return &KitchenHandler{kitchenUC: kuc, orderUC: ouc}
+}
+
+func (h *KitchenHandler) Show(c *gin.Context) {
+	booth := c.MustGet("booth").(*model.Booth)
+
+	queue, err := h.kitchenUC.Queue(*booth)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{"error": err.Error()})
+		return
+	}
+
+	c.HTML(http.StatusOK, "kitchen_display.html", gin.H{
+		"Title":      "Dapur " + booth.Name,
+		"Queue":      queue,
+		"Token":      c.Param("token"),
+		"csrf_token": c.GetString("csrf_token"),
+	})
+}
+
+// Tickets renders the queue only; the display reloads it on every event.
+func (h *KitchenHandler) Tickets(c *gin.Context) {
+	h.renderQueue(c, "")
+}
+
+func (h *KitchenHandler) Advance(c *gin.Context) {
+	booth := c.MustGet("booth").(*model.Booth)
+	ticketID, _ := strconv.ParseUint(c.Param("ticketId"), 10, 32)
+
+	errorMessage := ""
+	if err := h.kitchenUC.Advance(*booth, uint(ticketID), c.Param("action")); err != nil {
+		errorMessage = err.Error()
+	}
+	h.renderQueue(c, errorMessage)
+}
+
+// Events streams a "ticket" event whenever an order of this booth changes.
+func (h *KitchenHandler) Events(c *gin.Context) {
+	booth := c.MustGet("booth").(*model.Booth)
+
+	events, unsubscribe := h.orderUC.Subscribe()
+	defer unsubscribe()
+
+	utils.StreamEvents(c, events, func(event usecase.OrderEvent) {
+		if event.HasBooth(booth.ID) {
+			c.SSEvent("ticket", event.OrderCode)
+		}
+	})
+}
+
+func (h *KitchenHandler) renderQueue(c *gin.Context, errorMessage string) {
+	booth := c.MustGet("booth").(*model.Booth)
+
+	queue, err := h.kitchenUC.Queue(*booth)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.HTML(http.StatusOK, "kitchen_queue.html", gin.H{
+		"Queue":      queue,
+		"Token":      c.Param("token"),
+		"Error":      errorMessage,
+		"csrf_token": c.GetString("csrf_token"),
+	})
+}
@@ -4,14 +4,20 @@ import "time"
 
 type BoothCreateRequest struct {
 	Name     string `json:"name" form:"name" binding:"required"`
-	WhatsApp string `json:"whatsapp" form:"whatsapp" binding:"required"`
+	WhatsApp string `json:"whatsapp" form:"whatsapp"`
 	IsActive bool   `json:"is_active"`
+
+	NotifyChannel string `json:"notify_channel" form:"notify_channel"`
+	NotifyTarget  string `json:"notify_target" form:"notify_target"`
 }
 
 type BoothUpdateRequest struct {
 	Name     string `json:"name" form:"name"`
 	WhatsApp string `json:"whatsapp" form:"whatsapp"`
 	IsActive bool   `json:"is_active"`
+
+	NotifyChannel string `json:"notify_channel" form:"notify_channel"`
+	NotifyTarget  string `json:"notify_target" form:"notify_target"`
 }
 
 type BoothResponse struct {
@@ -21,6 +27,10 @@ type BoothResponse struct {
 	IsActive  bool      `json:"is_active"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	KitchenToken  string `json:"-"`
+	NotifyChannel string `json:"notify_channel"`
+	NotifyTarget  string `json:"notify_target"`
 }
 
 type BoothListResponse struct {
@@ -0,0 +1,52 @@
+package dto
+
+import "strconv"
+
+type BundleCreateRequest struct {
+	Name        string `json:"name" form:"name" binding:"required,max=100"`
+	Description string `json:"description" form:"description"`
+	Price       int    `json:"price" form:"price" binding:"required,gt=0"`
+	MenuIDs     []uint `json:"menu_ids" form:"menu_ids"`
+	Quantities  []int  `json:"quantities" form:"quantities"`
+}
+
+type BundleResponse struct {
+	ID          uint                 `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Price       int                  `json:"price"`
+	NormalPrice int                  `json:"normal_price"`
+	IsActive    bool                 `json:"is_active"`
+	IsOrderable bool                 `json:"is_orderable"`
+	Items       []BundleItemResponse `json:"items"`
+}
+
+type BundleItemResponse struct {
+	MenuID    uint   `json:"menu_id"`
+	MenuName  string `json:"menu_name"`
+	BoothName string `json:"booth_name"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Savings is how much cheaper the bundle is than its components bought separately.
+func (b BundleResponse) Savings() int {
+	if b.NormalPrice <= b.Price {
+		return 0
+	}
+	return b.NormalPrice - b.Price
+}
+
+// Summary lists the components, e.g. "Nasi Goreng, 2x Es Teh".
+func (b BundleResponse) Summary() string {
+	summary := ""
+	for i, item := range b.Items {
+		if i > 0 {
+			summary += ", "
+		}
+		if item.Quantity > 1 {
+			summary += strconv.Itoa(item.Quantity) + "x "
+		}
+		summary += item.MenuName
+	}
+	return summary
+}
@@ -1,16 +1,49 @@
 package dto
 
-import "github.com/Rakhulsr/foodcourt/internal/model"
+import (
+	"sort"
+	"strconv"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+)
 
 type CartItemCookie struct {
-	MenuID   uint   `json:"menu_id"`
-	Quantity int    `json:"quantity"`
-	Notes    string `json:"notes"`
+	MenuID    uint   `json:"menu_id"`
+	Quantity  int    `json:"quantity"`
+	Notes     string `json:"notes"`
+	OptionIDs []uint `json:"option_ids,omitempty"`
+	BundleID  uint   `json:"bundle_id,omitempty"`
+}
+
+// Key identifies a cart line: the same menu with different options is a separate line.
+// A menu without options is keyed by its ID alone, a bundle by "b" and its ID.
+func (i CartItemCookie) Key() string {
+	if i.BundleID != 0 {
+		return BundleLineKey(i.BundleID)
+	}
+	return CartLineKey(i.MenuID, i.OptionIDs)
+}
+
+func BundleLineKey(bundleID uint) string {
+	return "b" + strconv.FormatUint(uint64(bundleID), 10)
+}
+
+func CartLineKey(menuID uint, optionIDs []uint) string {
+	ids := append([]uint(nil), optionIDs...)
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+
+	key := strconv.FormatUint(uint64(menuID), 10)
+	for _, id := range ids {
+		key += "-" + strconv.FormatUint(uint64(id), 10)
+	}
+	return key
 }
 
 type AddToCartRequest struct {
-	MenuID   uint `json:"menu_id" form:"menu_id" binding:"required"`
-	Quantity int  `json:"quantity" form:"quantity"`
+	MenuID    uint   `json:"menu_id" form:"menu_id" binding:"required_without=BundleID"`
+	BundleID  uint   `json:"bundle_id" form:"bundle_id"`
+	Quantity  int    `json:"quantity" form:"quantity"`
+	OptionIDs []uint `json:"option_ids" form:"option_ids"`
 }
 
 type CartView struct {
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+)
+
+// KitchenTicket is one booth's part of an order as shown on its kitchen display.
+type KitchenTicket struct {
+	TicketID     uint
+	QueueLabel   string
+	Status       string
+	OrderCode    string
+	CustomerName string
+	TableNumber  string
+	OrderType    string
+	OrderedAt    time.Time
+	Items        []model.OrderItem
+}
+
+// KitchenQueue groups a booth's open tickets by what the kitchen has to do next.
+type KitchenQueue struct {
+	Booth     model.Booth
+	Pending   []KitchenTicket
+	Preparing []KitchenTicket
+	Ready     []KitchenTicket
+}
+
+// ServingOrder is an order as shown on the public queue screen. It carries no
+// customer details.
+type ServingOrder struct {
+	Label     string
+	UpdatedAt time.Time
+}
+
+// ServingBoard is what the public "now serving" screen shows.
+type ServingBoard struct {
+	Preparing []ServingOrder
+	Ready     []ServingOrder
+}
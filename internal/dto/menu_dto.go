@@ -1,5 +1,7 @@
 package dto
 
+import "strings"
+
 type MenuCreateRequest struct {
 	BoothID     uint   `json:"booth_id" form:"booth_id" binding:"required"`
 	Name        string `json:"name" form:"name" binding:"required"`
@@ -22,6 +24,7 @@ type MenuResponse struct {
 	Name        string `json:"name"`
 	Price       int    `json:"price"`
 	IsAvailable bool   `json:"is_available"`
+	Stock       *int   `json:"stock"`
 	Category    string `json:"category"`
 	Description string `json:"description"`
 	ImagePath   string `json:"image_path"`
@@ -29,9 +32,70 @@ type MenuResponse struct {
 		ID   uint   `json:"id"`
 		Name string `json:"name"`
 	} `json:"booth"`
+	OptionGroups []MenuOptionGroupResponse `json:"option_groups,omitempty"`
+}
+
+type MenuOptionGroupResponse struct {
+	ID          uint                 `json:"id"`
+	Name        string               `json:"name"`
+	Required    bool                 `json:"required"`
+	MultiSelect bool                 `json:"multi_select"`
+	MinSelect   int                  `json:"min_select"`
+	MaxSelect   int                  `json:"max_select"`
+	Options     []MenuOptionResponse `json:"options"`
+}
+
+type MenuOptionResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	PriceDelta  int    `json:"price_delta"`
+	IsAvailable bool   `json:"is_available"`
 }
 
 type MenuListResponse struct {
 	Total int            `json:"total"`
 	Menus []MenuResponse `json:"menus"`
 }
+
+type MenuStockRequest struct {
+	Action   string `json:"action" form:"action" binding:"required,oneof=add set untrack"`
+	Quantity int    `json:"quantity" form:"quantity" binding:"gte=0"`
+}
+
+type MenuOptionGroupRequest struct {
+	Name        string `json:"name" form:"name" binding:"required,max=50"`
+	Required    bool   `json:"required"`
+	MultiSelect bool   `json:"multi_select"`
+	MinSelect   int    `json:"min_select" form:"min_select" binding:"gte=0"`
+	MaxSelect   int    `json:"max_select" form:"max_select" binding:"gte=0"`
+	SortOrder   int    `json:"sort_order" form:"sort_order"`
+}
+
+type MenuOptionRequest struct {
+	Name       string `json:"name" form:"name" binding:"required,max=50"`
+	PriceDelta int    `json:"price_delta" form:"price_delta"`
+	SortOrder  int    `json:"sort_order" form:"sort_order"`
+}
+
+// MenuSelection is a menu together with the options a customer picked for it.
+type MenuSelection struct {
+	Menu      *MenuResponse
+	Options   []SelectedOption
+	UnitPrice int
+}
+
+type SelectedOption struct {
+	ID         uint   `json:"id"`
+	GroupName  string `json:"group_name"`
+	Name       string `json:"name"`
+	PriceDelta int    `json:"price_delta"`
+}
+
+// Summary lists the selected option names, e.g. "Besar, Level 3".
+func (s *MenuSelection) Summary() string {
+	names := make([]string, 0, len(s.Options))
+	for _, opt := range s.Options {
+		names = append(names, opt.Name)
+	}
+	return strings.Join(names, ", ")
+}
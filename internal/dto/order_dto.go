@@ -1,24 +1,59 @@
 package dto
 
-import "github.com/Rakhulsr/foodcourt/internal/model"
+import (
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+)
 
 type CreateOrderRequest struct {
 	CustomerName  string                   `json:"customer_name" form:"customer_name" binding:"required"`
 	TableNumber   string                   `json:"table_number" form:"table_number"`
 	PaymentMethod string                   `json:"payment_method" form:"payment_method" binding:"required"`
 	Items         []CreateOrderItemRequest `json:"items"`
+	VoucherCode   string                   `json:"voucher_code" form:"voucher_code"`
+	OrderType     string                   `json:"order_type" form:"order_type"`
+
+	// CustomerPhone opts the customer in to WhatsApp updates about the order.
+	CustomerPhone string `json:"customer_phone" form:"customer_phone"`
+
+	IdempotencyKey string `json:"-" form:"idempotency_key"`
 }
 
 type CreateOrderResponse struct {
-	OrderCode  string `json:"order_code"`
-	PaymentURL string `json:"payment_url" binding:"omitempty"`
-	Message    string `json:"message"`
+	OrderCode   string `json:"order_code"`
+	AccessToken string `json:"access_token"`
+	PaymentURL  string `json:"payment_url" binding:"omitempty"`
+	Message     string `json:"message"`
 }
 
 type CreateOrderItemRequest struct {
-	MenuID   uint   `json:"menu_id" binding:"required"`
-	Quantity int    `json:"quantity" binding:"required,gt=0"`
-	Notes    string `json:"notes"`
+	MenuID    uint   `json:"menu_id" binding:"required_without=BundleID"`
+	BundleID  uint   `json:"bundle_id"`
+	Quantity  int    `json:"quantity" binding:"required,gt=0"`
+	Notes     string `json:"notes"`
+	OptionIDs []uint `json:"option_ids"`
+}
+
+// OrderQuote is the price of a cart before it is ordered, used by the cart and
+// checkout pages.
+type OrderQuote struct {
+	Subtotal int              `json:"subtotal"`
+	Discount int              `json:"discount"`
+	Total    int              `json:"total"`
+	Vouchers []AppliedVoucher `json:"vouchers"`
+	Charges  []AppliedCharge  `json:"charges"`
+}
+
+type AppliedVoucher struct {
+	Code   string `json:"code"`
+	Amount int    `json:"amount"`
+}
+
+type AppliedCharge struct {
+	Code   string `json:"code"`
+	Label  string `json:"label"`
+	Amount int    `json:"amount"`
 }
 
 type OrderListResponse struct {
@@ -29,6 +64,7 @@ type OrderListResponse struct {
 
 type UpdateStatusRequest struct {
 	Status string `json:"status" form:"status" binding:"required"`
+	Reason string `json:"reason" form:"reason"`
 }
 
 type XenditCallbackRequest struct {
@@ -42,3 +78,11 @@ type XenditCallbackRequest struct {
 	Updated     string  `json:"updated"`
 	Created     string  `json:"created"`
 }
+
+type ReconciliationReport struct {
+	LastRunAt time.Time            `json:"last_run_at"`
+	Checked   int                  `json:"checked"`
+	Corrected int                  `json:"corrected"`
+	LastError string               `json:"last_error"`
+	Events    []model.PaymentEvent `json:"events"`
+}
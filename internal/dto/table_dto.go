@@ -0,0 +1,34 @@
+package dto
+
+import "github.com/Rakhulsr/foodcourt/internal/model"
+
+type TableRequest struct {
+	Code     string `json:"code" form:"code" binding:"required,max=20"`
+	Zone     string `json:"zone" form:"zone" binding:"max=50"`
+	Capacity int    `json:"capacity" form:"capacity" binding:"gte=0"`
+}
+
+// TableStatus is a table on the admin table board with the orders still open there.
+type TableStatus struct {
+	Table  model.Table
+	Orders []model.Order
+}
+
+func (s TableStatus) IsOccupied() bool {
+	return len(s.Orders) > 0
+}
+
+// OpenAmount is what the open orders at the table add up to.
+func (s TableStatus) OpenAmount() int {
+	total := 0
+	for _, order := range s.Orders {
+		total += order.TotalAmount
+	}
+	return total
+}
+
+type TableZone struct {
+	Zone     string
+	Tables   []TableStatus
+	Occupied int
+}
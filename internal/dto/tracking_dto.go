@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+// OrderTracking is what a customer sees while waiting for an order. It leaves out
+// the customer's name and payment details so the link is safe to share.
+type OrderTracking struct {
+	OrderCode        string          `json:"order_code"`
+	AccessToken      string          `json:"-"`
+	QueueNumber      string          `json:"queue_number,omitempty"`
+	OrderType        string          `json:"order_type"`
+	TableNumber      string          `json:"table_number,omitempty"`
+	Status           string          `json:"status"`
+	StatusLabel      string          `json:"status_label"`
+	PaymentStatus    string          `json:"payment_status"`
+	Progress         int             `json:"progress"`
+	EstimatedReadyAt *time.Time      `json:"estimated_ready_at,omitempty"`
+	Booths           []BoothProgress `json:"booths"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// BoothProgress is one booth's part of a tracked order.
+type BoothProgress struct {
+	BoothName        string        `json:"booth_name"`
+	QueueNumber      string        `json:"queue_number,omitempty"`
+	Status           string        `json:"status"`
+	StatusLabel      string        `json:"status_label"`
+	TicketsAhead     int64         `json:"tickets_ahead"`
+	EstimatedReadyAt *time.Time    `json:"estimated_ready_at,omitempty"`
+	Items            []TrackedItem `json:"items"`
+}
+
+type TrackedItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Options  string `json:"options,omitempty"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+)
+
+// VoucherRequest is the admin voucher form. StartsAt and EndsAt use the
+// datetime-local format "2006-01-02T15:04" and may be empty.
+type VoucherRequest struct {
+	Code             string `json:"code" form:"code" binding:"required,max=30"`
+	Description      string `json:"description" form:"description" binding:"max=255"`
+	Type             string `json:"type" form:"type" binding:"required,oneof=percent fixed"`
+	Value            int    `json:"value" form:"value" binding:"required,gt=0"`
+	MaxDiscount      int    `json:"max_discount" form:"max_discount" binding:"gte=0"`
+	MinSpend         int    `json:"min_spend" form:"min_spend" binding:"gte=0"`
+	BoothID          uint   `json:"booth_id" form:"booth_id"`
+	Category         string `json:"category" form:"category"`
+	StartsAt         string `json:"starts_at" form:"starts_at"`
+	EndsAt           string `json:"ends_at" form:"ends_at"`
+	UsageLimit       int    `json:"usage_limit" form:"usage_limit" binding:"gte=0"`
+	PerCustomerLimit int    `json:"per_customer_limit" form:"per_customer_limit" binding:"gte=0"`
+	Stackable        bool   `json:"stackable"`
+	IsActive         bool   `json:"is_active"`
+}
+
+type VoucherUsageRow struct {
+	Voucher       model.Voucher `json:"voucher"`
+	Uses          int           `json:"uses"`
+	TotalDiscount int           `json:"total_discount"`
+	LastUsedAt    *time.Time    `json:"last_used_at"`
+}
+
+type VoucherReport struct {
+	Rows          []VoucherUsageRow         `json:"rows"`
+	TotalUses     int                       `json:"total_uses"`
+	TotalDiscount int                       `json:"total_discount"`
+	Recent        []model.VoucherRedemption `json:"recent"`
+}
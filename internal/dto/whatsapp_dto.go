@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+// WhatsApp session states shown on the admin WhatsApp page.
+const (
+	WhatsAppConnecting   = "connecting"
+	WhatsAppPairing      = "pairing"
+	WhatsAppConnected    = "connected"
+	WhatsAppReconnecting = "reconnecting"
+	WhatsAppDisconnected = "disconnected"
+	WhatsAppLoggedOut    = "logged_out"
+)
+
+// WhatsAppStatus is a snapshot of the linked WhatsApp session. QRCode is only set
+// while the session waits for a phone to scan it.
+type WhatsAppStatus struct {
+	State       string
+	Phone       string
+	PushName    string
+	QRCode      string
+	QRExpiresAt time.Time
+	LastError   string
+	Since       time.Time
+}
+
+func (s WhatsAppStatus) StateLabel() string {
+	switch s.State {
+	case WhatsAppConnecting:
+		return "Menghubungkan"
+	case WhatsAppPairing:
+		return "Menunggu Scan QR"
+	case WhatsAppConnected:
+		return "Terhubung"
+	case WhatsAppReconnecting:
+		return "Menyambung Ulang"
+	case WhatsAppDisconnected:
+		return "Terputus"
+	case WhatsAppLoggedOut:
+		return "Belum Tertaut"
+	default:
+		return s.State
+	}
+}
+
+// IsSettling reports whether the state is expected to change on its own, so the
+// admin page keeps polling quickly.
+func (s WhatsAppStatus) IsSettling() bool {
+	return s.State == WhatsAppConnecting || s.State == WhatsAppPairing || s.State == WhatsAppReconnecting
+}
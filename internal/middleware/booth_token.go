@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// BoothTokenAuth lets a booth screen in with the token in its URL, or the
+// X-Booth-Token header, instead of the admin JWT. The booth is stored under "booth".
+func BoothTokenAuth(authenticate func(token string) (*model.Booth, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Param("token")
+		if token == "" {
+			token = c.GetHeader("X-Booth-Token")
+		}
+
+		booth, err := authenticate(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set("booth", booth)
+		c.Next()
+	}
+}
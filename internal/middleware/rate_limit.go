@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// failureWindow counts one client's failed lookups since the window started.
+type failureWindow struct {
+	count   int
+	started time.Time
+}
+
+// LimitFailedLookups blocks a client for the rest of the window once it has had
+// max responses that are 404 or 403. Customer order links are guarded with it so
+// codes and tokens cannot be enumerated; successful lookups are never counted.
+func LimitFailedLookups(max int, window time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	clients := make(map[string]*failureWindow)
+	lastSweep := time.Now()
+
+	current := func(ip string, now time.Time) *failureWindow {
+		// Forget finished windows now and then so the map does not keep every client.
+		if now.Sub(lastSweep) > window {
+			for key, w := range clients {
+				if now.Sub(w.started) > window {
+					delete(clients, key)
+				}
+			}
+			lastSweep = now
+		}
+
+		w, ok := clients[ip]
+		if !ok || now.Sub(w.started) > window {
+			w = &failureWindow{started: now}
+			clients[ip] = w
+		}
+		return w
+	}
+
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+
+		mu.Lock()
+		blocked := current(ip, time.Now()).count >= max
+		mu.Unlock()
+
+		if blocked {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Terlalu banyak percobaan, coba lagi nanti"})
+			return
+		}
+
+		c.Next()
+
+		if status := c.Writer.Status(); status == http.StatusNotFound || status == http.StatusForbidden {
+			mu.Lock()
+			current(ip, time.Now()).count++
+			mu.Unlock()
+		}
+	}
+}
@@ -10,4 +10,25 @@ type Booth struct {
 	Menus     []Menu `gorm:"foreignKey:BoothID"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	// KitchenToken opens the booth's kitchen display without an admin login.
+	KitchenToken string `gorm:"size:64;index" json:"-"`
+
+	// NotifyChannel is how the booth receives new orders, one of the NotifyChannel
+	// constants. NotifyTarget is the webhook URL or email address those channels need.
+	NotifyChannel string `gorm:"size:20;default:'whatsapp'"`
+	NotifyTarget  string `gorm:"size:255"`
+}
+
+// NotifyAddress returns the channel and address new orders for the booth go to.
+// Booths saved before channels existed use WhatsApp.
+func (b Booth) NotifyAddress() (channel string, target string) {
+	switch b.NotifyChannel {
+	case "", NotifyChannelWhatsApp:
+		return NotifyChannelWhatsApp, b.WhatsApp
+	case NotifyChannelLog:
+		return NotifyChannelLog, b.Name
+	default:
+		return b.NotifyChannel, b.NotifyTarget
+	}
 }
@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+const (
+	TicketStatusPending   = "pending"
+	TicketStatusPreparing = "preparing"
+	TicketStatusReady     = "ready"
+	TicketStatusCompleted = "completed"
+	TicketStatusCancelled = "cancelled"
+)
+
+// ticketTransitions lists, per ticket status, the statuses a kitchen ticket may move to next.
+var ticketTransitions = map[string][]string{
+	TicketStatusPending:   {TicketStatusPreparing, TicketStatusReady, TicketStatusCancelled},
+	TicketStatusPreparing: {TicketStatusReady, TicketStatusCancelled},
+	TicketStatusReady:     {TicketStatusCompleted, TicketStatusCancelled},
+	TicketStatusCompleted: {},
+	TicketStatusCancelled: {},
+}
+
+// BoothTicket is the part of an order one booth has to prepare.
+type BoothTicket struct {
+	ID        uint   `gorm:"primaryKey"`
+	OrderID   uint   `gorm:"not null;uniqueIndex:idx_ticket_order_booth"`
+	BoothID   uint   `gorm:"not null;uniqueIndex:idx_ticket_order_booth;index"`
+	Status    string `gorm:"type:enum('pending','preparing','ready','completed','cancelled');default:'pending'"`
+	Booth     Booth  `gorm:"foreignKey:BoothID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// QueueNumber counts the booth's own tickets of the business day.
+	QueueNumber int `gorm:"default:0"`
+}
+
+func (t BoothTicket) QueueLabel() string {
+	return queueLabel(t.QueueNumber)
+}
+
+func IsValidTicketStatus(status string) bool {
+	_, ok := ticketTransitions[status]
+	return ok
+}
+
+func IsFinalTicketStatus(status string) bool {
+	return status == TicketStatusCompleted || status == TicketStatusCancelled
+}
+
+func CanTransitionTicketStatus(from, to string) bool {
+	for _, next := range ticketTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+var ticketStatusLabels = map[string]string{
+	TicketStatusPending:   "Dalam Antrean",
+	TicketStatusPreparing: "Sedang Dimasak",
+	TicketStatusReady:     "Siap Diambil",
+	TicketStatusCompleted: "Sudah Diambil",
+	TicketStatusCancelled: "Dibatalkan",
+}
+
+// TicketStatusLabel returns the ticket status as shown to customers.
+func TicketStatusLabel(status string) string {
+	if label, ok := ticketStatusLabels[status]; ok {
+		return label
+	}
+	return status
+}
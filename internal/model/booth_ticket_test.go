@@ -0,0 +1,41 @@
+package model
+
+import "testing"
+
+func TestCanTransitionTicketStatus(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{TicketStatusPending, TicketStatusPreparing, true},
+		{TicketStatusPending, TicketStatusReady, true},
+		{TicketStatusPending, TicketStatusCancelled, true},
+		{TicketStatusPending, TicketStatusCompleted, false},
+		{TicketStatusPreparing, TicketStatusReady, true},
+		{TicketStatusPreparing, TicketStatusPending, false},
+		{TicketStatusReady, TicketStatusCompleted, true},
+		{TicketStatusReady, TicketStatusPreparing, false},
+		{TicketStatusCompleted, TicketStatusCancelled, false},
+		{TicketStatusCancelled, TicketStatusReady, false},
+		{"unknown", TicketStatusReady, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransitionTicketStatus(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitionTicketStatus(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestFinalTicketStatusesHaveNoTransitions(t *testing.T) {
+	for status, next := range ticketTransitions {
+		if IsFinalTicketStatus(status) != (len(next) == 0) {
+			t.Errorf("ticket status %q: final = %v but has %d transitions", status, IsFinalTicketStatus(status), len(next))
+		}
+		for _, to := range next {
+			if !IsValidTicketStatus(to) {
+				t.Errorf("ticket status %q moves to unknown status %q", status, to)
+			}
+		}
+	}
+}
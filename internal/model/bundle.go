@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Bundle sells several menus, possibly from different booths, at one price.
+// When ordered it is split back into per-booth order items.
+type Bundle struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"size:100;not null"`
+	Description string `gorm:"type:text"`
+	Price       int    `gorm:"not null"`
+	IsActive    bool   `gorm:"default:true"`
+
+	Items []BundleItem `gorm:"foreignKey:BundleID"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type BundleItem struct {
+	ID       uint `gorm:"primaryKey"`
+	BundleID uint `gorm:"index;not null"`
+	MenuID   uint `gorm:"not null"`
+	Quantity int  `gorm:"not null;default:1"`
+
+	Menu Menu `gorm:"foreignKey:MenuID"`
+}
+
+// NormalPrice is what the components would cost when ordered separately.
+func (b *Bundle) NormalPrice() int {
+	total := 0
+	for _, item := range b.Items {
+		total += item.Menu.Price * item.Quantity
+	}
+	return total
+}
+
+// IsOrderable reports whether the bundle is active and every component can be sold.
+func (b *Bundle) IsOrderable() bool {
+	if !b.IsActive || len(b.Items) == 0 {
+		return false
+	}
+	for _, item := range b.Items {
+		if !item.Menu.IsAvailable || !item.Menu.Booth.IsActive {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+const (
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+// IdempotencyKey remembers the outcome of a create-order request so a retried
+// request with the same key gets the original order back.
+type IdempotencyKey struct {
+	ID          uint   `gorm:"primaryKey"`
+	Key         string `gorm:"size:100;uniqueIndex;not null"`
+	Fingerprint string `gorm:"size:64;not null"`
+	Status      string `gorm:"type:enum('processing','completed');default:'processing'"`
+	OrderID     *uint  `gorm:"index"`
+	Response    string `gorm:"type:text"`
+
+	// LockedUntil is when a processing key is given up on. A request that died
+	// before completing leaves it behind, and a retry may take the key over after it.
+	LockedUntil *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
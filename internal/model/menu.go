@@ -6,8 +6,14 @@ type Menu struct {
 	Name        string `gorm:"size:100;not null"`
 	Price       int    `gorm:"not null"`
 	IsAvailable bool
+	Stock       *int `gorm:"default:null"`
+	// SoldOut is set when running out of stock switched the menu off, so releasing
+	// stock only undoes that and never a switch-off by the admin or booth.
+	SoldOut     bool   `gorm:"default:false"`
 	Booth       Booth  `gorm:"foreignKey:BoothID"`
 	Category    string `gorm:"size:20;default:'makanan'"`
 	Description string `gorm:"type:text"`
 	ImagePath   string `gorm:"size:255"`
+
+	OptionGroups []MenuOptionGroup `gorm:"foreignKey:MenuID"`
 }
@@ -0,0 +1,66 @@
+package model
+
+import "strings"
+
+// MenuOptionGroup is a choice the customer makes for a menu, e.g. "Ukuran" or
+// "Level Pedas". Single-select groups allow at most one option.
+type MenuOptionGroup struct {
+	ID          uint         `gorm:"primaryKey"`
+	MenuID      uint         `gorm:"index;not null"`
+	Name        string       `gorm:"size:50;not null"`
+	Required    bool         `gorm:"default:false"`
+	MultiSelect bool         `gorm:"default:false"`
+	MinSelect   int          `gorm:"default:0"`
+	MaxSelect   int          `gorm:"default:0"`
+	SortOrder   int          `gorm:"default:0"`
+	Options     []MenuOption `gorm:"foreignKey:GroupID"`
+}
+
+// SelectionBounds returns how many options must and may be picked from the group.
+func (g *MenuOptionGroup) SelectionBounds() (int, int) {
+	minSelect, maxSelect := g.MinSelect, g.MaxSelect
+	if g.Required && minSelect < 1 {
+		minSelect = 1
+	}
+	if !g.MultiSelect {
+		maxSelect = 1
+	} else if maxSelect <= 0 {
+		maxSelect = len(g.Options)
+	}
+	if maxSelect > len(g.Options) {
+		maxSelect = len(g.Options)
+	}
+	if minSelect > maxSelect {
+		minSelect = maxSelect
+	}
+	return minSelect, maxSelect
+}
+
+type MenuOption struct {
+	ID          uint   `gorm:"primaryKey"`
+	GroupID     uint   `gorm:"index;not null"`
+	Name        string `gorm:"size:50;not null"`
+	PriceDelta  int    `gorm:"default:0"`
+	IsAvailable bool   `gorm:"default:true"`
+	SortOrder   int    `gorm:"default:0"`
+}
+
+// OrderItemOption is a snapshot of a chosen option at the time of purchase, so later
+// menu edits do not change past orders.
+type OrderItemOption struct {
+	ID          uint   `gorm:"primaryKey"`
+	OrderItemID uint   `gorm:"index;not null"`
+	OptionID    uint   `gorm:"not null"`
+	GroupName   string `gorm:"size:50"`
+	OptionName  string `gorm:"size:50"`
+	PriceDelta  int
+}
+
+// OptionSummary lists the chosen options of an order item, e.g. "Besar, Level 3".
+func (i OrderItem) OptionSummary() string {
+	names := make([]string, 0, len(i.Options))
+	for _, opt := range i.Options {
+		names = append(names, opt.OptionName)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,28 @@
+package model
+
+// Channels a booth can receive its orders on.
+const (
+	NotifyChannelWhatsApp = "whatsapp"
+	NotifyChannelWebhook  = "webhook"
+	NotifyChannelEmail    = "email"
+	NotifyChannelLog      = "log"
+)
+
+var notifyChannelLabels = map[string]string{
+	NotifyChannelWhatsApp: "WhatsApp",
+	NotifyChannelWebhook:  "Webhook",
+	NotifyChannelEmail:    "Email",
+	NotifyChannelLog:      "Log server saja",
+}
+
+func IsValidNotifyChannel(channel string) bool {
+	_, ok := notifyChannelLabels[channel]
+	return ok
+}
+
+func NotifyChannelLabel(channel string) string {
+	if label, ok := notifyChannelLabels[channel]; ok {
+		return label
+	}
+	return channel
+}
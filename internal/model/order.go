@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Order struct {
 	ID            uint   `gorm:"primaryKey"`
@@ -11,10 +14,39 @@ type Order struct {
 	PaymentMethod string `gorm:"type:enum('qris','cash');not null"`
 	PaymentStatus string `gorm:"type:enum('pending','paid','expired');default:'pending'"`
 
+	// Subtotal is the price of the items before DiscountAmount is taken off;
+	// TotalAmount is what the customer pays, Charges included.
+	Subtotal       int `gorm:"default:0"`
+	DiscountAmount int `gorm:"default:0"`
+
+	// OrderType is one of the OrderType constants. Dine-in orders point at a Table;
+	// TableNumber keeps its code as it was when ordered.
+	OrderType string `gorm:"size:20;default:'dine_in'"`
+	TableID   *uint  `gorm:"index"`
+	Table     *Table `gorm:"foreignKey:TableID"`
+
+	// QueueNumber is the short number called out at pickup. It starts over every
+	// BusinessDate; OrderCode stays the unique reference.
+	QueueNumber  int    `gorm:"default:0"`
+	BusinessDate string `gorm:"size:10;index"`
+
+	// AccessToken is the secret customer links carry next to the order code.
+	AccessToken string `gorm:"size:64" json:"-"`
+
+	// CustomerPhone is the customer's normalized WhatsApp number. Leaving it at
+	// checkout opts in to progress messages; empty means none are sent.
+	CustomerPhone string `gorm:"size:20" json:"-"`
+
+	// ExpiredAt is set only when the order is cancelled because its payment window
+	// ran out, unlike other cancellations that also close the payment as expired.
+	ExpiredAt *time.Time
+
 	OrderStatus string `gorm:"type:enum('pending','confirmed','preparing','ready','completed','cancelled');default:'pending'"`
 
 	XenditInvoiceID string `gorm:"size:100"`
 	InvoiceURL      string `gorm:"size:255"`
+	// PaymentFlag marks a payment that needs an admin's attention, e.g. PaymentFlagAmountMismatch.
+	PaymentFlag string `gorm:"size:30"`
 
 	AdminTransferred bool `gorm:"default:false"`
 	AdminNote        string
@@ -22,6 +54,76 @@ type Order struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
-	Items []OrderItem   `gorm:"foreignKey:OrderID"`
-	Logs  []WhatsAppLog `gorm:"foreignKey:OrderID"`
+	Items         []OrderItem          `gorm:"foreignKey:OrderID"`
+	Tickets       []BoothTicket        `gorm:"foreignKey:OrderID"`
+	Logs          []WhatsAppLog        `gorm:"foreignKey:OrderID"`
+	StatusHistory []OrderStatusHistory `gorm:"foreignKey:OrderID"`
+	PaymentJobs   []PaymentOutbox      `gorm:"foreignKey:OrderID"`
+	PaymentEvents []PaymentEvent       `gorm:"foreignKey:OrderID"`
+	Redemptions   []VoucherRedemption  `gorm:"foreignKey:OrderID"`
+	Charges       []OrderCharge        `gorm:"foreignKey:OrderID"`
+	Messages      []OutboundMessage    `gorm:"foreignKey:OrderID"`
+}
+
+func (o Order) OrderTypeLabel() string {
+	return OrderTypeLabel(o.OrderType)
+}
+
+// SuccessPath and TrackingPath are the customer's links to the order. Both carry the
+// access token; the code alone opens nothing.
+func (o Order) SuccessPath() string {
+	return "/order/success/" + o.OrderCode + "?token=" + o.AccessToken
+}
+
+func (o Order) TrackingPath() string {
+	return "/order/track/" + o.OrderCode + "?token=" + o.AccessToken
+}
+
+// QueueLabel formats the queue number for screens and messages, e.g. "007". Orders
+// placed before queue numbers existed have none.
+func (o Order) QueueLabel() string {
+	return queueLabel(o.QueueNumber)
+}
+
+func queueLabel(number int) string {
+	if number == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%03d", number)
+}
+
+// BoothMessages returns the order's notifications to booths, CustomerMessages the
+// progress messages to the customer.
+func (o Order) BoothMessages() []OutboundMessage {
+	return o.messagesOfKind(MessageKindBoothOrder)
+}
+
+func (o Order) CustomerMessages() []OutboundMessage {
+	return o.messagesOfKind(MessageKindCustomerUpdate)
+}
+
+func (o Order) messagesOfKind(kind string) []OutboundMessage {
+	var messages []OutboundMessage
+	for _, m := range o.Messages {
+		if m.Kind == kind {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
+
+// BoothNotified reports whether a booth was sent the order before the message
+// queue existed, going by the WhatsApp log.
+func (o Order) BoothNotified() bool {
+	for _, log := range o.Logs {
+		if log.BoothID != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired reports whether the order was cancelled because it was not paid in time.
+func (o Order) IsExpired() bool {
+	return o.OrderStatus == OrderStatusCancelled && o.ExpiredAt != nil
 }
@@ -0,0 +1,11 @@
+package model
+
+// OrderCharge is one fee added to an order on top of its discounted subtotal, such as
+// the service charge or PB1 tax. Code matches the charge codes in config.
+type OrderCharge struct {
+	ID      uint   `gorm:"primaryKey"`
+	OrderID uint   `gorm:"index;not null"`
+	Code    string `gorm:"size:20;not null"`
+	Label   string `gorm:"size:50;not null"`
+	Amount  int    `gorm:"not null"`
+}
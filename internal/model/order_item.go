@@ -12,4 +12,18 @@ type OrderItem struct {
 
 	Booth Booth `gorm:"foreignKey:BoothID"`
 	Notes string
+
+	// PriceAtPurchase already includes the price deltas of Options.
+	Options []OrderItemOption `gorm:"foreignKey:OrderItemID"`
+
+	// BundleID is set when the item is a component of an ordered bundle. Its
+	// PriceAtPurchase is then the component's share of the bundle price.
+	BundleID   *uint  `gorm:"index"`
+	BundleName string `gorm:"size:100"`
+
+	// DiscountAmount is this line's share of the order's voucher discount, taken
+	// off PriceAtPurchase × Quantity.
+	DiscountAmount int `gorm:"default:0"`
+
+	StockReleased bool `gorm:"default:false"`
 }
@@ -0,0 +1,75 @@
+package model
+
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusConfirmed = "confirmed"
+	OrderStatusPreparing = "preparing"
+	OrderStatusReady     = "ready"
+	OrderStatusCompleted = "completed"
+	OrderStatusCancelled = "cancelled"
+
+	PaymentStatusPending = "pending"
+	PaymentStatusPaid    = "paid"
+	PaymentStatusExpired = "expired"
+)
+
+// orderTransitions lists, per order status, the statuses an order may move to next.
+var orderTransitions = map[string][]string{
+	OrderStatusPending:   {OrderStatusConfirmed, OrderStatusPreparing, OrderStatusCancelled},
+	OrderStatusConfirmed: {OrderStatusPreparing, OrderStatusCancelled},
+	OrderStatusPreparing: {OrderStatusReady, OrderStatusCancelled},
+	OrderStatusReady:     {OrderStatusCompleted, OrderStatusCancelled},
+	OrderStatusCompleted: {},
+	OrderStatusCancelled: {},
+}
+
+// paymentTransitions lists, per payment status, the statuses a payment may move to next.
+var paymentTransitions = map[string][]string{
+	PaymentStatusPending: {PaymentStatusPaid, PaymentStatusExpired},
+	PaymentStatusPaid:    {},
+	PaymentStatusExpired: {},
+}
+
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+func IsFinalOrderStatus(status string) bool {
+	return status == OrderStatusCompleted || status == OrderStatusCancelled
+}
+
+func CanTransitionOrderStatus(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func CanTransitionPaymentStatus(from, to string) bool {
+	for _, next := range paymentTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+var orderStatusLabels = map[string]string{
+	OrderStatusPending:   "Menunggu Pembayaran",
+	OrderStatusConfirmed: "Pesanan Diterima",
+	OrderStatusPreparing: "Sedang Disiapkan",
+	OrderStatusReady:     "Siap Diambil",
+	OrderStatusCompleted: "Selesai",
+	OrderStatusCancelled: "Dibatalkan",
+}
+
+// OrderStatusLabel returns the status as shown to customers.
+func OrderStatusLabel(status string) string {
+	if label, ok := orderStatusLabels[status]; ok {
+		return label
+	}
+	return status
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+const (
+	StatusFieldOrder   = "order_status"
+	StatusFieldPayment = "payment_status"
+	StatusFieldTicket  = "ticket_status"
+
+	ActorAdmin  = "admin"
+	ActorXendit = "xendit"
+	ActorSystem = "system"
+	ActorBooth  = "booth"
+)
+
+// StatusActor identifies who triggered a status transition.
+type StatusActor struct {
+	Type    string
+	AdminID *uint
+}
+
+type OrderStatusHistory struct {
+	ID         uint   `gorm:"primaryKey"`
+	OrderID    uint   `gorm:"index;not null"`
+	BoothID    *uint  `gorm:"index"`
+	Booth      *Booth `gorm:"foreignKey:BoothID"`
+	Field      string `gorm:"size:20;not null"`
+	FromStatus string `gorm:"size:20"`
+	ToStatus   string `gorm:"size:20;not null"`
+	ActorType  string `gorm:"size:20;not null"`
+	AdminID    *uint  `gorm:"index"`
+	Admin      *Admin `gorm:"foreignKey:AdminID"`
+	Reason     string `gorm:"size:255"`
+	CreatedAt  time.Time
+}
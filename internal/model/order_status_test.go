@@ -0,0 +1,64 @@
+package model
+
+import "testing"
+
+func TestCanTransitionOrderStatus(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{OrderStatusPending, OrderStatusConfirmed, true},
+		{OrderStatusPending, OrderStatusPreparing, true},
+		{OrderStatusPending, OrderStatusCancelled, true},
+		{OrderStatusPending, OrderStatusReady, false},
+		{OrderStatusPending, OrderStatusCompleted, false},
+		{OrderStatusConfirmed, OrderStatusPreparing, true},
+		{OrderStatusConfirmed, OrderStatusReady, false},
+		{OrderStatusPreparing, OrderStatusReady, true},
+		{OrderStatusPreparing, OrderStatusConfirmed, false},
+		{OrderStatusReady, OrderStatusCompleted, true},
+		{OrderStatusReady, OrderStatusCancelled, true},
+		{OrderStatusCompleted, OrderStatusCancelled, false},
+		{OrderStatusCancelled, OrderStatusPending, false},
+		{OrderStatusPending, OrderStatusPending, false},
+		{"unknown", OrderStatusConfirmed, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransitionOrderStatus(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitionOrderStatus(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestCanTransitionPaymentStatus(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{PaymentStatusPending, PaymentStatusPaid, true},
+		{PaymentStatusPending, PaymentStatusExpired, true},
+		{PaymentStatusPaid, PaymentStatusExpired, false},
+		{PaymentStatusPaid, PaymentStatusPending, false},
+		{PaymentStatusExpired, PaymentStatusPaid, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransitionPaymentStatus(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitionPaymentStatus(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestFinalOrderStatusesHaveNoTransitions(t *testing.T) {
+	for status, next := range orderTransitions {
+		if IsFinalOrderStatus(status) != (len(next) == 0) {
+			t.Errorf("order status %q: final = %v but has %d transitions", status, IsFinalOrderStatus(status), len(next))
+		}
+		for _, to := range next {
+			if !IsValidOrderStatus(to) {
+				t.Errorf("order status %q moves to unknown status %q", status, to)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+// Order types. Dine-in orders are served at a Table; takeaway and pickup orders
+// are packed and pay for packaging.
+const (
+	OrderTypeDineIn   = "dine_in"
+	OrderTypeTakeaway = "takeaway"
+	OrderTypePickup   = "pickup"
+)
+
+var orderTypeLabels = map[string]string{
+	OrderTypeDineIn:   "Makan di Sini",
+	OrderTypeTakeaway: "Bawa Pulang",
+	OrderTypePickup:   "Ambil di Booth",
+}
+
+func IsValidOrderType(orderType string) bool {
+	_, ok := orderTypeLabels[orderType]
+	return ok
+}
+
+// IsPackedOrderType reports whether orders of this type leave the foodcourt packed.
+func IsPackedOrderType(orderType string) bool {
+	return orderType == OrderTypeTakeaway || orderType == OrderTypePickup
+}
+
+func OrderTypeLabel(orderType string) string {
+	if label, ok := orderTypeLabels[orderType]; ok {
+		return label
+	}
+	return orderTypeLabels[OrderTypeDineIn]
+}
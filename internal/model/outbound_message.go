@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+const (
+	MessageKindBoothOrder     = "booth_order"
+	MessageKindCustomerUpdate = "customer_update"
+
+	MessageStatusQueued    = "queued"
+	MessageStatusSending   = "sending"
+	MessageStatusSent      = "sent"
+	MessageStatusDead      = "dead"
+	MessageStatusCancelled = "cancelled"
+)
+
+// OutboundMessage is a notification waiting in, or done with, the message outbox.
+// Messages to one recipient are delivered in ID order: a message waits while an
+// older one to the same channel and recipient is still queued or sending.
+type OutboundMessage struct {
+	ID            uint      `gorm:"primaryKey"`
+	OrderID       *uint     `gorm:"index"`
+	Order         *Order    `gorm:"foreignKey:OrderID"`
+	BoothID       *uint     `gorm:"index"`
+	Booth         *Booth    `gorm:"foreignKey:BoothID"`
+	Kind          string    `gorm:"size:30;not null"`
+	Channel       string    `gorm:"size:20;not null;index:idx_message_recipient"`
+	Recipient     string    `gorm:"size:255;not null;index:idx_message_recipient"`
+	Subject       string    `gorm:"size:255"`
+	Body          string    `gorm:"type:text"`
+	Status        string    `gorm:"size:20;default:'queued';index"`
+	Attempts      int       `gorm:"default:0"`
+	NextAttemptAt time.Time `gorm:"index"`
+	LockedUntil   *time.Time
+	LastError     string `gorm:"type:text"`
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// IsFinal reports whether the outbox is done with the message.
+func (m OutboundMessage) IsFinal() bool {
+	return m.Status == MessageStatusSent || m.Status == MessageStatusDead || m.Status == MessageStatusCancelled
+}
+
+var messageStatusLabels = map[string]string{
+	MessageStatusQueued:    "Antre",
+	MessageStatusSending:   "Mengirim",
+	MessageStatusSent:      "Terkirim",
+	MessageStatusDead:      "Gagal",
+	MessageStatusCancelled: "Dibatalkan",
+}
+
+// MessageStatusLabel returns the Indonesian label for a message status.
+func MessageStatusLabel(status string) string {
+	if label, ok := messageStatusLabels[status]; ok {
+		return label
+	}
+	return status
+}
+
+func (m OutboundMessage) StatusLabel() string {
+	return MessageStatusLabel(m.Status)
+}
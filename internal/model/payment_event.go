@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+const (
+	PaymentEventSourceWebhook   = "webhook"
+	PaymentEventSourceReconcile = "reconcile"
+	PaymentEventSourceRecheck   = "recheck"
+
+	PaymentEventReceived       = "received"
+	PaymentEventApplied        = "applied"
+	PaymentEventIgnored        = "ignored"
+	PaymentEventStale          = "stale"
+	PaymentEventAmountMismatch = "amount_mismatch"
+	PaymentEventUnknownOrder   = "unknown_order"
+
+	PaymentFlagAmountMismatch = "amount_mismatch"
+	PaymentFlagPaidAfterClose = "paid_after_close"
+)
+
+// PaymentEvent stores every verified payment callback as received, as well as
+// statuses found by reconciliation. DedupeKey makes provider retries and
+// reconciliation of the same status land on one row, so each event is processed once.
+type PaymentEvent struct {
+	ID         uint   `gorm:"primaryKey"`
+	OrderID    *uint  `gorm:"index"`
+	Order      *Order `gorm:"foreignKey:OrderID"`
+	Provider   string `gorm:"size:20;not null"`
+	Source     string `gorm:"size:20;default:'webhook';index"`
+	DedupeKey  string `gorm:"size:150;uniqueIndex;not null"`
+	InvoiceID  string `gorm:"size:100"`
+	ExternalID string `gorm:"size:50;index"`
+	Status     string `gorm:"size:20"`
+	Amount     int
+	PaidAmount int
+	Payload    string `gorm:"type:text"`
+	Outcome    string `gorm:"size:20;default:'received';index"`
+	Note       string `gorm:"size:255"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+const (
+	OutboxActionCreateInvoice = "create_invoice"
+
+	OutboxStatusPending    = "pending"
+	OutboxStatusProcessing = "processing"
+	OutboxStatusDone       = "done"
+	OutboxStatusFailed     = "failed"
+)
+
+// PaymentOutbox is a payment provider call that must happen after an order is committed.
+// It is written in the same transaction as the order and processed by a worker.
+type PaymentOutbox struct {
+	ID            uint      `gorm:"primaryKey"`
+	OrderID       uint      `gorm:"index;not null"`
+	Order         *Order    `gorm:"foreignKey:OrderID"`
+	Action        string    `gorm:"size:30;not null"`
+	Status        string    `gorm:"type:enum('pending','processing','done','failed');default:'pending';index"`
+	Attempts      int       `gorm:"default:0"`
+	NextAttemptAt time.Time `gorm:"index"`
+	LockedUntil   *time.Time
+	LastError     string `gorm:"type:text"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,17 @@
+package model
+
+import "fmt"
+
+// QueueScopeOrder numbers whole orders; QueueScopeBooth numbers one booth's tickets.
+const QueueScopeOrder = "order"
+
+func QueueScopeBooth(boothID uint) string {
+	return fmt.Sprintf("booth:%d", boothID)
+}
+
+// QueueCounter holds the last queue number handed out in a scope on a business day.
+type QueueCounter struct {
+	BusinessDate string `gorm:"primaryKey;size:10"`
+	Scope        string `gorm:"primaryKey;size:20"`
+	LastNumber   int    `gorm:"not null;default:0"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Table is a dine-in table. Code is stored normalized, so "a-1", "A 1" and "A1"
+// all refer to the same table.
+type Table struct {
+	ID       uint   `gorm:"primaryKey"`
+	Code     string `gorm:"uniqueIndex;size:10;not null"`
+	Zone     string `gorm:"size:50"`
+	Capacity int    `gorm:"default:4"`
+	IsActive bool   `gorm:"default:true"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NormalizeTableCode upper-cases a table code and drops everything but letters
+// and digits.
+func NormalizeTableCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, code)
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+const (
+	VoucherTypePercent = "percent"
+	VoucherTypeFixed   = "fixed"
+)
+
+// Voucher is a discount code. A zero BoothID or empty Category means the voucher
+// applies to every booth or category, and a zero limit means unlimited.
+type Voucher struct {
+	ID          uint   `gorm:"primaryKey"`
+	Code        string `gorm:"size:30;uniqueIndex;not null"`
+	Description string `gorm:"size:255"`
+	Type        string `gorm:"size:10;not null"`
+	Value       int    `gorm:"not null"`
+	MaxDiscount int    `gorm:"default:0"`
+	MinSpend    int    `gorm:"default:0"`
+
+	BoothID  *uint  `gorm:"index"`
+	Booth    *Booth `gorm:"foreignKey:BoothID"`
+	Category string `gorm:"size:20"`
+
+	StartsAt *time.Time
+	EndsAt   *time.Time
+
+	UsageLimit       int  `gorm:"default:0"`
+	PerCustomerLimit int  `gorm:"default:0"`
+	Stackable        bool `gorm:"default:false"`
+	IsActive         bool `gorm:"default:true"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsValidAt reports whether the voucher is active and inside its validity window.
+func (v *Voucher) IsValidAt(t time.Time) bool {
+	if !v.IsActive {
+		return false
+	}
+	if v.StartsAt != nil && t.Before(*v.StartsAt) {
+		return false
+	}
+	if v.EndsAt != nil && t.After(*v.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// AppliesTo reports whether an item of the given booth and menu category is in scope.
+func (v *Voucher) AppliesTo(boothID uint, category string) bool {
+	if v.BoothID != nil && *v.BoothID != boothID {
+		return false
+	}
+	if v.Category != "" && v.Category != category {
+		return false
+	}
+	return true
+}
+
+// VoucherRedemption records a voucher used by an order. Redemptions of cancelled
+// orders no longer count towards the usage limits.
+type VoucherRedemption struct {
+	ID          uint     `gorm:"primaryKey"`
+	VoucherID   uint     `gorm:"index;not null"`
+	Voucher     *Voucher `gorm:"foreignKey:VoucherID"`
+	OrderID     uint     `gorm:"index;not null"`
+	Order       *Order   `gorm:"foreignKey:OrderID"`
+	Code        string   `gorm:"size:30;not null"`
+	CustomerKey string   `gorm:"size:100;index"`
+	Amount      int      `gorm:"not null"`
+	CreatedAt   time.Time
+}
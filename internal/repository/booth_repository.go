@@ -12,6 +12,7 @@ type BoothRepository interface {
 	FindByID(id uint) (*model.Booth, error)
 	FindByName(keyword string) ([]model.Booth, error)
 	FindByExactName(name string) (*model.Booth, error)
+	FindByKitchenToken(token string) (*model.Booth, error)
 	Update(booth *model.Booth) error
 	Delete(id uint) error
 }
@@ -49,6 +50,14 @@ func (r *BoothRepositoryImpl) FindByExactName(name string) (*model.Booth, error)
 	return &booth, err
 }
 
+func (r *BoothRepositoryImpl) FindByKitchenToken(token string) (*model.Booth, error) {
+	var booth model.Booth
+	if err := r.db.Where("kitchen_token = ?", token).First(&booth).Error; err != nil {
+		return nil, err
+	}
+	return &booth, nil
+}
+
 func (r *BoothRepositoryImpl) FindByName(keyword string) ([]model.Booth, error) {
 	var booths []model.Booth
 
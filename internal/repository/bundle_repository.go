@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"gorm.io/gorm"
+)
+
+type BundleRepository interface {
+	FindAll() ([]model.Bundle, error)
+	FindActive() ([]model.Bundle, error)
+	FindByID(id uint) (*model.Bundle, error)
+	Create(bundle *model.Bundle) error
+	SetActive(id uint, active bool) error
+	Delete(id uint) error
+}
+
+type bundleRepository struct {
+	db *gorm.DB
+}
+
+func NewBundleRepository(db *gorm.DB) BundleRepository {
+	return &bundleRepository{db: db}
+}
+
+// preloadBundleItems loads the component menus with their booth and options.
+func preloadBundleItems(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Items.Menu").
+		Preload("Items.Menu.Booth").
+		Preload("Items.Menu.OptionGroups", orderBySort).
+		Preload("Items.Menu.OptionGroups.Options", orderBySort)
+}
+
+func (r *bundleRepository) FindAll() ([]model.Bundle, error) {
+	var bundles []model.Bundle
+	err := r.db.Scopes(preloadBundleItems).Order("id DESC").Find(&bundles).Error
+	return bundles, err
+}
+
+func (r *bundleRepository) FindActive() ([]model.Bundle, error) {
+	var bundles []model.Bundle
+	err := r.db.Scopes(preloadBundleItems).
+		Where("is_active = ?", true).
+		Order("id DESC").
+		Find(&bundles).Error
+	return bundles, err
+}
+
+func (r *bundleRepository) FindByID(id uint) (*model.Bundle, error) {
+	var bundle model.Bundle
+	err := r.db.Scopes(preloadBundleItems).First(&bundle, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (r *bundleRepository) Create(bundle *model.Bundle) error {
+	return r.db.Omit("Items.Menu").Create(bundle).Error
+}
+
+func (r *bundleRepository) SetActive(id uint, active bool) error {
+	return r.db.Model(&model.Bundle{}).Where("id = ?", id).Update("is_active", active).Error
+}
+
+// Delete removes the bundle and its components. Past orders keep their items
+// because they only store the bundle ID and name.
+func (r *bundleRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", id).Delete(&model.BundleItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Bundle{}, id).Error
+	})
+}
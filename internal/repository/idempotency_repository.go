@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrIdempotencyKeyTaken is returned when the key an order is created for was completed
+// by another request meanwhile. The order is not stored; the key's order is the one.
+var ErrIdempotencyKeyTaken = errors.New("idempotency key sudah dipakai oleh permintaan lain")
+
+type IdempotencyRepository interface {
+	Reserve(record *model.IdempotencyKey, now time.Time, lockFor time.Duration) (bool, error)
+	FindByKey(key string) (*model.IdempotencyKey, error)
+	Release(key string) error
+}
+
+// IdempotencyCompletion is the key an order is created for and the response kept for
+// its retries.
+type IdempotencyCompletion struct {
+	Key      string
+	Response string
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve inserts the key in processing state, locked for lockFor. It returns false
+// when the key already exists, unless it is still processing the same request and
+// its lock has run out, in which case the key is taken over.
+func (r *idempotencyRepository) Reserve(record *model.IdempotencyKey, now time.Time, lockFor time.Duration) (bool, error) {
+	lockedUntil := now.Add(lockFor)
+	record.LockedUntil = &lockedUntil
+
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+
+	res = r.db.Model(&model.IdempotencyKey{}).
+		Where("`key` = ? AND fingerprint = ? AND status = ? AND (locked_until IS NULL OR locked_until < ?)",
+			record.Key, record.Fingerprint, model.IdempotencyStatusProcessing, now).
+		Update("locked_until", lockedUntil)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *idempotencyRepository) FindByKey(key string) (*model.IdempotencyKey, error) {
+	var record model.IdempotencyKey
+	err := r.db.Where("`key` = ?", key).First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// completeIdempotencyKey links the key to its order. It runs in the transaction that
+// creates the order, so a key is completed exactly when its order exists.
+func completeIdempotencyKey(tx *gorm.DB, completion *IdempotencyCompletion, orderID uint) error {
+	res := tx.Model(&model.IdempotencyKey{}).
+		Where("`key` = ? AND status = ?", completion.Key, model.IdempotencyStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":       model.IdempotencyStatusCompleted,
+			"order_id":     orderID,
+			"response":     completion.Response,
+			"locked_until": nil,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrIdempotencyKeyTaken
+	}
+	return nil
+}
+
+// Release removes a key whose request failed, so the client may retry with it.
+func (r *idempotencyRepository) Release(key string) error {
+	return r.db.Where("`key` = ? AND status = ?", key, model.IdempotencyStatusProcessing).
+		Delete(&model.IdempotencyKey{}).Error
+}
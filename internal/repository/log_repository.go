@@ -39,6 +39,7 @@ func (r *whatsAppLogRepository) FindAll(page int, limit int) ([]model.WhatsAppLo
 
 	err := r.db.
 		Preload("Order").
+		Preload("Booth").
 		Order("sent_at DESC").
 		Limit(limit).
 		Offset(offset).
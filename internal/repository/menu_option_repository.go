@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"gorm.io/gorm"
+)
+
+type MenuOptionRepository interface {
+	FindGroupsByMenuID(menuID uint) ([]model.MenuOptionGroup, error)
+	FindGroupByID(id uint) (*model.MenuOptionGroup, error)
+	CreateGroup(group *model.MenuOptionGroup) error
+	DeleteGroup(id uint) error
+
+	FindOptionByID(id uint) (*model.MenuOption, error)
+	CreateOption(option *model.MenuOption) error
+	UpdateOption(option *model.MenuOption) error
+	DeleteOption(id uint) error
+}
+
+type menuOptionRepository struct {
+	db *gorm.DB
+}
+
+func NewMenuOptionRepository(db *gorm.DB) MenuOptionRepository {
+	return &menuOptionRepository{db: db}
+}
+
+func (r *menuOptionRepository) FindGroupsByMenuID(menuID uint) ([]model.MenuOptionGroup, error) {
+	var groups []model.MenuOptionGroup
+	err := r.db.
+		Preload("Options", orderBySort).
+		Where("menu_id = ?", menuID).
+		Order("sort_order ASC, id ASC").
+		Find(&groups).Error
+	return groups, err
+}
+
+func (r *menuOptionRepository) FindGroupByID(id uint) (*model.MenuOptionGroup, error) {
+	var group model.MenuOptionGroup
+	err := r.db.Preload("Options", orderBySort).First(&group, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *menuOptionRepository) CreateGroup(group *model.MenuOptionGroup) error {
+	return r.db.Create(group).Error
+}
+
+// DeleteGroup removes the group together with its options.
+func (r *menuOptionRepository) DeleteGroup(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&model.MenuOption{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.MenuOptionGroup{}, id).Error
+	})
+}
+
+func (r *menuOptionRepository) FindOptionByID(id uint) (*model.MenuOption, error) {
+	var option model.MenuOption
+	err := r.db.First(&option, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &option, nil
+}
+
+func (r *menuOptionRepository) CreateOption(option *model.MenuOption) error {
+	return r.db.Create(option).Error
+}
+
+func (r *menuOptionRepository) UpdateOption(option *model.MenuOption) error {
+	return r.db.Save(option).Error
+}
+
+func (r *menuOptionRepository) DeleteOption(id uint) error {
+	return r.db.Delete(&model.MenuOption{}, id).Error
+}
+
+func orderBySort(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC, id ASC")
+}
+
+// preloadMenuOptions loads a menu's option groups and options in display order.
+func preloadMenuOptions(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("OptionGroups", orderBySort).
+		Preload("OptionGroups.Options", orderBySort)
+}
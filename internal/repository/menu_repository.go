@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"fmt"
+
 	"github.com/Rakhulsr/foodcourt/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MenuRepository interface {
@@ -18,6 +21,9 @@ type MenuRepository interface {
 
 	Update(menu *model.Menu) error
 	Delete(id uint) error
+
+	AddStock(id uint, quantity int) error
+	SetStock(id uint, stock *int) error
 }
 
 type menuRepository struct {
@@ -34,7 +40,7 @@ func (r *menuRepository) Create(menu *model.Menu) error {
 
 func (r *menuRepository) FindAll() ([]model.Menu, error) {
 	var menus []model.Menu
-	err := r.db.Preload("Booth").Find(&menus).Error
+	err := r.db.Scopes(preloadMenuOptions).Preload("Booth").Find(&menus).Error
 	return menus, err
 }
 
@@ -47,6 +53,7 @@ func (r *menuRepository) FindByBoothID(boothID uint) ([]model.Menu, error) {
 func (r *menuRepository) FindActive() ([]model.Menu, error) {
 	var menus []model.Menu
 	err := r.db.
+		Scopes(preloadMenuOptions).
 		Preload("Booth").
 		Joins("JOIN booths ON booths.id = menus.booth_id").
 		Where("menus.is_available = ? AND booths.is_active = ?", true, true).
@@ -57,7 +64,7 @@ func (r *menuRepository) FindActive() ([]model.Menu, error) {
 
 func (r *menuRepository) FindByID(id uint) (*model.Menu, error) {
 	var menu model.Menu
-	err := r.db.Preload("Booth").First(&menu, id).Error
+	err := r.db.Scopes(preloadMenuOptions).Preload("Booth").First(&menu, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +74,7 @@ func (r *menuRepository) FindByID(id uint) (*model.Menu, error) {
 func (r *menuRepository) FindActiveByBoothID(boothID uint) ([]model.Menu, error) {
 	var menus []model.Menu
 	err := r.db.
+		Scopes(preloadMenuOptions).
 		Preload("Booth").
 		Joins("JOIN booths ON booths.id = menus.booth_id").
 		Where("menus.booth_id = ? AND menus.is_available = ? AND booths.is_active = ?", boothID, true, true).
@@ -80,6 +88,7 @@ func (r *menuRepository) FindByName(keyword string) ([]model.Menu, error) {
 	searchKey := "%" + keyword + "%"
 
 	err := r.db.
+		Scopes(preloadMenuOptions).
 		Preload("Booth").
 		Joins("JOIN booths ON booths.id = menus.booth_id").
 		Where(
@@ -94,6 +103,7 @@ func (r *menuRepository) FindByName(keyword string) ([]model.Menu, error) {
 func (r *menuRepository) FindByCategory(category string) ([]model.Menu, error) {
 	var menus []model.Menu
 	err := r.db.
+		Scopes(preloadMenuOptions).
 		Preload("Booth").
 		Joins("JOIN booths ON booths.id = menus.booth_id").
 		Where("menus.category = ? AND menus.is_available = ? AND booths.is_active = ?", category, true, true).
@@ -101,9 +111,116 @@ func (r *menuRepository) FindByCategory(category string) ([]model.Menu, error) {
 	return menus, err
 }
 func (r *menuRepository) Update(menu *model.Menu) error {
-	return r.db.Save(menu).Error
+	// Stock is only changed through the atomic stock methods below, and option
+	// groups through MenuOptionRepository. Availability saved here is a deliberate
+	// choice, so it is no longer treated as sold out.
+	menu.SoldOut = false
+	return r.db.Omit("Stock", "OptionGroups").Save(menu).Error
 }
 
 func (r *menuRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Menu{}, id).Error
 }
+
+// AddStock adds quantity to the menu stock, starting tracking if it was untracked.
+// A menu that was sold out becomes available again.
+func (r *menuRepository) AddStock(id uint, quantity int) error {
+	return r.db.Exec(
+		"UPDATE menus SET is_available = IF(COALESCE(stock, 0) = 0 AND ? > 0, TRUE, is_available), sold_out = FALSE, stock = COALESCE(stock, 0) + ? WHERE id = ?",
+		quantity, quantity, id,
+	).Error
+}
+
+// SetStock overwrites the menu stock. A nil stock turns tracking off.
+func (r *menuRepository) SetStock(id uint, stock *int) error {
+	if stock == nil {
+		return r.db.Model(&model.Menu{}).Where("id = ?", id).Update("stock", gorm.Expr("NULL")).Error
+	}
+	return r.db.Exec(
+		"UPDATE menus SET sold_out = IF(?, FALSE, is_available OR sold_out), is_available = IF(?, is_available OR COALESCE(stock, 0) = 0, FALSE), stock = ? WHERE id = ?",
+		*stock > 0, *stock > 0, *stock, id,
+	).Error
+}
+
+// reserveStock locks the tracked menus of the given items and decrements their stock.
+// It must run inside the transaction that creates the order. Items whose menu is not
+// tracked are marked as released so cancelling them never adds stock.
+func reserveStock(tx *gorm.DB, items []model.OrderItem) error {
+	needed := make(map[uint]int)
+	var ids []uint
+	for _, item := range items {
+		if _, ok := needed[item.MenuID]; !ok {
+			ids = append(ids, item.MenuID)
+		}
+		needed[item.MenuID] += item.Quantity
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var menus []model.Menu
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).
+		Order("id ASC").
+		Find(&menus).Error
+	if err != nil {
+		return err
+	}
+
+	tracked := make(map[uint]bool)
+	for _, m := range menus {
+		if m.Stock == nil {
+			continue
+		}
+		tracked[m.ID] = true
+
+		if *m.Stock < needed[m.ID] {
+			return fmt.Errorf("stok menu '%s' tidak cukup (sisa %d)", m.Name, *m.Stock)
+		}
+
+		err := tx.Exec(
+			"UPDATE menus SET stock = stock - ?, sold_out = IF(stock = 0, is_available OR sold_out, sold_out), is_available = IF(stock = 0, FALSE, is_available) WHERE id = ?",
+			needed[m.ID], m.ID,
+		).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range items {
+		items[i].StockReleased = !tracked[items[i].MenuID]
+	}
+	return nil
+}
+
+// releaseStock returns the stock held by an order's items, optionally only for one booth.
+// Each item is released at most once. Only a menu that went off sale by selling out
+// is put back on sale.
+func releaseStock(tx *gorm.DB, orderID uint, boothID *uint) error {
+	query := tx.Where("order_id = ? AND stock_released = ?", orderID, false)
+	if boothID != nil {
+		query = query.Where("booth_id = ?", *boothID)
+	}
+
+	var items []model.OrderItem
+	if err := query.Find(&items).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	var ids []uint
+	for _, item := range items {
+		err := tx.Exec(
+			"UPDATE menus SET is_available = IF(sold_out, TRUE, is_available), sold_out = FALSE, stock = stock + ? WHERE id = ? AND stock IS NOT NULL",
+			item.Quantity, item.MenuID,
+		).Error
+		if err != nil {
+			return err
+		}
+		ids = append(ids, item.ID)
+	}
+
+	return tx.Model(&model.OrderItem{}).Where("id IN ?", ids).Update("stock_released", true).Error
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"gorm.io/gorm"
+)
+
+type MessageRepository interface {
+	Create(messages []model.OutboundMessage) error
+	FindDue(now time.Time, limit int) ([]model.OutboundMessage, error)
+	FindByIDs(ids []uint) ([]model.OutboundMessage, error)
+	FindAll(page int, limit int, status string) ([]model.OutboundMessage, int64, error)
+	CountByStatus() (map[string]int64, error)
+	Claim(id uint, now time.Time, lockFor time.Duration) (bool, error)
+	MarkSent(id uint, sentAt time.Time) error
+	MarkRetry(id uint, nextAttemptAt time.Time, lastError string) error
+	MarkDead(id uint, lastError string) error
+	Requeue(id uint, now time.Time) (bool, error)
+	Cancel(id uint) (bool, error)
+}
+
+type messageRepository struct {
+	db *gorm.DB
+}
+
+func NewMessageRepository(db *gorm.DB) MessageRepository {
+	return &messageRepository{db: db}
+}
+
+func (r *messageRepository) Create(messages []model.OutboundMessage) error {
+	return r.db.Create(&messages).Error
+}
+
+// FindDue returns messages that are waiting for their next attempt or whose worker
+// lock has expired, skipping any that still has an older undelivered message to
+// the same recipient.
+func (r *messageRepository) FindDue(now time.Time, limit int) ([]model.OutboundMessage, error) {
+	var messages []model.OutboundMessage
+	err := r.db.
+		Preload("Order", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "order_code")
+		}).
+		Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+			model.MessageStatusQueued, now, model.MessageStatusSending, now).
+		Where(`NOT EXISTS (SELECT 1 FROM outbound_messages older
+			WHERE older.channel = outbound_messages.channel AND older.recipient = outbound_messages.recipient
+			AND older.id < outbound_messages.id AND older.status IN ?)`,
+			[]string{model.MessageStatusQueued, model.MessageStatusSending}).
+		Order("id ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *messageRepository) FindByIDs(ids []uint) ([]model.OutboundMessage, error) {
+	var messages []model.OutboundMessage
+	err := r.db.Preload("Booth").Where("id IN ?", ids).Order("id ASC").Find(&messages).Error
+	return messages, err
+}
+
+func (r *messageRepository) FindAll(page int, limit int, status string) ([]model.OutboundMessage, int64, error) {
+	var messages []model.OutboundMessage
+	var total int64
+
+	query := r.db.Model(&model.OutboundMessage{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("Order").
+		Preload("Booth").
+		Order("id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&messages).Error
+	return messages, total, err
+}
+
+func (r *messageRepository) CountByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Total  int64
+	}
+	err := r.db.Model(&model.OutboundMessage{}).
+		Select("status, COUNT(*) AS total").
+		Group("status").
+		Scan(&rows).Error
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Total
+	}
+	return counts, err
+}
+
+// Claim marks the message as sending for lockFor. It returns false when another
+// worker got there first or the message was cancelled meanwhile.
+func (r *messageRepository) Claim(id uint, now time.Time, lockFor time.Duration) (bool, error) {
+	res := r.db.Model(&model.OutboundMessage{}).
+		Where("id = ? AND ((status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?))",
+			id, model.MessageStatusQueued, now, model.MessageStatusSending, now).
+		Updates(map[string]interface{}{
+			"status":       model.MessageStatusSending,
+			"locked_until": now.Add(lockFor),
+			"attempts":     gorm.Expr("attempts + 1"),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *messageRepository) MarkSent(id uint, sentAt time.Time) error {
+	return r.db.Model(&model.OutboundMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       model.MessageStatusSent,
+			"sent_at":      sentAt,
+			"locked_until": nil,
+			"last_error":   "",
+		}).Error
+}
+
+func (r *messageRepository) MarkRetry(id uint, nextAttemptAt time.Time, lastError string) error {
+	return r.db.Model(&model.OutboundMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          model.MessageStatusQueued,
+			"next_attempt_at": nextAttemptAt,
+			"locked_until":    nil,
+			"last_error":      lastError,
+		}).Error
+}
+
+func (r *messageRepository) MarkDead(id uint, lastError string) error {
+	return r.db.Model(&model.OutboundMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       model.MessageStatusDead,
+			"locked_until": nil,
+			"last_error":   lastError,
+		}).Error
+}
+
+// Requeue gives a dead or cancelled message a fresh set of attempts.
+func (r *messageRepository) Requeue(id uint, now time.Time) (bool, error) {
+	res := r.db.Model(&model.OutboundMessage{}).
+		Where("id = ? AND status IN ?", id, []string{model.MessageStatusDead, model.MessageStatusCancelled}).
+		Updates(map[string]interface{}{
+			"status":          model.MessageStatusQueued,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"locked_until":    nil,
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+// Cancel stops a message that has not been picked up by a worker.
+func (r *messageRepository) Cancel(id uint) (bool, error) {
+	res := r.db.Model(&model.OutboundMessage{}).
+		Where("id = ? AND status = ?", id, model.MessageStatusQueued).
+		Update("status", model.MessageStatusCancelled)
+	return res.RowsAffected == 1, res.Error
+}
@@ -1,24 +1,39 @@
 package repository
 
 import (
+	"errors"
 	"time"
 
 	"github.com/Rakhulsr/foodcourt/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrStaleOrderStatus is returned when an order's status changed between read and write.
+var ErrStaleOrderStatus = errors.New("status pesanan sudah berubah, silakan muat ulang")
+
 type OrderRepository interface {
-	Create(order *model.Order) error
+	Create(order *model.Order, idempotency *IdempotencyCompletion) error
 	FindByCode(code string) (*model.Order, error)
+	FindByID(id uint) (*model.Order, error)
 	UpdateInvoice(orderCode string, invoiceID string, invoiceURL string) error
+	SetPaymentFlag(orderID uint, flag string) error
+	FindAwaitingPayment(createdBefore time.Time, limit int) ([]model.Order, error)
+	FindUnpaidPending(paymentMethod string, createdBefore time.Time, limit int) ([]model.Order, error)
 
 	FindAll(page int, limit int, status string) ([]model.Order, int64, error)
 	UpdatePaymentStatus(orderCode string, status string) error
 	UpdateOrderStatus(orderCode string, status string) error
+	ApplyStatusChange(order *model.Order, updates map[string]interface{}, tickets []TicketChange, history []model.OrderStatusHistory) error
 
 	GetTotalIncomeToday() (int, error)
+	GetBoothIncomeToday() ([]BoothIncome, error)
+	GetChargesCollectedToday() ([]ChargeTotal, error)
 	CountOrdersToday() (int64, error)
 	FindOrdersToday() ([]model.Order, error)
+	FindOpenDineIn() ([]model.Order, error)
+	FindKitchenQueue(boothID uint) ([]model.Order, error)
+	FindServing(businessDate string) ([]model.Order, error)
 }
 
 type orderRepository struct {
@@ -29,8 +44,64 @@ func NewOrderRepository(db *gorm.DB) OrderRepository {
 	return &orderRepository{db: db}
 }
 
-func (r *orderRepository) Create(order *model.Order) error {
-	return r.db.Omit("Items.ID").Create(order).Error
+// Create stores the order and reserves stock for its items and uses of its vouchers
+// in one transaction. The order and its tickets get the next queue numbers of
+// order.BusinessDate. With an idempotency key, the key is completed in the same
+// transaction.
+func (r *orderRepository) Create(order *model.Order, idempotency *IdempotencyCompletion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := reserveStock(tx, order.Items); err != nil {
+			return err
+		}
+		if err := reserveVouchers(tx, order.Redemptions); err != nil {
+			return err
+		}
+		if err := assignQueueNumbers(tx, order); err != nil {
+			return err
+		}
+		if err := tx.Omit("Items.ID").Create(order).Error; err != nil {
+			return err
+		}
+		if idempotency == nil {
+			return nil
+		}
+		return completeIdempotencyKey(tx, idempotency, order.ID)
+	})
+}
+
+func assignQueueNumbers(tx *gorm.DB, order *model.Order) error {
+	number, err := nextQueueNumber(tx, order.BusinessDate, model.QueueScopeOrder)
+	if err != nil {
+		return err
+	}
+	order.QueueNumber = number
+
+	for i := range order.Tickets {
+		number, err := nextQueueNumber(tx, order.BusinessDate, model.QueueScopeBooth(order.Tickets[i].BoothID))
+		if err != nil {
+			return err
+		}
+		order.Tickets[i].QueueNumber = number
+	}
+	return nil
+}
+
+// nextQueueNumber bumps the counter of the scope and day and returns the new value.
+// The upsert keeps the counter row locked until the transaction ends, so parallel
+// checkouts wait for each other instead of drawing the same number.
+func nextQueueNumber(tx *gorm.DB, day, scope string) (int, error) {
+	counter := model.QueueCounter{BusinessDate: day, Scope: scope, LastNumber: 1}
+	err := tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{"last_number": gorm.Expr("last_number + 1")}),
+	}).Create(&counter).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Where("business_date = ? AND scope = ?", day, scope).First(&counter).Error; err != nil {
+		return 0, err
+	}
+	return counter.LastNumber, nil
 }
 
 func (r *orderRepository) FindByCode(code string) (*model.Order, error) {
@@ -38,8 +109,22 @@ func (r *orderRepository) FindByCode(code string) (*model.Order, error) {
 	err := r.db.
 		Preload("Items").
 		Preload("Items.Menu").
+		Preload("Items.Options").
 		Preload("Items.Booth").
+		Preload("Redemptions").
+		Preload("Charges").
+		Preload("Tickets").
+		Preload("Tickets.Booth").
 		Preload("Logs").
+		Preload("Messages", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Messages.Booth").
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("StatusHistory.Admin").
+		Preload("StatusHistory.Booth").
 		Where("order_code = ?", code).
 		First(&order).Error
 
@@ -49,6 +134,20 @@ func (r *orderRepository) FindByCode(code string) (*model.Order, error) {
 	return &order, nil
 }
 
+func (r *orderRepository) FindByID(id uint) (*model.Order, error) {
+	var order model.Order
+	err := r.db.
+		Preload("Items").
+		Preload("Tickets").
+		Preload("Charges").
+		First(&order, id).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 func (r *orderRepository) UpdateInvoice(orderCode string, invoiceID string, invoiceURL string) error {
 
 	return r.db.Model(&model.Order{}).
@@ -59,6 +158,39 @@ func (r *orderRepository) UpdateInvoice(orderCode string, invoiceID string, invo
 		}).Error
 }
 
+func (r *orderRepository) SetPaymentFlag(orderID uint, flag string) error {
+	return r.db.Model(&model.Order{}).
+		Where("id = ?", orderID).
+		Update("payment_flag", flag).Error
+}
+
+// FindAwaitingPayment returns open orders with an invoice whose payment is still pending.
+func (r *orderRepository) FindAwaitingPayment(createdBefore time.Time, limit int) ([]model.Order, error) {
+	var orders []model.Order
+	err := r.db.
+		Preload("Tickets").
+		Where("payment_status = ? AND xendit_invoice_id <> '' AND order_status NOT IN ?",
+			model.PaymentStatusPending, []string{model.OrderStatusCompleted, model.OrderStatusCancelled}).
+		Where("created_at <= ?", createdBefore).
+		Order("id ASC").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+// FindUnpaidPending returns orders that nobody has acted on: still pending and unpaid.
+func (r *orderRepository) FindUnpaidPending(paymentMethod string, createdBefore time.Time, limit int) ([]model.Order, error) {
+	var orders []model.Order
+	err := r.db.
+		Preload("Tickets").
+		Where("payment_method = ? AND order_status = ? AND payment_status = ? AND created_at <= ?",
+			paymentMethod, model.OrderStatusPending, model.PaymentStatusPending, createdBefore).
+		Order("id ASC").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
 func (r *orderRepository) FindAll(page int, limit int, status string) ([]model.Order, int64, error) {
 	var orders []model.Order
 	var total int64
@@ -79,8 +211,21 @@ func (r *orderRepository) FindAll(page int, limit int, status string) ([]model.O
 	err := query.
 		Preload("Items").
 		Preload("Items.Menu").
+		Preload("Items.Options").
 		Preload("Items.Booth").
+		Preload("Charges").
+		Preload("Tickets").
+		Preload("Tickets.Booth").
 		Preload("Logs").
+		Preload("Messages", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Messages.Booth").
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("StatusHistory.Admin").
+		Preload("StatusHistory.Booth").
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
@@ -99,6 +244,46 @@ func (r *orderRepository) UpdateOrderStatus(orderCode string, status string) err
 		Update("order_status", status).Error
 }
 
+// ApplyStatusChange updates the order, the tickets it cascades to and the history
+// rows in one transaction, so an order never ends up final with open tickets. The
+// update only succeeds if the order still has the statuses it was read with.
+// Cancelling an order also returns its reserved stock.
+func (r *orderRepository) ApplyStatusChange(order *model.Order, updates map[string]interface{}, tickets []TicketChange, history []model.OrderStatusHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Order{}).
+			Where("id = ? AND order_status = ? AND payment_status = ?", order.ID, order.OrderStatus, order.PaymentStatus).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleOrderStatus
+		}
+
+		for _, change := range tickets {
+			if err := applyTicketChange(tx, change); err != nil {
+				return err
+			}
+		}
+
+		if updates["order_status"] == model.OrderStatusCancelled {
+			if err := releaseStock(tx, order.ID, nil); err != nil {
+				return err
+			}
+		}
+
+		for i := range history {
+			history[i].OrderID = order.ID
+		}
+		if len(history) > 0 {
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *orderRepository) GetTotalIncomeToday() (int, error) {
 	var total int
 
@@ -112,6 +297,56 @@ func (r *orderRepository) GetTotalIncomeToday() (int, error) {
 	return total, err
 }
 
+// BoothIncome is a booth's share of completed orders. Bundle components carry their
+// share of the bundle price and items their share of the voucher discount, so the
+// booth totals add up to the order totals.
+type BoothIncome struct {
+	BoothID   uint
+	BoothName string
+	Total     int
+}
+
+func (r *orderRepository) GetBoothIncomeToday() ([]BoothIncome, error) {
+	var incomes []BoothIncome
+
+	today := time.Now().Truncate(24 * time.Hour)
+
+	err := r.db.Table("order_items oi").
+		Select("oi.booth_id, b.name AS booth_name, COALESCE(SUM(oi.price_at_purchase * oi.quantity - oi.discount_amount), 0) AS total").
+		Joins("JOIN orders o ON o.id = oi.order_id").
+		Joins("JOIN booths b ON b.id = oi.booth_id").
+		Where("o.order_status = ? AND o.created_at >= ?", "completed", today).
+		Group("oi.booth_id, b.name").
+		Order("total DESC").
+		Scan(&incomes).Error
+
+	return incomes, err
+}
+
+// ChargeTotal is how much of one fee, such as PB1 tax, completed orders collected.
+// These amounts are not booth income and are kept out of BoothIncome.
+type ChargeTotal struct {
+	Code  string
+	Label string
+	Total int
+}
+
+func (r *orderRepository) GetChargesCollectedToday() ([]ChargeTotal, error) {
+	var totals []ChargeTotal
+
+	today := time.Now().Truncate(24 * time.Hour)
+
+	err := r.db.Table("order_charges oc").
+		Select("oc.code, MAX(oc.label) AS label, COALESCE(SUM(oc.amount), 0) AS total").
+		Joins("JOIN orders o ON o.id = oc.order_id").
+		Where("o.order_status = ? AND o.created_at >= ?", "completed", today).
+		Group("oc.code").
+		Order("oc.code").
+		Scan(&totals).Error
+
+	return totals, err
+}
+
 func (r *orderRepository) CountOrdersToday() (int64, error) {
 	var count int64
 	today := time.Now().Truncate(24 * time.Hour)
@@ -130,10 +365,60 @@ func (r *orderRepository) FindOrdersToday() ([]model.Order, error) {
 	err := r.db.
 		Preload("Items").
 		Preload("Items.Menu").
+		Preload("Items.Options").
 		Preload("Items.Booth").
+		Preload("Tickets").
 		Where("created_at >= ?", today).
 		Order("created_at DESC").
 		Find(&orders).Error
 
 	return orders, err
 }
+
+// FindOpenDineIn returns the dine-in orders that are still being served, oldest first.
+func (r *orderRepository) FindOpenDineIn() ([]model.Order, error) {
+	var orders []model.Order
+
+	err := r.db.
+		Preload("Items").
+		Preload("Items.Menu").
+		Where("table_id IS NOT NULL AND order_status NOT IN ?", []string{model.OrderStatusCompleted, model.OrderStatusCancelled}).
+		Order("created_at ASC").
+		Find(&orders).Error
+
+	return orders, err
+}
+
+// FindKitchenQueue returns the confirmed orders that still have an open ticket at the
+// booth, oldest first. Only the booth's own ticket and items are loaded.
+func (r *orderRepository) FindKitchenQueue(boothID uint) ([]model.Order, error) {
+	var orders []model.Order
+
+	err := r.db.
+		Preload("Items", "booth_id = ?", boothID).
+		Preload("Items.Menu").
+		Preload("Items.Options").
+		Preload("Tickets", "booth_id = ?", boothID).
+		Joins("JOIN booth_tickets bt ON bt.order_id = orders.id AND bt.booth_id = ?", boothID).
+		Where("orders.order_status IN ? AND bt.status IN ?",
+			[]string{model.OrderStatusConfirmed, model.OrderStatusPreparing, model.OrderStatusReady},
+			[]string{model.TicketStatusPending, model.TicketStatusPreparing, model.TicketStatusReady}).
+		Order("orders.created_at ASC").
+		Find(&orders).Error
+
+	return orders, err
+}
+
+// FindServing returns the orders of the business day being prepared or waiting for
+// pickup, in the order their status last changed.
+func (r *orderRepository) FindServing(businessDate string) ([]model.Order, error) {
+	var orders []model.Order
+
+	err := r.db.
+		Select("id", "order_code", "queue_number", "order_status", "created_at", "updated_at").
+		Where("order_status IN ? AND business_date = ?", []string{model.OrderStatusPreparing, model.OrderStatusReady}, businessDate).
+		Order("updated_at ASC").
+		Find(&orders).Error
+
+	return orders, err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentEventRepository interface {
+	Record(event *model.PaymentEvent) (bool, error)
+	FindLatestApplied(orderID uint) (*model.PaymentEvent, error)
+	FindByOrderID(orderID uint) ([]model.PaymentEvent, error)
+	FindRecentBySource(sources []string, limit int) ([]model.PaymentEvent, error)
+	SetOutcome(id uint, orderID *uint, outcome string, note string) error
+}
+
+type paymentEventRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentEventRepository(db *gorm.DB) PaymentEventRepository {
+	return &paymentEventRepository{db: db}
+}
+
+// Record inserts the event. When its dedupe key already exists it returns false and
+// loads the stored row into event instead.
+func (r *paymentEventRepository) Record(event *model.PaymentEvent) (bool, error) {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+
+	return false, r.db.Where("dedupe_key = ?", event.DedupeKey).First(event).Error
+}
+
+func (r *paymentEventRepository) FindLatestApplied(orderID uint) (*model.PaymentEvent, error) {
+	var event model.PaymentEvent
+	err := r.db.
+		Where("order_id = ? AND outcome = ?", orderID, model.PaymentEventApplied).
+		Order("id DESC").
+		First(&event).Error
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *paymentEventRepository) FindByOrderID(orderID uint) ([]model.PaymentEvent, error) {
+	var events []model.PaymentEvent
+	err := r.db.Where("order_id = ?", orderID).Order("id ASC").Find(&events).Error
+	return events, err
+}
+
+func (r *paymentEventRepository) FindRecentBySource(sources []string, limit int) ([]model.PaymentEvent, error) {
+	var events []model.PaymentEvent
+	err := r.db.
+		Preload("Order").
+		Where("source IN ?", sources).
+		Order("id DESC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *paymentEventRepository) SetOutcome(id uint, orderID *uint, outcome string, note string) error {
+	note = utils.Truncate(note, 255)
+	return r.db.Model(&model.PaymentEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"order_id": orderID,
+			"outcome":  outcome,
+			"note":     note,
+		}).Error
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"gorm.io/gorm"
+)
+
+type PaymentOutboxRepository interface {
+	FindDue(now time.Time, limit int) ([]model.PaymentOutbox, error)
+	FindPendingByOrderID(orderID uint) (*model.PaymentOutbox, error)
+	Claim(id uint, now time.Time, lockFor time.Duration) (bool, error)
+	MarkDone(id uint) error
+	MarkRetry(id uint, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(id uint, lastError string) error
+}
+
+type paymentOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentOutboxRepository(db *gorm.DB) PaymentOutboxRepository {
+	return &paymentOutboxRepository{db: db}
+}
+
+// FindDue returns records that are waiting for their next attempt or whose
+// worker lock has expired.
+func (r *paymentOutboxRepository) FindDue(now time.Time, limit int) ([]model.PaymentOutbox, error) {
+	var records []model.PaymentOutbox
+	err := r.db.
+		Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+			model.OutboxStatusPending, now, model.OutboxStatusProcessing, now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&records).Error
+	return records, err
+}
+
+func (r *paymentOutboxRepository) FindPendingByOrderID(orderID uint) (*model.PaymentOutbox, error) {
+	var record model.PaymentOutbox
+	err := r.db.
+		Where("order_id = ? AND status IN ?", orderID, []string{model.OutboxStatusPending, model.OutboxStatusProcessing}).
+		Order("id ASC").
+		First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Claim marks the record as processing for lockFor. It returns false when another
+// worker got there first.
+func (r *paymentOutboxRepository) Claim(id uint, now time.Time, lockFor time.Duration) (bool, error) {
+	lockedUntil := now.Add(lockFor)
+	res := r.db.Model(&model.PaymentOutbox{}).
+		Where("id = ? AND ((status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?))",
+			id, model.OutboxStatusPending, now, model.OutboxStatusProcessing, now).
+		Updates(map[string]interface{}{
+			"status":       model.OutboxStatusProcessing,
+			"locked_until": lockedUntil,
+			"attempts":     gorm.Expr("attempts + 1"),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *paymentOutboxRepository) MarkDone(id uint) error {
+	return r.db.Model(&model.PaymentOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       model.OutboxStatusDone,
+			"locked_until": nil,
+			"last_error":   "",
+		}).Error
+}
+
+func (r *paymentOutboxRepository) MarkRetry(id uint, nextAttemptAt time.Time, lastError string) error {
+	return r.db.Model(&model.PaymentOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          model.OutboxStatusPending,
+			"next_attempt_at": nextAttemptAt,
+			"locked_until":    nil,
+			"last_error":      lastError,
+		}).Error
+}
+
+func (r *paymentOutboxRepository) MarkFailed(id uint, lastError string) error {
+	return r.db.Model(&model.PaymentOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       model.OutboxStatusFailed,
+			"locked_until": nil,
+			"last_error":   lastError,
+		}).Error
+}
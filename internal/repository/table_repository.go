@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"gorm.io/gorm"
+)
+
+type TableRepository interface {
+	FindAll() ([]model.Table, error)
+	FindActive() ([]model.Table, error)
+	FindByID(id uint) (*model.Table, error)
+	FindByCode(code string) (*model.Table, error)
+	Create(table *model.Table) error
+	Update(table *model.Table) error
+	Delete(id uint) error
+}
+
+type tableRepository struct {
+	db *gorm.DB
+}
+
+func NewTableRepository(db *gorm.DB) TableRepository {
+	return &tableRepository{db: db}
+}
+
+func (r *tableRepository) FindAll() ([]model.Table, error) {
+	var tables []model.Table
+	err := r.db.Order("zone ASC, code ASC").Find(&tables).Error
+	return tables, err
+}
+
+func (r *tableRepository) FindActive() ([]model.Table, error) {
+	var tables []model.Table
+	err := r.db.Where("is_active = ?", true).Order("zone ASC, code ASC").Find(&tables).Error
+	return tables, err
+}
+
+func (r *tableRepository) FindByID(id uint) (*model.Table, error) {
+	var table model.Table
+	if err := r.db.First(&table, id).Error; err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+func (r *tableRepository) FindByCode(code string) (*model.Table, error) {
+	var table model.Table
+	if err := r.db.Where("code = ?", model.NormalizeTableCode(code)).First(&table).Error; err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+func (r *tableRepository) Create(table *model.Table) error {
+	return r.db.Create(table).Error
+}
+
+func (r *tableRepository) Update(table *model.Table) error {
+	return r.db.Save(table).Error
+}
+
+// Delete removes a table that no order points at. Tables with history should be
+// deactivated instead.
+func (r *tableRepository) Delete(id uint) error {
+	var used int64
+	if err := r.db.Model(&model.Order{}).Where("table_id = ?", id).Count(&used).Error; err != nil {
+		return err
+	}
+	if used > 0 {
+		return errors.New("meja sudah pernah dipakai pesanan, nonaktifkan saja")
+	}
+	return r.db.Delete(&model.Table{}, id).Error
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"gorm.io/gorm"
+)
+
+// TicketChange moves one ticket from a status to the next.
+type TicketChange struct {
+	Ticket *model.BoothTicket
+	From   string
+	To     string
+}
+
+type TicketRepository interface {
+	FindByID(id uint) (*model.BoothTicket, error)
+	FindByOrderID(orderID uint) ([]model.BoothTicket, error)
+	CountOpenBefore(boothID uint, ticketID uint) (int64, error)
+	ApplyStatusChange(ticket *model.BoothTicket, status string, history []model.OrderStatusHistory) error
+}
+
+type ticketRepository struct {
+	db *gorm.DB
+}
+
+func NewTicketRepository(db *gorm.DB) TicketRepository {
+	return &ticketRepository{db: db}
+}
+
+func (r *ticketRepository) FindByID(id uint) (*model.BoothTicket, error) {
+	var ticket model.BoothTicket
+	err := r.db.Preload("Booth").First(&ticket, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (r *ticketRepository) FindByOrderID(orderID uint) ([]model.BoothTicket, error) {
+	var tickets []model.BoothTicket
+	err := r.db.Preload("Booth").Where("order_id = ?", orderID).Order("id ASC").Find(&tickets).Error
+	return tickets, err
+}
+
+// CountOpenBefore counts the booth's tickets that are queued or cooking ahead of the
+// given ticket. Tickets of orders still awaiting payment are not in the queue yet.
+func (r *ticketRepository) CountOpenBefore(boothID uint, ticketID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.BoothTicket{}).
+		Joins("JOIN orders ON orders.id = booth_tickets.order_id").
+		Where("booth_tickets.booth_id = ? AND booth_tickets.id < ?", boothID, ticketID).
+		Where("booth_tickets.status IN ?", []string{model.TicketStatusPending, model.TicketStatusPreparing}).
+		Where("orders.order_status IN ?", []string{model.OrderStatusConfirmed, model.OrderStatusPreparing, model.OrderStatusReady}).
+		Count(&count).Error
+	return count, err
+}
+
+// ApplyStatusChange updates the ticket and writes its history rows in one transaction.
+// The update only succeeds if the ticket still has the status it was read with.
+// Cancelling a ticket returns the stock of that booth's items.
+func (r *ticketRepository) ApplyStatusChange(ticket *model.BoothTicket, status string, history []model.OrderStatusHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := applyTicketChange(tx, TicketChange{Ticket: ticket, From: ticket.Status, To: status}); err != nil {
+			return err
+		}
+
+		if len(history) > 0 {
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func applyTicketChange(tx *gorm.DB, change TicketChange) error {
+	res := tx.Model(&model.BoothTicket{}).
+		Where("id = ? AND status = ?", change.Ticket.ID, change.From).
+		Update("status", change.To)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrStaleOrderStatus
+	}
+
+	if change.To == model.TicketStatusCancelled {
+		return releaseStock(tx, change.Ticket.OrderID, &change.Ticket.BoothID)
+	}
+	return nil
+}
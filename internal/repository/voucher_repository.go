@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherRepository interface {
+	FindAll() ([]model.Voucher, error)
+	FindByID(id uint) (*model.Voucher, error)
+	FindByCode(code string) (*model.Voucher, error)
+	Create(voucher *model.Voucher) error
+	Update(voucher *model.Voucher) error
+	Delete(id uint) error
+
+	CountRedemptions(voucherID uint, customerKey string) (int64, error)
+	UsageSummary() ([]VoucherUsage, error)
+	FindRecentRedemptions(limit int) ([]model.VoucherRedemption, error)
+}
+
+type voucherRepository struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) VoucherRepository {
+	return &voucherRepository{db: db}
+}
+
+func (r *voucherRepository) FindAll() ([]model.Voucher, error) {
+	var vouchers []model.Voucher
+	err := r.db.Preload("Booth").Order("id DESC").Find(&vouchers).Error
+	return vouchers, err
+}
+
+func (r *voucherRepository) FindByID(id uint) (*model.Voucher, error) {
+	var voucher model.Voucher
+	err := r.db.Preload("Booth").First(&voucher, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+func (r *voucherRepository) FindByCode(code string) (*model.Voucher, error) {
+	var voucher model.Voucher
+	err := r.db.Where("code = ?", code).First(&voucher).Error
+	if err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+func (r *voucherRepository) Create(voucher *model.Voucher) error {
+	return r.db.Create(voucher).Error
+}
+
+func (r *voucherRepository) Update(voucher *model.Voucher) error {
+	return r.db.Omit("Booth").Save(voucher).Error
+}
+
+// Delete removes a voucher that has never been redeemed. Used vouchers stay for the
+// redemption report and should be deactivated instead.
+func (r *voucherRepository) Delete(id uint) error {
+	var used int64
+	r.db.Model(&model.VoucherRedemption{}).Where("voucher_id = ?", id).Count(&used)
+	if used > 0 {
+		return errors.New("voucher sudah pernah dipakai, nonaktifkan saja")
+	}
+	return r.db.Delete(&model.Voucher{}, id).Error
+}
+
+// CountRedemptions counts the redemptions that still hold a use of the voucher,
+// i.e. those of orders that were not cancelled. An empty customerKey counts all customers.
+func (r *voucherRepository) CountRedemptions(voucherID uint, customerKey string) (int64, error) {
+	return countRedemptions(r.db, voucherID, customerKey)
+}
+
+func countRedemptions(db *gorm.DB, voucherID uint, customerKey string) (int64, error) {
+	query := db.Model(&model.VoucherRedemption{}).
+		Joins("JOIN orders o ON o.id = voucher_redemptions.order_id").
+		Where("voucher_redemptions.voucher_id = ? AND o.order_status <> ?", voucherID, model.OrderStatusCancelled)
+	if customerKey != "" {
+		query = query.Where("voucher_redemptions.customer_key = ?", customerKey)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// VoucherUsage sums the redemptions of one voucher over orders that were not cancelled.
+type VoucherUsage struct {
+	VoucherID     uint
+	Code          string
+	Uses          int
+	TotalDiscount int
+	LastUsedAt    *time.Time
+}
+
+func (r *voucherRepository) UsageSummary() ([]VoucherUsage, error) {
+	var usage []VoucherUsage
+	err := r.db.Table("vouchers v").
+		Select(`v.id AS voucher_id, v.code,
+			COUNT(o.id) AS uses,
+			COALESCE(SUM(CASE WHEN o.id IS NULL THEN 0 ELSE vr.amount END), 0) AS total_discount,
+			MAX(CASE WHEN o.id IS NULL THEN NULL ELSE vr.created_at END) AS last_used_at`).
+		Joins("LEFT JOIN voucher_redemptions vr ON vr.voucher_id = v.id").
+		Joins("LEFT JOIN orders o ON o.id = vr.order_id AND o.order_status <> ?", model.OrderStatusCancelled).
+		Group("v.id, v.code").
+		Order("uses DESC, v.id DESC").
+		Scan(&usage).Error
+	return usage, err
+}
+
+func (r *voucherRepository) FindRecentRedemptions(limit int) ([]model.VoucherRedemption, error) {
+	var redemptions []model.VoucherRedemption
+	err := r.db.
+		Preload("Order").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&redemptions).Error
+	return redemptions, err
+}
+
+// reserveVouchers locks the redeemed vouchers and checks their usage limits again,
+// so two orders racing for the last use cannot both get it. It must run inside the
+// transaction that creates the order.
+func reserveVouchers(tx *gorm.DB, redemptions []model.VoucherRedemption) error {
+	for _, redemption := range redemptions {
+		var voucher model.Voucher
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&voucher, redemption.VoucherID).Error
+		if err != nil {
+			return err
+		}
+
+		if voucher.UsageLimit > 0 {
+			used, err := countRedemptions(tx, voucher.ID, "")
+			if err != nil {
+				return err
+			}
+			if used >= int64(voucher.UsageLimit) {
+				return fmt.Errorf("kuota voucher %s sudah habis", voucher.Code)
+			}
+		}
+		if voucher.PerCustomerLimit > 0 {
+			used, err := countRedemptions(tx, voucher.ID, redemption.CustomerKey)
+			if err != nil {
+				return err
+			}
+			if used >= int64(voucher.PerCustomerLimit) {
+				return fmt.Errorf("voucher %s sudah mencapai batas pemakaian Anda", voucher.Code)
+			}
+		}
+	}
+	return nil
+}
@@ -2,10 +2,14 @@ package usecase
 
 import (
 	"errors"
+	"net/mail"
+	"net/url"
+	"strings"
 
 	"github.com/Rakhulsr/foodcourt/internal/dto"
 	"github.com/Rakhulsr/foodcourt/internal/model"
 	"github.com/Rakhulsr/foodcourt/internal/repository"
+	"github.com/Rakhulsr/foodcourt/utils"
 )
 
 type BoothUseCase interface {
@@ -15,6 +19,8 @@ type BoothUseCase interface {
 	Create(req dto.BoothCreateRequest) (*dto.BoothResponse, error)
 	Update(id uint, req dto.BoothUpdateRequest) (*dto.BoothResponse, error)
 	Delete(id uint) error
+	// RotateKitchenToken replaces the booth's kitchen display link, locking out the old one.
+	RotateKitchenToken(id uint) error
 }
 
 type boothUseCase struct {
@@ -58,6 +64,10 @@ func (u *boothUseCase) ListAll() (dto.BoothListResponse, error) {
 			IsActive:  b.IsActive,
 			CreatedAt: b.CreatedAt,
 			UpdatedAt: b.UpdatedAt,
+
+			KitchenToken:  b.KitchenToken,
+			NotifyChannel: b.NotifyChannel,
+			NotifyTarget:  b.NotifyTarget,
 		})
 	}
 
@@ -76,6 +86,9 @@ func (u *boothUseCase) GetByID(id uint) (*dto.BoothResponse, error) {
 		Name:     booth.Name,
 		WhatsApp: booth.WhatsApp,
 		IsActive: booth.IsActive,
+
+		NotifyChannel: booth.NotifyChannel,
+		NotifyTarget:  booth.NotifyTarget,
 	}, nil
 }
 
@@ -90,6 +103,13 @@ func (u *boothUseCase) Create(req dto.BoothCreateRequest) (*dto.BoothResponse, e
 		Name:     req.Name,
 		WhatsApp: req.WhatsApp,
 		IsActive: req.IsActive,
+
+		KitchenToken:  utils.SecureToken(24),
+		NotifyChannel: req.NotifyChannel,
+		NotifyTarget:  strings.TrimSpace(req.NotifyTarget),
+	}
+	if err := validateNotifyChannel(booth); err != nil {
+		return nil, err
 	}
 
 	if err := u.repo.Create(booth); err != nil {
@@ -103,6 +123,9 @@ func (u *boothUseCase) Create(req dto.BoothCreateRequest) (*dto.BoothResponse, e
 		IsActive:  booth.IsActive,
 		CreatedAt: booth.CreatedAt,
 		UpdatedAt: booth.UpdatedAt,
+
+		NotifyChannel: booth.NotifyChannel,
+		NotifyTarget:  booth.NotifyTarget,
 	}, nil
 }
 
@@ -119,6 +142,13 @@ func (u *boothUseCase) Update(id uint, req dto.BoothUpdateRequest) (*dto.BoothRe
 		booth.WhatsApp = req.WhatsApp
 	}
 	booth.IsActive = req.IsActive
+	if req.NotifyChannel != "" {
+		booth.NotifyChannel = req.NotifyChannel
+		booth.NotifyTarget = strings.TrimSpace(req.NotifyTarget)
+	}
+	if err := validateNotifyChannel(booth); err != nil {
+		return nil, err
+	}
 
 	if err := u.repo.Update(booth); err != nil {
 		return nil, err
@@ -131,9 +161,49 @@ func (u *boothUseCase) Update(id uint, req dto.BoothUpdateRequest) (*dto.BoothRe
 		IsActive:  booth.IsActive,
 		CreatedAt: booth.CreatedAt,
 		UpdatedAt: booth.UpdatedAt,
+
+		NotifyChannel: booth.NotifyChannel,
+		NotifyTarget:  booth.NotifyTarget,
 	}, nil
 }
 
 func (u *boothUseCase) Delete(id uint) error {
 	return u.repo.Delete(id)
 }
+
+func (u *boothUseCase) RotateKitchenToken(id uint) error {
+	booth, err := u.repo.FindByID(id)
+	if err != nil {
+		return errors.New("booth tidak ditemukan")
+	}
+	booth.KitchenToken = utils.SecureToken(24)
+	return u.repo.Update(booth)
+}
+
+// validateNotifyChannel defaults the booth to WhatsApp and checks that the chosen
+// channel has somewhere to send to.
+func validateNotifyChannel(booth *model.Booth) error {
+	if booth.NotifyChannel == "" {
+		booth.NotifyChannel = model.NotifyChannelWhatsApp
+	}
+
+	switch booth.NotifyChannel {
+	case model.NotifyChannelWhatsApp:
+		if booth.WhatsApp == "" {
+			return errors.New("nomor WhatsApp wajib diisi untuk notifikasi WhatsApp")
+		}
+	case model.NotifyChannelWebhook:
+		target, err := url.Parse(booth.NotifyTarget)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return errors.New("URL webhook tidak valid")
+		}
+	case model.NotifyChannelEmail:
+		if _, err := mail.ParseAddress(booth.NotifyTarget); err != nil {
+			return errors.New("alamat email tidak valid")
+		}
+	case model.NotifyChannelLog:
+	default:
+		return errors.New("channel notifikasi tidak dikenal")
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+type BundleUseCase interface {
+	ListAll() ([]dto.BundleResponse, error)
+	ListOrderable() ([]dto.BundleResponse, error)
+	GetByID(id uint) (*dto.BundleResponse, error)
+	Create(req dto.BundleCreateRequest) error
+	ToggleActive(id uint) error
+	Delete(id uint) error
+}
+
+type bundleUseCase struct {
+	repo     repository.BundleRepository
+	menuRepo repository.MenuRepository
+}
+
+func NewBundleUseCase(repo repository.BundleRepository, mRepo repository.MenuRepository) *bundleUseCase {
+	return &bundleUseCase{repo: repo, menuRepo: mRepo}
+}
+
+func (u *bundleUseCase) ListAll() ([]dto.BundleResponse, error) {
+	bundles, err := u.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.BundleResponse, 0, len(bundles))
+	for i := range bundles {
+		resp = append(resp, toBundleResponse(&bundles[i]))
+	}
+	return resp, nil
+}
+
+// ListOrderable returns the active bundles whose components can all be sold right now.
+func (u *bundleUseCase) ListOrderable() ([]dto.BundleResponse, error) {
+	bundles, err := u.repo.FindActive()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []dto.BundleResponse
+	for i := range bundles {
+		if bundles[i].IsOrderable() {
+			resp = append(resp, toBundleResponse(&bundles[i]))
+		}
+	}
+	return resp, nil
+}
+
+func (u *bundleUseCase) GetByID(id uint) (*dto.BundleResponse, error) {
+	bundle, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("paket tidak ditemukan")
+	}
+	resp := toBundleResponse(bundle)
+	return &resp, nil
+}
+
+func (u *bundleUseCase) Create(req dto.BundleCreateRequest) error {
+	bundle := model.Bundle{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		IsActive:    true,
+	}
+
+	seen := make(map[uint]bool)
+	for i, menuID := range req.MenuIDs {
+		if menuID == 0 {
+			continue
+		}
+		if seen[menuID] {
+			return errors.New("menu yang sama tidak boleh dipilih dua kali, ubah jumlahnya saja")
+		}
+		seen[menuID] = true
+
+		qty := 1
+		if i < len(req.Quantities) && req.Quantities[i] > 0 {
+			qty = req.Quantities[i]
+		}
+
+		menu, err := u.menuRepo.FindByID(menuID)
+		if err != nil {
+			return fmt.Errorf("menu ID %d tidak ditemukan", menuID)
+		}
+		// Bundle components are ordered without options, so menus that need a
+		// choice cannot be part of a bundle.
+		if _, _, err := resolveMenuOptions(menu, nil); err != nil {
+			return fmt.Errorf("menu '%s' memiliki opsi wajib dan tidak bisa dijadikan komponen paket", menu.Name)
+		}
+
+		bundle.Items = append(bundle.Items, model.BundleItem{MenuID: menuID, Quantity: qty, Menu: *menu})
+	}
+
+	if len(bundle.Items) < 2 {
+		return errors.New("paket minimal berisi 2 menu")
+	}
+	if normal := bundle.NormalPrice(); bundle.Price > normal {
+		return fmt.Errorf("harga paket tidak boleh melebihi harga normal (Rp %d)", normal)
+	}
+
+	return u.repo.Create(&bundle)
+}
+
+func (u *bundleUseCase) ToggleActive(id uint) error {
+	bundle, err := u.repo.FindByID(id)
+	if err != nil {
+		return errors.New("paket tidak ditemukan")
+	}
+	return u.repo.SetActive(id, !bundle.IsActive)
+}
+
+func (u *bundleUseCase) Delete(id uint) error {
+	return u.repo.Delete(id)
+}
+
+func toBundleResponse(b *model.Bundle) dto.BundleResponse {
+	resp := dto.BundleResponse{
+		ID:          b.ID,
+		Name:        b.Name,
+		Description: b.Description,
+		Price:       b.Price,
+		NormalPrice: b.NormalPrice(),
+		IsActive:    b.IsActive,
+		IsOrderable: b.IsOrderable(),
+	}
+	for _, item := range b.Items {
+		resp.Items = append(resp.Items, dto.BundleItemResponse{
+			MenuID:    item.MenuID,
+			MenuName:  item.Menu.Name,
+			BoothName: item.Menu.Booth.Name,
+			Quantity:  item.Quantity,
+		})
+	}
+	return resp
+}
+
+// splitBundlePrice divides the bundle price over its components in proportion to
+// their normal price.
+func splitBundlePrice(b *model.Bundle) []int {
+	weights := make([]int, len(b.Items))
+	for i, item := range b.Items {
+		weights[i] = item.Menu.Price * item.Quantity
+	}
+	return splitProportionally(b.Price, weights)
+}
+
+// bundleOrderItems explodes quantity bundles into per-booth order items priced at
+// each component's share of the bundle price. A share that does not divide evenly
+// over the component quantity is split over two rows one rupiah apart.
+func bundleOrderItems(b *model.Bundle, quantity int, notes string) ([]model.OrderItem, error) {
+	if !b.IsActive {
+		return nil, fmt.Errorf("paket '%s' tidak tersedia", b.Name)
+	}
+	if len(b.Items) == 0 {
+		return nil, fmt.Errorf("paket '%s' tidak memiliki menu", b.Name)
+	}
+
+	bundleID := b.ID
+	shares := splitBundlePrice(b)
+
+	var items []model.OrderItem
+	for i, component := range b.Items {
+		menu := component.Menu
+		if !menu.IsAvailable {
+			return nil, fmt.Errorf("menu '%s' dalam paket '%s' tidak tersedia", menu.Name, b.Name)
+		}
+		if !menu.Booth.IsActive {
+			return nil, fmt.Errorf("booth '%s' tutup", menu.Booth.Name)
+		}
+
+		unitPrice := shares[i] / component.Quantity
+		extra := shares[i] % component.Quantity
+
+		rows := []struct{ qty, price int }{
+			{extra, unitPrice + 1},
+			{component.Quantity - extra, unitPrice},
+		}
+		for _, row := range rows {
+			if row.qty == 0 {
+				continue
+			}
+			items = append(items, model.OrderItem{
+				MenuID:          menu.ID,
+				BoothID:         menu.BoothID,
+				Quantity:        row.qty * quantity,
+				PriceAtPurchase: row.price,
+				Notes:           notes,
+				BundleID:        &bundleID,
+				BundleName:      b.Name,
+			})
+		}
+	}
+	return items, nil
+}
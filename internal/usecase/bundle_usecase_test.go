@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+)
+
+func TestSplitBundlePrice(t *testing.T) {
+	item := func(price, quantity int) model.BundleItem {
+		return model.BundleItem{Quantity: quantity, Menu: model.Menu{Price: price}}
+	}
+
+	tests := []struct {
+		name  string
+		price int
+		items []model.BundleItem
+		want  []int
+	}{
+		{"single item", 12000, []model.BundleItem{item(15000, 1)}, []int{12000}},
+		{"by normal price", 25000, []model.BundleItem{item(15000, 1), item(5000, 2)}, []int{15000, 10000}},
+		{"discounted", 20000, []model.BundleItem{item(15000, 1), item(5000, 1)}, []int{15000, 5000}},
+		{"rounding to first", 10000, []model.BundleItem{item(5000, 1), item(5000, 1), item(5000, 1)}, []int{3334, 3333, 3333}},
+		{"free component", 8000, []model.BundleItem{item(10000, 1), item(0, 1)}, []int{8000, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitBundlePrice(&model.Bundle{Price: tt.price, Items: tt.items})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitBundlePrice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/config"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+)
+
+// Customer update events. Each one's text can be replaced with CUSTOMER_MSG_<EVENT>.
+const (
+	customerPaid       = "paid"
+	customerBoothReady = "booth_ready"
+	customerOrderReady = "order_ready"
+	customerCancelled  = "cancelled"
+	customerExpired    = "expired"
+)
+
+var customerTemplates = map[string]string{
+	customerPaid: "Halo {{.CustomerName}}, pembayaran pesanan *{{.OrderCode}}* sudah kami terima ✅\n" +
+		"{{if .QueueLabel}}No. Antrean: *{{.QueueLabel}}*\n{{end}}" +
+		"Pantau pesanan Anda di sini: {{.TrackingURL}}",
+	customerBoothReady: "🔔 Pesanan dari *{{.BoothName}}* untuk *{{.OrderCode}}* sudah siap diambil.\n" +
+		"Pantau sisa pesanan: {{.TrackingURL}}",
+	customerOrderReady: "🔔 Halo {{.CustomerName}}, pesanan *{{.OrderCode}}*{{if .QueueLabel}} (antrean *{{.QueueLabel}}*){{end}} sudah siap! " +
+		"Silakan ambil di booth. Selamat menikmati 🍽️",
+	customerCancelled: "Pesanan *{{.OrderCode}}* dibatalkan.{{if .Reason}}\nAlasan: {{.Reason}}{{end}}",
+	customerExpired:   "Pesanan *{{.OrderCode}}* dibatalkan karena belum dibayar sampai batas waktu.",
+}
+
+var customerSubjects = map[string]string{
+	customerPaid:       "Lunas",
+	customerBoothReady: "Booth siap",
+	customerOrderReady: "Siap diambil",
+	customerCancelled:  "Dibatalkan",
+	customerExpired:    "Kedaluwarsa",
+}
+
+type customerMessageData struct {
+	CustomerName string
+	OrderCode    string
+	QueueLabel   string
+	BoothName    string
+	TrackingURL  string
+	Reason       string
+}
+
+// notifyCustomerOfTransition queues the customer update an order or payment status
+// change calls for.
+func (u *orderUsecase) notifyCustomerOfTransition(order *model.Order, prevOrderStatus string, prevPaymentStatus string, reason string) {
+	if order.OrderStatus == model.OrderStatusCancelled && prevOrderStatus != model.OrderStatusCancelled {
+		if order.IsExpired() {
+			u.notifyCustomer(order, customerExpired, "", "")
+		} else {
+			u.notifyCustomer(order, customerCancelled, "", reason)
+		}
+		return
+	}
+
+	if order.PaymentStatus == model.PaymentStatusPaid && prevPaymentStatus != model.PaymentStatusPaid {
+		u.notifyCustomer(order, customerPaid, "", "")
+	}
+	if order.OrderStatus == model.OrderStatusReady && prevOrderStatus != model.OrderStatusReady {
+		u.notifyCustomer(order, customerOrderReady, "", "")
+	}
+}
+
+// notifyCustomerOfTicket tells the customer one booth is ready while others are
+// still working. The last booth is covered by the order becoming ready.
+func (u *orderUsecase) notifyCustomerOfTicket(order *model.Order, ticket *model.BoothTicket) {
+	if ticket.Status != model.TicketStatusReady {
+		return
+	}
+	if order.OrderStatus == model.OrderStatusReady || order.OrderStatus == model.OrderStatusCompleted {
+		return
+	}
+
+	active, waiting := 0, 0
+	for _, t := range order.Tickets {
+		switch t.Status {
+		case model.TicketStatusCancelled:
+			continue
+		case model.TicketStatusPending, model.TicketStatusPreparing:
+			waiting++
+		}
+		active++
+	}
+	if active < 2 || waiting == 0 {
+		return
+	}
+
+	u.notifyCustomer(order, customerBoothReady, ticket.Booth.Name, "")
+}
+
+// notifyCustomer queues one WhatsApp update for a customer who left their number.
+// During quiet hours it is held until they end, except pickup calls, which would be
+// stale by then and are kept only as a cancelled record. Failures are logged and
+// never block the status change.
+func (u *orderUsecase) notifyCustomer(order *model.Order, event string, boothName string, reason string) {
+	if order.CustomerPhone == "" || !config.WhatsAppEnabled() {
+		return
+	}
+
+	body, err := renderCustomerMessage(event, customerMessageData{
+		CustomerName: order.CustomerName,
+		OrderCode:    order.OrderCode,
+		QueueLabel:   order.QueueLabel(),
+		BoothName:    boothName,
+		TrackingURL:  appBaseURL() + order.TrackingPath(),
+		Reason:       reason,
+	})
+	if err != nil {
+		fmt.Printf("⚠️ Pesan pelanggan %s untuk %s gagal dibuat: %v\n", event, order.OrderCode, err)
+		return
+	}
+
+	message := model.OutboundMessage{
+		OrderID:   &order.ID,
+		Kind:      model.MessageKindCustomerUpdate,
+		Channel:   model.NotifyChannelWhatsApp,
+		Recipient: order.CustomerPhone,
+		Subject:   customerSubjects[event],
+		Body:      body,
+	}
+
+	if until, quiet := config.QuietUntil(time.Now()); quiet {
+		if event == customerBoothReady || event == customerOrderReady {
+			message.Status = model.MessageStatusCancelled
+			message.LastError = "Tidak dikirim: jam tenang"
+		} else {
+			message.NextAttemptAt = until
+		}
+	}
+
+	if _, err := u.messages.Enqueue([]model.OutboundMessage{message}); err != nil {
+		fmt.Printf("⚠️ Pesan pelanggan %s untuk %s gagal diantrekan: %v\n", event, order.OrderCode, err)
+	}
+}
+
+// renderCustomerMessage fills the event's template, preferring the one configured
+// in CUSTOMER_MSG_<EVENT> when it parses.
+func renderCustomerMessage(event string, data customerMessageData) (string, error) {
+	text := customerTemplates[event]
+	if custom := config.CustomerMessageTemplate(event); custom != "" {
+		if _, err := template.New(event).Parse(custom); err != nil {
+			fmt.Printf("⚠️ CUSTOMER_MSG_%s tidak valid, memakai teks bawaan: %v\n", event, err)
+		} else {
+			text = custom
+		}
+	}
+
+	tmpl, err := template.New(event).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/config"
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+// servingLabelLength is how many trailing characters of the order code the public
+// screen shows for orders without a queue number. The full code opens the order
+// page, so it is never put on a TV.
+const servingLabelLength = 4
+
+type DisplayUseCase interface {
+	// Board returns today's orders that are being prepared or ready for pickup.
+	Board() (*dto.ServingBoard, error)
+}
+
+type displayUseCase struct {
+	orderRepo repository.OrderRepository
+}
+
+func NewDisplayUseCase(or repository.OrderRepository) DisplayUseCase {
+	return &displayUseCase{orderRepo: or}
+}
+
+func (u *displayUseCase) Board() (*dto.ServingBoard, error) {
+	// Same day boundary as the queue numbers, so late orders stay on screen past midnight.
+	orders, err := u.orderRepo.FindServing(config.BusinessDay(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	board := &dto.ServingBoard{}
+	for _, order := range orders {
+		item := dto.ServingOrder{Label: servingLabel(order), UpdatedAt: order.UpdatedAt}
+		if order.OrderStatus == model.OrderStatusReady {
+			board.Ready = append(board.Ready, item)
+		} else {
+			board.Preparing = append(board.Preparing, item)
+		}
+	}
+	return board, nil
+}
+
+// servingLabel is what customers look for on the screen: the queue number, e.g.
+// "007", or else the end of the order code, e.g. "#K7QZ".
+func servingLabel(order model.Order) string {
+	if queue := order.QueueLabel(); queue != "" {
+		return queue
+	}
+	code := strings.TrimPrefix(order.OrderCode, "ORD-")
+	if len(code) > servingLabelLength {
+		code = code[len(code)-servingLabelLength:]
+	}
+	return "#" + strings.ToUpper(code)
+}
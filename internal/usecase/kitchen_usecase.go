@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+// kitchenActions maps the buttons on the kitchen display to ticket statuses.
+var kitchenActions = map[string]string{
+	"start": model.TicketStatusPreparing,
+	"ready": model.TicketStatusReady,
+	"bump":  model.TicketStatusCompleted,
+}
+
+type KitchenUseCase interface {
+	// Authenticate returns the active booth a kitchen display token belongs to.
+	Authenticate(token string) (*model.Booth, error)
+	Queue(booth model.Booth) (*dto.KitchenQueue, error)
+	Advance(booth model.Booth, ticketID uint, action string) error
+}
+
+type kitchenUseCase struct {
+	boothRepo repository.BoothRepository
+	orderRepo repository.OrderRepository
+	orderUC   OrderUsecase
+}
+
+func NewKitchenUseCase(br repository.BoothRepository, or repository.OrderRepository, ouc OrderUsecase) *kitchenUseCase {
+	return &kitchenUseCase{boothRepo: br, orderRepo: or, orderUC: ouc}
+}
+
+// Authenticate checks the token only. A closed booth keeps its kitchen screen to
+// finish the tickets it already has.
+func (u *kitchenUseCase) Authenticate(token string) (*model.Booth, error) {
+	if token == "" {
+		return nil, errors.New("token dapur wajib diisi")
+	}
+	booth, err := u.boothRepo.FindByKitchenToken(token)
+	if err != nil {
+		return nil, errors.New("token dapur tidak valid")
+	}
+	return booth, nil
+}
+
+func (u *kitchenUseCase) Queue(booth model.Booth) (*dto.KitchenQueue, error) {
+	orders, err := u.orderRepo.FindKitchenQueue(booth.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &dto.KitchenQueue{Booth: booth}
+	for _, order := range orders {
+		if len(order.Tickets) == 0 {
+			continue
+		}
+		ticket := dto.KitchenTicket{
+			TicketID:     order.Tickets[0].ID,
+			QueueLabel:   order.Tickets[0].QueueLabel(),
+			Status:       order.Tickets[0].Status,
+			OrderCode:    order.OrderCode,
+			CustomerName: order.CustomerName,
+			TableNumber:  order.TableNumber,
+			OrderType:    model.OrderTypeLabel(order.OrderType),
+			OrderedAt:    order.CreatedAt,
+			Items:        order.Items,
+		}
+
+		switch ticket.Status {
+		case model.TicketStatusPending:
+			queue.Pending = append(queue.Pending, ticket)
+		case model.TicketStatusPreparing:
+			queue.Preparing = append(queue.Preparing, ticket)
+		case model.TicketStatusReady:
+			queue.Ready = append(queue.Ready, ticket)
+		}
+	}
+	return queue, nil
+}
+
+// Advance applies a kitchen button to one of the booth's tickets. Tickets of other
+// booths are refused.
+func (u *kitchenUseCase) Advance(booth model.Booth, ticketID uint, action string) error {
+	status, ok := kitchenActions[action]
+	if !ok {
+		return fmt.Errorf("aksi %s tidak dikenal", action)
+	}
+
+	orders, err := u.orderRepo.FindKitchenQueue(booth.ID)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		for _, ticket := range order.Tickets {
+			if ticket.ID == ticketID {
+				actor := model.StatusActor{Type: model.ActorBooth}
+				return u.orderUC.UpdateTicketStatus(order.OrderCode, ticketID, status, actor, "Layar dapur "+booth.Name)
+			}
+		}
+	}
+	return errors.New("tiket tidak ditemukan di antrean booth ini")
+}
@@ -7,6 +7,11 @@ import (
 
 type LogUseCase interface {
 	RecordLog(orderID uint, boothID uint, targetPhone string) error
+	// RecordCommand logs a command received over WhatsApp. boothID is nil when the
+	// sender is not a registered booth.
+	RecordCommand(boothID *uint, orderID *uint, status string, detail string) error
+	// RecordCustomerUpdate logs the outcome of an order update sent to a customer.
+	RecordCustomerUpdate(orderID *uint, status string, detail string) error
 	GetLogs(page int, limit int) ([]model.WhatsAppLog, int64, error)
 }
 
@@ -29,6 +34,27 @@ func (u *logUseCase) RecordLog(orderID uint, boothID uint, targetPhone string) e
 	return u.repo.Create(log)
 }
 
+func (u *logUseCase) RecordCommand(boothID *uint, orderID *uint, status string, detail string) error {
+	log := &model.WhatsAppLog{
+		OrderID:     orderID,
+		BoothID:     boothID,
+		MessageType: "inbound_command",
+		Status:      status,
+		Response:    detail,
+	}
+	return u.repo.Create(log)
+}
+
+func (u *logUseCase) RecordCustomerUpdate(orderID *uint, status string, detail string) error {
+	log := &model.WhatsAppLog{
+		OrderID:     orderID,
+		MessageType: "customer_update",
+		Status:      status,
+		Response:    detail,
+	}
+	return u.repo.Create(log)
+}
+
 func (u *logUseCase) GetLogs(page int, limit int) ([]model.WhatsAppLog, int64, error) {
 	if page <= 0 {
 		page = 1
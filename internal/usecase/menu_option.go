@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+)
+
+// resolveMenuOptions checks the chosen option IDs against the menu's option groups
+// and returns a price snapshot of each chosen option plus their total price delta.
+func resolveMenuOptions(menu *model.Menu, optionIDs []uint) ([]model.OrderItemOption, int, error) {
+	chosen := make(map[uint]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if chosen[id] {
+			return nil, 0, fmt.Errorf("opsi menu '%s' dipilih lebih dari sekali", menu.Name)
+		}
+		chosen[id] = true
+	}
+
+	var snapshot []model.OrderItemOption
+	delta := 0
+	matched := 0
+
+	for _, group := range menu.OptionGroups {
+		count := 0
+		for _, opt := range group.Options {
+			if !chosen[opt.ID] {
+				continue
+			}
+			if !opt.IsAvailable {
+				return nil, 0, fmt.Errorf("opsi '%s' untuk '%s' sedang tidak tersedia", opt.Name, menu.Name)
+			}
+
+			count++
+			delta += opt.PriceDelta
+			snapshot = append(snapshot, model.OrderItemOption{
+				OptionID:   opt.ID,
+				GroupName:  group.Name,
+				OptionName: opt.Name,
+				PriceDelta: opt.PriceDelta,
+			})
+		}
+		matched += count
+
+		minSelect, maxSelect := group.SelectionBounds()
+		if count < minSelect {
+			return nil, 0, fmt.Errorf("pilih minimal %d opsi '%s' untuk '%s'", minSelect, group.Name, menu.Name)
+		}
+		if count > maxSelect {
+			return nil, 0, fmt.Errorf("pilih maksimal %d opsi '%s' untuk '%s'", maxSelect, group.Name, menu.Name)
+		}
+	}
+
+	if matched != len(chosen) {
+		return nil, 0, fmt.Errorf("opsi tidak valid untuk menu '%s'", menu.Name)
+	}
+
+	return snapshot, delta, nil
+}
+
+func (u *menuUseCase) ResolveSelection(menuID uint, optionIDs []uint) (*dto.MenuSelection, error) {
+	menu, err := u.repo.FindByID(menuID)
+	if err != nil {
+		return nil, errors.New("menu tidak ditemukan")
+	}
+
+	options, delta, err := resolveMenuOptions(menu, optionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := u.GetByID(menuID)
+	if err != nil {
+		return nil, err
+	}
+
+	selection := &dto.MenuSelection{Menu: resp, UnitPrice: menu.Price + delta}
+	for _, opt := range options {
+		selection.Options = append(selection.Options, dto.SelectedOption{
+			ID:         opt.OptionID,
+			GroupName:  opt.GroupName,
+			Name:       opt.OptionName,
+			PriceDelta: opt.PriceDelta,
+		})
+	}
+	return selection, nil
+}
+
+func (u *menuUseCase) AddOptionGroup(menuID uint, req dto.MenuOptionGroupRequest) error {
+	if _, err := u.repo.FindByID(menuID); err != nil {
+		return errors.New("menu tidak ditemukan")
+	}
+	if req.MaxSelect > 0 && req.MinSelect > req.MaxSelect {
+		return errors.New("minimal pilihan tidak boleh melebihi maksimal")
+	}
+
+	group := &model.MenuOptionGroup{
+		MenuID:      menuID,
+		Name:        req.Name,
+		Required:    req.Required,
+		MultiSelect: req.MultiSelect,
+		MinSelect:   req.MinSelect,
+		MaxSelect:   req.MaxSelect,
+		SortOrder:   req.SortOrder,
+	}
+	if !group.MultiSelect {
+		group.MaxSelect = 1
+		if group.MinSelect > 1 {
+			group.MinSelect = 1
+		}
+	}
+	return u.optionRepo.CreateGroup(group)
+}
+
+func (u *menuUseCase) DeleteOptionGroup(menuID uint, groupID uint) error {
+	if _, err := u.findGroup(menuID, groupID); err != nil {
+		return err
+	}
+	return u.optionRepo.DeleteGroup(groupID)
+}
+
+func (u *menuUseCase) AddOption(menuID uint, groupID uint, req dto.MenuOptionRequest) error {
+	if _, err := u.findGroup(menuID, groupID); err != nil {
+		return err
+	}
+
+	return u.optionRepo.CreateOption(&model.MenuOption{
+		GroupID:     groupID,
+		Name:        req.Name,
+		PriceDelta:  req.PriceDelta,
+		IsAvailable: true,
+		SortOrder:   req.SortOrder,
+	})
+}
+
+func (u *menuUseCase) ToggleOption(menuID uint, optionID uint) error {
+	option, err := u.findOption(menuID, optionID)
+	if err != nil {
+		return err
+	}
+
+	option.IsAvailable = !option.IsAvailable
+	return u.optionRepo.UpdateOption(option)
+}
+
+func (u *menuUseCase) DeleteOption(menuID uint, optionID uint) error {
+	if _, err := u.findOption(menuID, optionID); err != nil {
+		return err
+	}
+	return u.optionRepo.DeleteOption(optionID)
+}
+
+// findGroup loads a group and makes sure it belongs to the menu in the URL.
+func (u *menuUseCase) findGroup(menuID uint, groupID uint) (*model.MenuOptionGroup, error) {
+	group, err := u.optionRepo.FindGroupByID(groupID)
+	if err != nil || group.MenuID != menuID {
+		return nil, errors.New("grup opsi tidak ditemukan")
+	}
+	return group, nil
+}
+
+func (u *menuUseCase) findOption(menuID uint, optionID uint) (*model.MenuOption, error) {
+	option, err := u.optionRepo.FindOptionByID(optionID)
+	if err != nil {
+		return nil, errors.New("opsi tidak ditemukan")
+	}
+	if _, err := u.findGroup(menuID, option.GroupID); err != nil {
+		return nil, errors.New("opsi tidak ditemukan")
+	}
+	return option, nil
+}
+
+func toOptionGroupResponses(groups []model.MenuOptionGroup) []dto.MenuOptionGroupResponse {
+	var resp []dto.MenuOptionGroupResponse
+	for _, g := range groups {
+		minSelect, maxSelect := g.SelectionBounds()
+		group := dto.MenuOptionGroupResponse{
+			ID:          g.ID,
+			Name:        g.Name,
+			Required:    minSelect > 0,
+			MultiSelect: g.MultiSelect,
+			MinSelect:   minSelect,
+			MaxSelect:   maxSelect,
+		}
+		for _, o := range g.Options {
+			group.Options = append(group.Options, dto.MenuOptionResponse{
+				ID:          o.ID,
+				Name:        o.Name,
+				PriceDelta:  o.PriceDelta,
+				IsAvailable: o.IsAvailable,
+			})
+		}
+		resp = append(resp, group)
+	}
+	return resp
+}
@@ -20,15 +20,25 @@ type MenuUseCase interface {
 	Create(req dto.MenuCreateRequest, imagePath string) (*dto.MenuResponse, error)
 	Update(id uint, req dto.MenuUpdateRequest, imagePath string) (*dto.MenuResponse, error)
 	Delete(id uint) error
+
+	Restock(id uint, req dto.MenuStockRequest) error
+
+	ResolveSelection(menuID uint, optionIDs []uint) (*dto.MenuSelection, error)
+	AddOptionGroup(menuID uint, req dto.MenuOptionGroupRequest) error
+	DeleteOptionGroup(menuID uint, groupID uint) error
+	AddOption(menuID uint, groupID uint, req dto.MenuOptionRequest) error
+	ToggleOption(menuID uint, optionID uint) error
+	DeleteOption(menuID uint, optionID uint) error
 }
 
 type menuUseCase struct {
-	repo      repository.MenuRepository
-	boothRepo repository.BoothRepository
+	repo       repository.MenuRepository
+	boothRepo  repository.BoothRepository
+	optionRepo repository.MenuOptionRepository
 }
 
-func NewMenuUseCase(repo repository.MenuRepository, bRepo repository.BoothRepository) *menuUseCase {
-	return &menuUseCase{repo: repo, boothRepo: bRepo}
+func NewMenuUseCase(repo repository.MenuRepository, bRepo repository.BoothRepository, oRepo repository.MenuOptionRepository) *menuUseCase {
+	return &menuUseCase{repo: repo, boothRepo: bRepo, optionRepo: oRepo}
 }
 
 func (u *menuUseCase) ListActive() (*dto.MenuListResponse, error) {
@@ -44,6 +54,7 @@ func (u *menuUseCase) ListActive() (*dto.MenuListResponse, error) {
 			Name:        m.Name,
 			Price:       m.Price,
 			IsAvailable: m.IsAvailable,
+			Stock:       m.Stock,
 			ImagePath:   m.ImagePath,
 			Category:    m.Category,
 			Description: m.Description,
@@ -51,6 +62,7 @@ func (u *menuUseCase) ListActive() (*dto.MenuListResponse, error) {
 				ID   uint   `json:"id"`
 				Name string `json:"name"`
 			}{ID: m.Booth.ID, Name: m.Booth.Name},
+			OptionGroups: toOptionGroupResponses(m.OptionGroups),
 		})
 	}
 	return resp, nil
@@ -70,12 +82,14 @@ func (u *menuUseCase) ListAll() (*dto.MenuListResponse, error) {
 			Name:        m.Name,
 			Price:       m.Price,
 			IsAvailable: m.IsAvailable,
+			Stock:       m.Stock,
 			ImagePath:   m.ImagePath,
 			Description: m.Description,
 			Booth: struct {
 				ID   uint   `json:"id"`
 				Name string `json:"name"`
 			}{ID: m.Booth.ID, Name: m.Booth.Name},
+			OptionGroups: toOptionGroupResponses(m.OptionGroups),
 		})
 	}
 	return resp, nil
@@ -92,6 +106,7 @@ func (u *menuUseCase) GetByID(id uint) (*dto.MenuResponse, error) {
 		Name:        menu.Name,
 		Price:       menu.Price,
 		IsAvailable: menu.IsAvailable,
+		Stock:       menu.Stock,
 		Category:    menu.Category,
 		ImagePath:   menu.ImagePath,
 		Description: menu.Description,
@@ -99,6 +114,7 @@ func (u *menuUseCase) GetByID(id uint) (*dto.MenuResponse, error) {
 			ID   uint   `json:"id"`
 			Name string `json:"name"`
 		}{ID: menu.Booth.ID, Name: menu.Booth.Name},
+		OptionGroups: toOptionGroupResponses(menu.OptionGroups),
 	}, nil
 }
 
@@ -115,11 +131,13 @@ func (u *menuUseCase) ListActiveByBoothID(id uint) (*dto.MenuListResponse, error
 			Name:        m.Name,
 			Price:       m.Price,
 			IsAvailable: m.IsAvailable,
+			Stock:       m.Stock,
 			Description: m.Description,
 			Booth: struct {
 				ID   uint   `json:"id"`
 				Name string `json:"name"`
 			}{ID: m.Booth.ID, Name: m.Booth.Name},
+			OptionGroups: toOptionGroupResponses(m.OptionGroups),
 		})
 	}
 	return resp, nil
@@ -139,6 +157,7 @@ func (u *menuUseCase) FindByName(keyword string) (*dto.MenuListResponse, error)
 			Name:        m.Name,
 			Price:       m.Price,
 			IsAvailable: m.IsAvailable,
+			Stock:       m.Stock,
 			ImagePath:   m.ImagePath,
 			Category:    m.Category,
 			Description: m.Description,
@@ -146,6 +165,7 @@ func (u *menuUseCase) FindByName(keyword string) (*dto.MenuListResponse, error)
 				ID   uint   `json:"id"`
 				Name string `json:"name"`
 			}{ID: m.Booth.ID, Name: m.Booth.Name},
+			OptionGroups: toOptionGroupResponses(m.OptionGroups),
 		})
 	}
 	return resp, nil
@@ -164,11 +184,13 @@ func (u *menuUseCase) FindByCategory(category string) (*dto.MenuListResponse, er
 			Name:        m.Name,
 			Price:       m.Price,
 			IsAvailable: m.IsAvailable,
+			Stock:       m.Stock,
 			Description: m.Description,
 			Booth: struct {
 				ID   uint   `json:"id"`
 				Name string `json:"name"`
 			}{ID: m.Booth.ID, Name: m.Booth.Name},
+			OptionGroups: toOptionGroupResponses(m.OptionGroups),
 		})
 	}
 	return resp, nil
@@ -200,6 +222,7 @@ func (u *menuUseCase) Create(req dto.MenuCreateRequest, imgPath string) (*dto.Me
 		Name:        menu.Name,
 		Price:       menu.Price,
 		IsAvailable: menu.IsAvailable,
+		Stock:       menu.Stock,
 		Category:    menu.Category,
 		ImagePath:   menu.ImagePath,
 		Description: menu.Description,
@@ -241,6 +264,7 @@ func (u *menuUseCase) Update(id uint, req dto.MenuUpdateRequest, imagePath strin
 		Name:        menu.Name,
 		Price:       menu.Price,
 		IsAvailable: menu.IsAvailable,
+		Stock:       menu.Stock,
 		Category:    menu.Category,
 		ImagePath:   menu.ImagePath,
 		Description: menu.Description,
@@ -248,9 +272,30 @@ func (u *menuUseCase) Update(id uint, req dto.MenuUpdateRequest, imagePath strin
 			ID   uint   `json:"id"`
 			Name string `json:"name"`
 		}{ID: menu.Booth.ID, Name: menu.Booth.Name},
+		OptionGroups: toOptionGroupResponses(menu.OptionGroups),
 	}, nil
 }
 
 func (u *menuUseCase) Delete(id uint) error {
 	return u.repo.Delete(id)
 }
+
+func (u *menuUseCase) Restock(id uint, req dto.MenuStockRequest) error {
+	if _, err := u.repo.FindByID(id); err != nil {
+		return errors.New("menu tidak ditemukan")
+	}
+
+	switch req.Action {
+	case "add":
+		if req.Quantity <= 0 {
+			return errors.New("jumlah stok harus lebih dari 0")
+		}
+		return u.repo.AddStock(id, req.Quantity)
+	case "set":
+		return u.repo.SetStock(id, &req.Quantity)
+	case "untrack":
+		return u.repo.SetStock(id, nil)
+	default:
+		return errors.New("aksi stok tidak dikenal")
+	}
+}
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+const (
+	messageMaxAttempts  = 8
+	messageLockDuration = 2 * time.Minute
+	messageSendTimeout  = 45 * time.Second
+	messageBatchSize    = 50
+	messageBaseBackoff  = 10 * time.Second
+	messageMaxBackoff   = 30 * time.Minute
+)
+
+// MessageQueue is the durable outbox for notifications. Enqueue only stores the
+// messages; workers deliver them through the Notifier with exponential backoff and
+// move them to the dead state once every attempt has failed.
+type MessageQueue interface {
+	Enqueue(messages []model.OutboundMessage) ([]model.OutboundMessage, error)
+	Run(ctx context.Context, workers int, interval time.Duration)
+
+	FindByIDs(ids []uint) ([]model.OutboundMessage, error)
+	List(page int, limit int, status string) ([]model.OutboundMessage, int64, error)
+	CountByStatus() (map[string]int64, error)
+	Requeue(id uint) error
+	Cancel(id uint) error
+}
+
+type messageQueue struct {
+	repo     repository.MessageRepository
+	notifier Notifier
+	logUC    LogUseCase
+	// wake lets Enqueue start a delivery round without waiting for the next tick.
+	wake chan struct{}
+}
+
+func NewMessageQueue(repo repository.MessageRepository, notifier Notifier, log LogUseCase) MessageQueue {
+	return &messageQueue{repo: repo, notifier: notifier, logUC: log, wake: make(chan struct{}, 1)}
+}
+
+func (q *messageQueue) Enqueue(messages []model.OutboundMessage) ([]model.OutboundMessage, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	// A message may come in already cancelled, to keep a record of something that
+	// was not sent, or held until a later NextAttemptAt.
+	now := time.Now()
+	for i := range messages {
+		if messages[i].Status != model.MessageStatusCancelled {
+			messages[i].Status = model.MessageStatusQueued
+		}
+		if messages[i].NextAttemptAt.Before(now) {
+			messages[i].NextAttemptAt = now
+		}
+	}
+	if err := q.repo.Create(messages); err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		if message.Status == model.MessageStatusCancelled {
+			q.recordCustomerUpdate(message, "skipped", message.LastError)
+		}
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return messages, nil
+}
+
+// Run delivers due messages with the given number of workers, every interval or
+// as soon as something is enqueued, until ctx is cancelled. Messages still in
+// flight when the process stops are picked up again once their lock expires.
+func (q *messageQueue) Run(ctx context.Context, workers int, interval time.Duration) {
+	if workers < 1 {
+		workers = 1
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		messages, err := q.repo.FindDue(time.Now(), messageBatchSize)
+		if err != nil {
+			fmt.Printf("⚠️ Gagal membaca antrean pesan: %v\n", err)
+		}
+
+		// FindDue returns at most one message per recipient, so the round can run
+		// in parallel without breaking per-recipient order.
+		jobs := make(chan model.OutboundMessage)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for message := range jobs {
+					q.deliver(ctx, message)
+				}
+			}()
+		}
+		for _, message := range messages {
+			jobs <- message
+		}
+		close(jobs)
+		wg.Wait()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+// deliver claims one message and sends it, scheduling a retry with exponential
+// backoff on failure.
+func (q *messageQueue) deliver(ctx context.Context, message model.OutboundMessage) {
+	claimed, err := q.repo.Claim(message.ID, time.Now(), messageLockDuration)
+	if err != nil {
+		fmt.Printf("⚠️ Pesan #%d gagal diambil: %v\n", message.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	notification := Notification{
+		Channel: message.Channel,
+		To:      message.Recipient,
+		Subject: message.Subject,
+		Body:    message.Body,
+	}
+	if message.Order != nil {
+		notification.OrderCode = message.Order.OrderCode
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, messageSendTimeout)
+	err = q.notifier.Send(sendCtx, notification)
+	cancel()
+
+	if err == nil {
+		if markErr := q.repo.MarkSent(message.ID, time.Now()); markErr != nil {
+			fmt.Printf("⚠️ Status pesan #%d gagal disimpan: %v\n", message.ID, markErr)
+		}
+		q.recordCustomerUpdate(message, model.MessageStatusSent, "")
+		return
+	}
+
+	attempts := message.Attempts + 1
+	if attempts >= messageMaxAttempts {
+		fmt.Printf("❌ Pesan #%d ke %s gagal %d kali: %v\n", message.ID, message.Recipient, attempts, err)
+		if markErr := q.repo.MarkDead(message.ID, err.Error()); markErr != nil {
+			fmt.Printf("⚠️ Status pesan #%d gagal disimpan: %v\n", message.ID, markErr)
+		}
+		q.recordCustomerUpdate(message, model.MessageStatusDead, err.Error())
+		return
+	}
+
+	fmt.Printf("⚠️ Pesan #%d ke %s gagal (percobaan %d/%d): %v\n", message.ID, message.Recipient, attempts, messageMaxAttempts, err)
+	if markErr := q.repo.MarkRetry(message.ID, time.Now().Add(messageBackoff(attempts)), err.Error()); markErr != nil {
+		fmt.Printf("⚠️ Status pesan #%d gagal disimpan: %v\n", message.ID, markErr)
+	}
+}
+
+// recordCustomerUpdate adds the outcome of a customer update to the WhatsApp log,
+// next to the booth clicks and commands. Other kinds are only tracked here.
+func (q *messageQueue) recordCustomerUpdate(message model.OutboundMessage, status string, detail string) {
+	if message.Kind != model.MessageKindCustomerUpdate || q.logUC == nil {
+		return
+	}
+
+	response := message.Subject + " → +" + message.Recipient
+	if detail != "" {
+		response += ": " + detail
+	}
+	if err := q.logUC.RecordCustomerUpdate(message.OrderID, status, response); err != nil {
+		fmt.Printf("⚠️ Log pesan #%d gagal disimpan: %v\n", message.ID, err)
+	}
+}
+
+// messageBackoff doubles the wait after every failed attempt: 10s, 20s, 40s, ...
+// up to messageMaxBackoff.
+func messageBackoff(attempts int) time.Duration {
+	backoff := messageBaseBackoff << (attempts - 1)
+	if backoff > messageMaxBackoff || backoff <= 0 {
+		return messageMaxBackoff
+	}
+	return backoff
+}
+
+func (q *messageQueue) FindByIDs(ids []uint) ([]model.OutboundMessage, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return q.repo.FindByIDs(ids)
+}
+
+func (q *messageQueue) List(page int, limit int, status string) ([]model.OutboundMessage, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	return q.repo.FindAll(page, limit, status)
+}
+
+func (q *messageQueue) CountByStatus() (map[string]int64, error) {
+	return q.repo.CountByStatus()
+}
+
+func (q *messageQueue) Requeue(id uint) error {
+	ok, err := q.repo.Requeue(id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("hanya pesan yang gagal atau dibatalkan yang bisa dikirim ulang")
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *messageQueue) Cancel(id uint) error {
+	ok, err := q.repo.Cancel(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("pesan sudah diproses dan tidak bisa dibatalkan")
+	}
+	return nil
+}
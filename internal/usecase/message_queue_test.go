@@ -0,0 +1,430 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+// fakeMessageRepo keeps the outbox in memory with the same rules as the database
+// version: a message is due when it is queued and its attempt time has come or when
+// its worker lock has expired, and never while an older message to the same
+// recipient is still undelivered.
+type fakeMessageRepo struct {
+	repository.MessageRepository
+	mu       sync.Mutex
+	nextID   uint
+	messages map[uint]*model.OutboundMessage
+}
+
+func newFakeMessageRepo() *fakeMessageRepo {
+	return &fakeMessageRepo{messages: map[uint]*model.OutboundMessage{}}
+}
+
+func (r *fakeMessageRepo) Create(messages []model.OutboundMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range messages {
+		r.nextID++
+		messages[i].ID = r.nextID
+		stored := messages[i]
+		r.messages[stored.ID] = &stored
+	}
+	return nil
+}
+
+func (r *fakeMessageRepo) FindDue(now time.Time, limit int) ([]model.OutboundMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []model.OutboundMessage
+	for _, m := range r.messages {
+		if isDue(m, now) && !r.hasOlderPending(m) {
+			due = append(due, *m)
+		}
+	}
+	sort.Slice(due, func(a, b int) bool { return due[a].ID < due[b].ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func isDue(m *model.OutboundMessage, now time.Time) bool {
+	switch m.Status {
+	case model.MessageStatusQueued:
+		return !m.NextAttemptAt.After(now)
+	case model.MessageStatusSending:
+		return m.LockedUntil != nil && m.LockedUntil.Before(now)
+	}
+	return false
+}
+
+func (r *fakeMessageRepo) hasOlderPending(m *model.OutboundMessage) bool {
+	for _, older := range r.messages {
+		if older.ID < m.ID && older.Channel == m.Channel && older.Recipient == m.Recipient &&
+			(older.Status == model.MessageStatusQueued || older.Status == model.MessageStatusSending) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *fakeMessageRepo) Claim(id uint, now time.Time, lockFor time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.messages[id]
+	if m == nil || !isDue(m, now) {
+		return false, nil
+	}
+	lockedUntil := now.Add(lockFor)
+	m.Status = model.MessageStatusSending
+	m.LockedUntil = &lockedUntil
+	m.Attempts++
+	return true, nil
+}
+
+func (r *fakeMessageRepo) MarkSent(id uint, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[id].Status = model.MessageStatusSent
+	r.messages[id].SentAt = &sentAt
+	r.messages[id].LockedUntil = nil
+	return nil
+}
+
+func (r *fakeMessageRepo) MarkRetry(id uint, nextAttemptAt time.Time, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[id].Status = model.MessageStatusQueued
+	r.messages[id].NextAttemptAt = nextAttemptAt
+	r.messages[id].LockedUntil = nil
+	r.messages[id].LastError = lastError
+	return nil
+}
+
+func (r *fakeMessageRepo) MarkDead(id uint, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[id].Status = model.MessageStatusDead
+	r.messages[id].LockedUntil = nil
+	r.messages[id].LastError = lastError
+	return nil
+}
+
+func (r *fakeMessageRepo) get(id uint) model.OutboundMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.messages[id]
+}
+
+// elapse moves every attempt time and lock back by d, as if d had passed.
+func (r *fakeMessageRepo) elapse(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.messages {
+		m.NextAttemptAt = m.NextAttemptAt.Add(-d)
+		if m.LockedUntil != nil {
+			lockedUntil := m.LockedUntil.Add(-d)
+			m.LockedUntil = &lockedUntil
+		}
+	}
+}
+
+// deliverDue runs one delivery round like Run does and returns the bodies sent in it.
+func deliverDue(t *testing.T, q *messageQueue, notifier *FakeNotifier) []string {
+	t.Helper()
+	before := len(notifier.Sent())
+	due, err := q.repo.FindDue(time.Now(), messageBatchSize)
+	if err != nil {
+		t.Fatalf("FindDue: %v", err)
+	}
+	for _, message := range due {
+		q.deliver(context.Background(), message)
+	}
+
+	var bodies []string
+	for _, n := range notifier.Sent()[before:] {
+		bodies = append(bodies, n.Body)
+	}
+	return bodies
+}
+
+func TestMessageBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{8, 1280 * time.Second},
+		{9, messageMaxBackoff},
+		{100, messageMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := messageBackoff(tt.attempts); got != tt.want {
+			t.Errorf("messageBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestMessageQueueEnqueue(t *testing.T) {
+	repo := newFakeMessageRepo()
+	q := NewMessageQueue(repo, NewFakeNotifier(), nil)
+
+	later := time.Now().Add(time.Hour)
+	stored, err := q.Enqueue([]model.OutboundMessage{
+		{Recipient: "1", NextAttemptAt: time.Now().Add(-time.Hour)},
+		{Recipient: "2", NextAttemptAt: later},
+		{Recipient: "3", Status: model.MessageStatusCancelled},
+		{Recipient: "4", Status: model.MessageStatusDead},
+	})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	wantStatus := []string{model.MessageStatusQueued, model.MessageStatusQueued, model.MessageStatusCancelled, model.MessageStatusQueued}
+	for i, message := range stored {
+		if message.Status != wantStatus[i] {
+			t.Errorf("message %d status = %q, want %q", i, message.Status, wantStatus[i])
+		}
+	}
+	if stored[0].NextAttemptAt.Before(time.Now().Add(-time.Minute)) {
+		t.Errorf("past NextAttemptAt kept: %v", stored[0].NextAttemptAt)
+	}
+	if !stored[1].NextAttemptAt.Equal(later) {
+		t.Errorf("held message NextAttemptAt = %v, want %v", stored[1].NextAttemptAt, later)
+	}
+}
+
+func TestMessageQueueDeliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		sendErr      error
+		attempts     int
+		wantStatus   string
+		wantSent     int
+		wantAttempts int
+	}{
+		{name: "sent", wantStatus: model.MessageStatusSent, wantSent: 1, wantAttempts: 1},
+		{name: "retried", sendErr: errors.New("offline"), wantStatus: model.MessageStatusQueued, wantAttempts: 1},
+		{name: "retried before the last attempt", sendErr: errors.New("offline"), attempts: messageMaxAttempts - 2, wantStatus: model.MessageStatusQueued, wantAttempts: messageMaxAttempts - 1},
+		{name: "dead after last attempt", sendErr: errors.New("offline"), attempts: messageMaxAttempts - 1, wantStatus: model.MessageStatusDead, wantAttempts: messageMaxAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeMessageRepo()
+			notifier := NewFakeNotifier()
+			notifier.Err = tt.sendErr
+			q := NewMessageQueue(repo, notifier, nil).(*messageQueue)
+
+			stored, err := q.Enqueue([]model.OutboundMessage{{
+				Channel:   model.NotifyChannelWhatsApp,
+				Recipient: "6281234567890",
+				Subject:   "Siap diambil",
+				Body:      "Pesanan siap",
+				Order:     &model.Order{OrderCode: "ORD-TEST"},
+			}})
+			if err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			stored[0].Attempts = tt.attempts
+			repo.messages[stored[0].ID].Attempts = tt.attempts
+
+			start := time.Now()
+			q.deliver(context.Background(), stored[0])
+
+			got := repo.get(stored[0].ID)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+			}
+			if got.Attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got.Attempts, tt.wantAttempts)
+			}
+			if tt.wantStatus == model.MessageStatusQueued {
+				if wait := messageBackoff(tt.wantAttempts); got.NextAttemptAt.Before(start.Add(wait)) {
+					t.Errorf("retry scheduled at %v, want at least %v later", got.NextAttemptAt, wait)
+				}
+				if got.LastError != "offline" {
+					t.Errorf("last error = %q, want %q", got.LastError, "offline")
+				}
+			}
+
+			sent := notifier.Sent()
+			if len(sent) != tt.wantSent {
+				t.Fatalf("sent %d notifications, want %d", len(sent), tt.wantSent)
+			}
+			if tt.wantSent > 0 {
+				want := Notification{Channel: model.NotifyChannelWhatsApp, To: "6281234567890", Subject: "Siap diambil", Body: "Pesanan siap", OrderCode: "ORD-TEST"}
+				if sent[0] != want {
+					t.Errorf("notification = %+v, want %+v", sent[0], want)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageQueueKeepsRecipientOrder(t *testing.T) {
+	repo := newFakeMessageRepo()
+	notifier := NewFakeNotifier()
+	q := NewMessageQueue(repo, notifier, nil).(*messageQueue)
+
+	if _, err := q.Enqueue([]model.OutboundMessage{
+		{Channel: model.NotifyChannelWhatsApp, Recipient: "1", Body: "a1"},
+		{Channel: model.NotifyChannelWhatsApp, Recipient: "1", Body: "a2"},
+		{Channel: model.NotifyChannelWhatsApp, Recipient: "2", Body: "b1"},
+		{Channel: model.NotifyChannelEmail, Recipient: "1", Body: "email"},
+	}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	// The first round fails, so a2 has to wait behind a1's retry.
+	notifier.Err = errors.New("offline")
+	deliverDue(t, q, notifier)
+	notifier.Err = nil
+
+	rounds := []struct {
+		elapse time.Duration
+		want   []string
+	}{
+		{0, nil},
+		{messageBaseBackoff + time.Second, []string{"a1", "b1", "email"}},
+		{0, []string{"a2"}},
+		{0, nil},
+	}
+	for i, round := range rounds {
+		repo.elapse(round.elapse)
+		if got := deliverDue(t, q, notifier); !reflect.DeepEqual(got, round.want) {
+			t.Errorf("round %d sent %v, want %v", i+1, got, round.want)
+		}
+	}
+}
+
+func TestMessageQueueRecoversStaleClaims(t *testing.T) {
+	repo := newFakeMessageRepo()
+	notifier := NewFakeNotifier()
+	q := NewMessageQueue(repo, notifier, nil).(*messageQueue)
+
+	stored, err := q.Enqueue([]model.OutboundMessage{
+		{Channel: model.NotifyChannelWhatsApp, Recipient: "1", Body: "first"},
+		{Channel: model.NotifyChannelWhatsApp, Recipient: "1", Body: "second"},
+	})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	// A worker claims the first message and the process stops before sending it.
+	if claimed, _ := repo.Claim(stored[0].ID, time.Now(), messageLockDuration); !claimed {
+		t.Fatal("first message was not claimed")
+	}
+
+	if got := deliverDue(t, q, notifier); got != nil {
+		t.Errorf("sent %v while the claim is held, want nothing", got)
+	}
+
+	repo.elapse(messageLockDuration + time.Second)
+	if got := deliverDue(t, q, notifier); !reflect.DeepEqual(got, []string{"first"}) {
+		t.Errorf("after the lock expired sent %v, want [first]", got)
+	}
+	if got := repo.get(stored[0].ID); got.Status != model.MessageStatusSent || got.Attempts != 2 {
+		t.Errorf("first message = %s after %d attempts, want sent after 2", got.Status, got.Attempts)
+	}
+	if got := deliverDue(t, q, notifier); !reflect.DeepEqual(got, []string{"second"}) {
+		t.Errorf("next round sent %v, want [second]", got)
+	}
+}
+
+func TestMessageQueueRunDeliversEnqueued(t *testing.T) {
+	repo := newFakeMessageRepo()
+	notifier := NewFakeNotifier()
+	q := NewMessageQueue(repo, notifier, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx, 2, time.Hour)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if _, err := q.Enqueue([]model.OutboundMessage{
+		{Channel: model.NotifyChannelWhatsApp, Recipient: "1", Body: "a"},
+		{Channel: model.NotifyChannelWhatsApp, Recipient: "2", Body: "b"},
+	}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	// Enqueue wakes the workers, so this does not wait for the hourly tick.
+	deadline := time.Now().Add(5 * time.Second)
+	for len(notifier.Sent()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("sent %d of 2 notifications", len(notifier.Sent()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (l *fakeLog) RecordCustomerUpdate(orderID *uint, status string, detail string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.updates = append(l.updates, status)
+	return nil
+}
+
+func TestMessageQueueLogsCustomerUpdates(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		status   string
+		attempts int
+		sendErr  error
+		want     []string
+	}{
+		{name: "sent", kind: model.MessageKindCustomerUpdate, want: []string{model.MessageStatusSent}},
+		{name: "skipped in quiet hours", kind: model.MessageKindCustomerUpdate, status: model.MessageStatusCancelled, want: []string{"skipped"}},
+		{name: "retry is not logged", kind: model.MessageKindCustomerUpdate, sendErr: errors.New("offline")},
+		{name: "dead", kind: model.MessageKindCustomerUpdate, attempts: messageMaxAttempts - 1, sendErr: errors.New("offline"), want: []string{model.MessageStatusDead}},
+		{name: "booth message is not logged", kind: model.MessageKindBoothOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeMessageRepo()
+			notifier := NewFakeNotifier()
+			notifier.Err = tt.sendErr
+			log := &fakeLog{}
+			q := NewMessageQueue(repo, notifier, log).(*messageQueue)
+
+			stored, err := q.Enqueue([]model.OutboundMessage{{
+				Kind:      tt.kind,
+				Status:    tt.status,
+				Channel:   model.NotifyChannelWhatsApp,
+				Recipient: "6281234567890",
+				Subject:   "Pesanan siap",
+			}})
+			if err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			repo.messages[stored[0].ID].Attempts = tt.attempts
+			stored[0].Attempts = tt.attempts
+			q.deliver(context.Background(), stored[0])
+
+			if !reflect.DeepEqual(log.updates, tt.want) {
+				t.Errorf("customer log = %v, want %v", log.updates, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/google/uuid"
+)
+
+var ErrMockInvoiceNotFound = errors.New("invoice mock tidak ditemukan")
+
+type MockInvoice struct {
+	PaymentInvoice
+	Description string
+	SuccessURL  string
+	FailureURL  string
+	Created     time.Time
+	Updated     time.Time
+}
+
+// MockPaymentGateway keeps invoices in memory and serves its own hosted payment
+// page, so checkout works without Xendit credentials. Paying or expiring an
+// invoice posts a Xendit-shaped callback to the regular webhook endpoint.
+type MockPaymentGateway struct {
+	mu       sync.RWMutex
+	invoices map[string]*MockInvoice
+	client   *http.Client
+}
+
+func NewMockPaymentGateway() *MockPaymentGateway {
+	return &MockPaymentGateway{
+		invoices: make(map[string]*MockInvoice),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (g *MockPaymentGateway) Name() string {
+	return "mock"
+}
+
+func (g *MockPaymentGateway) CreateInvoice(ctx context.Context, req InvoiceRequest) (*PaymentInvoice, error) {
+	id := "mock-" + uuid.New().String()
+	now := time.Now()
+
+	inv := &MockInvoice{
+		PaymentInvoice: PaymentInvoice{
+			ID:         id,
+			ExternalID: req.ExternalID,
+			URL:        fmt.Sprintf("%s/mock-payment/%s", appBaseURL(), id),
+			Status:     InvoiceStatusPending,
+			Amount:     req.Amount,
+		},
+		Description: req.Description,
+		SuccessURL:  req.SuccessURL,
+		FailureURL:  req.FailureURL,
+		Created:     now,
+		Updated:     now,
+	}
+
+	g.mu.Lock()
+	g.invoices[id] = inv
+	g.mu.Unlock()
+
+	result := inv.PaymentInvoice
+	return &result, nil
+}
+
+func (g *MockPaymentGateway) FindInvoiceByExternalID(ctx context.Context, externalID string) (*PaymentInvoice, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var latest *MockInvoice
+	for _, inv := range g.invoices {
+		if inv.ExternalID == externalID && (latest == nil || inv.Created.After(latest.Created)) {
+			latest = inv
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+
+	result := latest.PaymentInvoice
+	return &result, nil
+}
+
+func (g *MockPaymentGateway) GetInvoice(ctx context.Context, invoiceID string) (*PaymentInvoice, error) {
+	inv, err := g.Detail(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	result := inv.PaymentInvoice
+	return &result, nil
+}
+
+func (g *MockPaymentGateway) ExpireInvoice(ctx context.Context, invoiceID string) (*PaymentInvoice, error) {
+	return g.settle(invoiceID, InvoiceStatusExpired)
+}
+
+// Detail returns a copy of the invoice together with the data the hosted page shows.
+func (g *MockPaymentGateway) Detail(invoiceID string) (*MockInvoice, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	inv, ok := g.invoices[invoiceID]
+	if !ok {
+		return nil, ErrMockInvoiceNotFound
+	}
+	result := *inv
+	return &result, nil
+}
+
+// Pay marks the invoice as paid and fires the PAID callback.
+func (g *MockPaymentGateway) Pay(invoiceID string) (*PaymentInvoice, error) {
+	return g.settle(invoiceID, InvoiceStatusPaid)
+}
+
+// Expire marks the invoice as expired and fires the EXPIRED callback.
+func (g *MockPaymentGateway) Expire(invoiceID string) (*PaymentInvoice, error) {
+	return g.settle(invoiceID, InvoiceStatusExpired)
+}
+
+func (g *MockPaymentGateway) settle(invoiceID, status string) (*PaymentInvoice, error) {
+	g.mu.Lock()
+	inv, ok := g.invoices[invoiceID]
+	if !ok {
+		g.mu.Unlock()
+		return nil, ErrMockInvoiceNotFound
+	}
+	if inv.Status != InvoiceStatusPending {
+		g.mu.Unlock()
+		return nil, fmt.Errorf("invoice sudah berstatus %s", inv.Status)
+	}
+
+	inv.Status = status
+	inv.Updated = time.Now()
+	snapshot := *inv
+	g.mu.Unlock()
+
+	go g.sendCallback(snapshot)
+
+	result := snapshot.PaymentInvoice
+	return &result, nil
+}
+
+func (g *MockPaymentGateway) sendCallback(inv MockInvoice) {
+	payload := dto.XenditCallbackRequest{
+		ID:          inv.ID,
+		ExternalID:  inv.ExternalID,
+		Status:      inv.Status,
+		Amount:      float64(inv.Amount),
+		Description: inv.Description,
+		Created:     inv.Created.Format(time.RFC3339),
+		Updated:     inv.Updated.Format(time.RFC3339),
+	}
+	if inv.IsPaid() {
+		payload.PaidAmount = float64(inv.Amount)
+	}
+
+	body, _ := json.Marshal(payload)
+	url := webhookBaseURL() + "/webhooks/xendit"
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		fmt.Printf("⚠️ Mock callback gagal dibuat: %v\n", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := os.Getenv("XENDIT_CALLBACK_TOKEN"); token != "" {
+		req.Header.Set("x-callback-token", token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		fmt.Printf("⚠️ Mock callback %s gagal dikirim: %v\n", inv.ExternalID, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	fmt.Printf("🧪 Mock callback %s (%s) -> %d\n", inv.ExternalID, inv.Status, resp.StatusCode)
+}
+
+func appBaseURL() string {
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+func webhookBaseURL() string {
+	if baseURL := os.Getenv("WEBHOOK_BASE_URL"); baseURL != "" {
+		return strings.TrimRight(baseURL, "/")
+	}
+	return appBaseURL() + "/api"
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Notification is a message to a booth or customer. To is whatever address the
+// channel uses: a phone number, a webhook URL or an email address.
+type Notification struct {
+	Channel   string
+	To        string
+	Subject   string
+	Body      string
+	OrderCode string
+}
+
+// Notifier delivers notifications over one channel, or routes them to the right one.
+type Notifier interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// NotifierRouter sends each notification through the notifier registered for its
+// channel, so callers never depend on a concrete channel.
+type NotifierRouter struct {
+	mu        sync.RWMutex
+	notifiers map[string]Notifier
+}
+
+func NewNotifierRouter() *NotifierRouter {
+	return &NotifierRouter{notifiers: make(map[string]Notifier)}
+}
+
+func (r *NotifierRouter) Register(channel string, notifier Notifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifiers[channel] = notifier
+}
+
+// Channels lists the channels that can currently be sent on.
+func (r *NotifierRouter) Channels() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	channels := make([]string, 0, len(r.notifiers))
+	for channel := range r.notifiers {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+func (r *NotifierRouter) Send(ctx context.Context, n Notification) error {
+	r.mu.RLock()
+	notifier, ok := r.notifiers[n.Channel]
+	r.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("channel notifikasi %s tidak aktif", n.Channel)
+	}
+	return notifier.Send(ctx, n)
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/config"
+)
+
+// EmailNotifier sends notifications as plain text mail over SMTP.
+type EmailNotifier struct {
+	cfg config.SMTPConfig
+}
+
+func NewEmailNotifier(cfg config.SMTPConfig) *EmailNotifier {
+	return &EmailNotifier{cfg: cfg}
+}
+
+func (e *EmailNotifier) Send(ctx context.Context, n Notification) error {
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)
+	}
+
+	headers := []string{
+		"From: " + e.cfg.From,
+		"To: " + n.To,
+		"Subject: " + mime.QEncoding.Encode("utf-8", n.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	msg := strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(n.Body, "\n", "\r\n")
+
+	// net/smtp takes no context, so the send runs aside and is abandoned on cancel.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(e.cfg.Host, e.cfg.Port), auth, e.cfg.From, []string{n.To}, []byte(msg))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("email gagal dikirim: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
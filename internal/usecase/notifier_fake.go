@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeNotifier keeps notifications in memory instead of sending them, for tests and
+// local runs. Set Err to make every send fail.
+type FakeNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+	Err  error
+}
+
+func NewFakeNotifier() *FakeNotifier {
+	return &FakeNotifier{}
+}
+
+func (f *FakeNotifier) Send(ctx context.Context, n Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	f.sent = append(f.sent, n)
+	return nil
+}
+
+// Sent returns a copy of the notifications sent so far.
+func (f *FakeNotifier) Sent() []Notification {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Notification(nil), f.sent...)
+}
+
+func (f *FakeNotifier) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = nil
+}
@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+)
+
+// LogNotifier only prints notifications. Booths on the log channel read their
+// orders from the kitchen display instead.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (LogNotifier) Send(ctx context.Context, n Notification) error {
+	fmt.Printf("📝 Notifikasi [%s] untuk %s (%s):\n%s\n", n.Channel, n.To, n.OrderCode, n.Body)
+	return nil
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+)
+
+func TestNotifierRouter(t *testing.T) {
+	whatsapp := NewFakeNotifier()
+	email := NewFakeNotifier()
+	email.Err = errors.New("smtp down")
+
+	router := NewNotifierRouter()
+	router.Register(model.NotifyChannelWhatsApp, whatsapp)
+	router.Register(model.NotifyChannelEmail, email)
+
+	if got, want := router.Channels(), []string{model.NotifyChannelEmail, model.NotifyChannelWhatsApp}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Channels() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name    string
+		channel string
+		wantErr bool
+	}{
+		{"registered channel", model.NotifyChannelWhatsApp, false},
+		{"channel error is returned", model.NotifyChannelEmail, true},
+		{"unregistered channel", model.NotifyChannelWebhook, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			whatsapp.Reset()
+			err := router.Send(context.Background(), Notification{Channel: tt.channel, To: "6281234567890", Body: "halo"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			wantSent := 0
+			if tt.channel == model.NotifyChannelWhatsApp {
+				wantSent = 1
+			}
+			if got := len(whatsapp.Sent()); got != wantSent {
+				t.Errorf("WhatsApp notifier got %d notifications, want %d", got, wantSent)
+			}
+		})
+	}
+}
+
+func TestFakeNotifier(t *testing.T) {
+	fake := NewFakeNotifier()
+	first := Notification{Channel: model.NotifyChannelLog, To: "a", Body: "1"}
+	second := Notification{Channel: model.NotifyChannelLog, To: "b", Body: "2"}
+
+	fake.Send(context.Background(), first)
+	fake.Send(context.Background(), second)
+	if got := fake.Sent(); !reflect.DeepEqual(got, []Notification{first, second}) {
+		t.Errorf("Sent() = %v, want both notifications in order", got)
+	}
+
+	// Sent returns a copy, so callers cannot change what was recorded.
+	fake.Sent()[0].Body = "changed"
+	if got := fake.Sent()[0].Body; got != "1" {
+		t.Errorf("recorded body = %q after changing the copy, want %q", got, "1")
+	}
+
+	fake.Err = errors.New("offline")
+	if err := fake.Send(context.Background(), first); err == nil {
+		t.Error("Send() with Err set succeeded")
+	}
+	if got := len(fake.Sent()); got != 2 {
+		t.Errorf("failed send was recorded, have %d notifications", got)
+	}
+
+	fake.Reset()
+	if got := fake.Sent(); len(got) != 0 {
+		t.Errorf("Sent() after Reset = %v, want none", got)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		status  int
+		wantErr bool
+	}{
+		{name: "signed", secret: "rahasia", status: http.StatusOK},
+		{name: "unsigned", status: http.StatusNoContent},
+		{name: "receiver error", status: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				header = r.Header.Clone()
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := NewWebhookNotifier(tt.secret).Send(context.Background(), Notification{
+				Channel:   model.NotifyChannelWebhook,
+				To:        server.URL,
+				Subject:   "Pesanan baru",
+				Body:      "2x Bakso",
+				OrderCode: "ORD-TEST",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			var payload webhookPayload
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("payload is not JSON: %v", err)
+			}
+			if payload.Subject != "Pesanan baru" || payload.Body != "2x Bakso" || payload.OrderCode != "ORD-TEST" {
+				t.Errorf("payload = %+v", payload)
+			}
+			if got := header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q", got)
+			}
+
+			signature := header.Get("X-Foodcourt-Signature")
+			if tt.secret == "" {
+				if signature != "" {
+					t.Errorf("unsigned webhook has signature %q", signature)
+				}
+				return
+			}
+			mac := hmac.New(sha256.New, []byte(tt.secret))
+			mac.Write(body)
+			if want := hex.EncodeToString(mac.Sum(nil)); signature != want {
+				t.Errorf("signature = %q, want %q", signature, want)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	err := NewWebhookNotifier("").Send(context.Background(), Notification{Channel: model.NotifyChannelWebhook, To: url})
+	if err == nil {
+		t.Error("Send() to a closed server succeeded")
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier posts notifications as JSON to the URL in Notification.To. With a
+// secret, the body's HMAC-SHA256 is sent in X-Foodcourt-Signature so the receiver
+// can check where it came from.
+type WebhookNotifier struct {
+	secret string
+	client *http.Client
+}
+
+func NewWebhookNotifier(secret string) *WebhookNotifier {
+	return &WebhookNotifier{secret: secret, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type webhookPayload struct {
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	OrderCode string    `json:"order_code,omitempty"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+func (w *WebhookNotifier) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(webhookPayload{Subject: n.Subject, Body: n.Body, OrderCode: n.OrderCode, SentAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.To, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(body)
+		req.Header.Set("X-Foodcourt-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook gagal dihubungi: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook membalas status %d", resp.StatusCode)
+	}
+	return nil
+}
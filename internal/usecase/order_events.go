@@ -0,0 +1,65 @@
+package usecase
+
+import "sync"
+
+// OrderEvent tells live screens that an order or one of its tickets changed. Screens
+// reload what they show instead of applying the event themselves.
+type OrderEvent struct {
+	OrderID   uint
+	OrderCode string
+	BoothIDs  []uint
+}
+
+// HasBooth reports whether the change concerns the given booth.
+func (e OrderEvent) HasBooth(boothID uint) bool {
+	for _, id := range e.BoothIDs {
+		if id == boothID {
+			return true
+		}
+	}
+	return false
+}
+
+// orderEventBuffer is how many events a slow subscriber may fall behind before
+// further events are dropped for it.
+const orderEventBuffer = 16
+
+// OrderEvents fans order changes out to the subscribed live screens of this process.
+type OrderEvents struct {
+	mu   sync.Mutex
+	subs map[chan OrderEvent]struct{}
+}
+
+func NewOrderEvents() *OrderEvents {
+	return &OrderEvents{subs: make(map[chan OrderEvent]struct{})}
+}
+
+// Subscribe returns a channel of order events and a function that ends the subscription.
+func (e *OrderEvents) Subscribe() (<-chan OrderEvent, func()) {
+	ch := make(chan OrderEvent, orderEventBuffer)
+
+	e.mu.Lock()
+	e.subs[ch] = struct{}{}
+	e.mu.Unlock()
+
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if _, ok := e.subs[ch]; ok {
+			delete(e.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish sends the event to every subscriber without waiting on any of them.
+func (e *OrderEvents) Publish(event OrderEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
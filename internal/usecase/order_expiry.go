@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/config"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+const expiryBatchSize = 50
+
+var expiringPaymentMethods = []string{"cash", "qris"}
+
+// RunOrderExpiry cancels orders that stayed pending and unpaid longer than their
+// payment method allows, every interval until ctx is cancelled.
+func (u *orderUsecase) RunOrderExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, method := range expiringPaymentMethods {
+			u.expireStaleOrders(ctx, method)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *orderUsecase) expireStaleOrders(ctx context.Context, paymentMethod string) {
+	ttl := config.OrderExpiry(paymentMethod)
+	if ttl <= 0 {
+		return
+	}
+
+	orders, err := u.orderRepo.FindUnpaidPending(paymentMethod, time.Now().Add(-ttl), expiryBatchSize)
+	if err != nil {
+		fmt.Printf("⚠️ Gagal membaca order kedaluwarsa: %v\n", err)
+		return
+	}
+
+	for i := range orders {
+		if err := u.expireOrder(ctx, &orders[i], ttl); err != nil {
+			fmt.Printf("⚠️ Gagal mengakhiri order %s: %v\n", orders[i].OrderCode, err)
+		}
+	}
+}
+
+// expireOrder cancels one abandoned order. A QRIS invoice is checked first so a
+// payment whose webhook was lost is applied instead of cancelled, and is expired at
+// the provider afterwards so it can no longer be paid.
+func (u *orderUsecase) expireOrder(ctx context.Context, order *model.Order, ttl time.Duration) error {
+	if order.PaymentMethod == "qris" && order.XenditInvoiceID != "" {
+		event, err := u.reconcileOrder(ctx, order, model.PaymentEventSourceReconcile, model.StatusActor{Type: model.ActorSystem})
+		// Mock invoices only live in memory and are gone after a restart.
+		if err != nil && !errors.Is(err, ErrMockInvoiceNotFound) {
+			return err
+		}
+		if event != nil {
+			// The provider already settled the invoice one way or the other.
+			return nil
+		}
+	}
+
+	actor := model.StatusActor{Type: model.ActorSystem}
+	reason := fmt.Sprintf("Kedaluwarsa otomatis: belum dibayar setelah %s", ttl)
+
+	err := u.markExpired(order, actor, reason)
+	if errors.Is(err, repository.ErrStaleOrderStatus) {
+		// Someone else moved the order in the meantime; it is no longer abandoned.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("⌛ Order %s kedaluwarsa otomatis\n", order.OrderCode)
+
+	if order.XenditInvoiceID != "" {
+		callCtx, cancel := context.WithTimeout(ctx, reconcileCallTimeout)
+		defer cancel()
+
+		_, err := u.paymentUc.ExpireInvoice(callCtx, order.XenditInvoiceID)
+		if err != nil && !errors.Is(err, ErrMockInvoiceNotFound) {
+			return fmt.Errorf("order dibatalkan tetapi invoice gagal dikedaluwarsakan: %w", err)
+		}
+	}
+	return nil
+}
+
+// OrderExpiresAt returns when a pending, unpaid order will be cancelled automatically.
+// It returns false when the order is not subject to expiry.
+func OrderExpiresAt(order *model.Order) (time.Time, bool) {
+	if order.OrderStatus != model.OrderStatusPending || order.PaymentStatus != model.PaymentStatusPending {
+		return time.Time{}, false
+	}
+
+	ttl := config.OrderExpiry(order.PaymentMethod)
+	if ttl <= 0 {
+		return time.Time{}, false
+	}
+	return order.CreatedAt.Add(ttl), true
+}
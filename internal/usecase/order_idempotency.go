@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+	"github.com/Rakhulsr/foodcourt/utils"
+	"gorm.io/gorm"
+)
+
+// idempotencyLockDuration is how long a key stays reserved for a request that is
+// creating its order. Creating an order takes seconds, so a longer hold means the
+// request died.
+const idempotencyLockDuration = 2 * time.Minute
+
+var (
+	errIdempotencyKeyReused = errors.New("idempotency key sudah dipakai untuk pesanan yang berbeda")
+	errOrderInProgress      = errors.New("pesanan dengan idempotency key ini masih diproses, silakan tunggu")
+)
+
+// CreateOrder creates the order once per idempotency key. A retry with the same
+// key and body returns the original order; a retry with a different body is rejected.
+func (u *orderUsecase) CreateOrder(req dto.CreateOrderRequest) (*dto.CreateOrderResponse, error) {
+	if req.IdempotencyKey == "" {
+		return u.createOrder(req)
+	}
+	if len(req.IdempotencyKey) > 100 {
+		return nil, errors.New("idempotency key terlalu panjang")
+	}
+
+	fingerprint := fingerprintOrderRequest(req)
+
+	reserved, err := u.idemRepo.Reserve(&model.IdempotencyKey{
+		Key:         req.IdempotencyKey,
+		Fingerprint: fingerprint,
+		Status:      model.IdempotencyStatusProcessing,
+	}, time.Now(), idempotencyLockDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reserved {
+		return u.replayKey(req.IdempotencyKey, fingerprint)
+	}
+
+	resp, err := u.createOrder(req)
+	if errors.Is(err, repository.ErrIdempotencyKeyTaken) {
+		// Another request took the key over after our lock ran out and finished first.
+		return u.replayKey(req.IdempotencyKey, fingerprint)
+	}
+	if err != nil {
+		if releaseErr := u.idemRepo.Release(req.IdempotencyKey); releaseErr != nil {
+			fmt.Printf("⚠️ Gagal melepas idempotency key %s: %v\n", req.IdempotencyKey, releaseErr)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ReplayOrder returns the stored result for a key without a request body, for retries
+// that arrive after the cart has already been cleared.
+func (u *orderUsecase) ReplayOrder(idempotencyKey string) (*dto.CreateOrderResponse, error) {
+	record, err := u.idemRepo.FindByKey(idempotencyKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return u.replay(record)
+}
+
+func (u *orderUsecase) replayKey(key string, fingerprint string) (*dto.CreateOrderResponse, error) {
+	record, err := u.idemRepo.FindByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if record.Fingerprint != fingerprint {
+		return nil, errIdempotencyKeyReused
+	}
+	return u.replay(record)
+}
+
+// replay answers a retry from the order the key is linked to.
+func (u *orderUsecase) replay(record *model.IdempotencyKey) (*dto.CreateOrderResponse, error) {
+	if record.Status != model.IdempotencyStatusCompleted || record.OrderID == nil {
+		return nil, errOrderInProgress
+	}
+
+	order, err := u.orderRepo.FindByID(*record.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored dto.CreateOrderResponse
+	json.Unmarshal([]byte(record.Response), &stored)
+
+	// The invoice is usually created by the outbox worker after the first response.
+	resp := &dto.CreateOrderResponse{
+		OrderCode:   order.OrderCode,
+		AccessToken: order.AccessToken,
+		Message:     stored.Message,
+	}
+	if order.PaymentStatus == model.PaymentStatusPending && !model.IsFinalOrderStatus(order.OrderStatus) {
+		resp.PaymentURL = order.InvoiceURL
+	}
+	return resp, nil
+}
+
+// fingerprintOrderRequest hashes the parts of the request that define the order.
+func fingerprintOrderRequest(req dto.CreateOrderRequest) string {
+	payload, _ := json.Marshal(struct {
+		CustomerName  string
+		TableNumber   string
+		PaymentMethod string
+		Items         []dto.CreateOrderItemRequest
+		VoucherCode   string
+		OrderType     string
+		// omitempty keeps the hash of requests without a number unchanged.
+		CustomerPhone string `json:",omitempty"`
+	}{req.CustomerName, req.TableNumber, req.PaymentMethod, req.Items, req.VoucherCode, req.OrderType, utils.NormalizePhone(req.CustomerPhone)})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeIdemRepo keeps idempotency keys in memory with the same takeover rule as the
+// database version.
+type fakeIdemRepo struct {
+	repository.IdempotencyRepository
+	keys map[string]*model.IdempotencyKey
+}
+
+func (r *fakeIdemRepo) Reserve(record *model.IdempotencyKey, now time.Time, lockFor time.Duration) (bool, error) {
+	lockedUntil := now.Add(lockFor)
+	existing, ok := r.keys[record.Key]
+	if !ok {
+		stored := *record
+		stored.LockedUntil = &lockedUntil
+		r.keys[record.Key] = &stored
+		return true, nil
+	}
+	if existing.Fingerprint == record.Fingerprint && existing.Status == model.IdempotencyStatusProcessing &&
+		(existing.LockedUntil == nil || existing.LockedUntil.Before(now)) {
+		existing.LockedUntil = &lockedUntil
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *fakeIdemRepo) FindByKey(key string) (*model.IdempotencyKey, error) {
+	record, ok := r.keys[key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (r *fakeIdemRepo) Release(key string) error {
+	if record, ok := r.keys[key]; ok && record.Status == model.IdempotencyStatusProcessing {
+		delete(r.keys, key)
+	}
+	return nil
+}
+
+func (r *fakeIdemRepo) complete(completion *repository.IdempotencyCompletion, orderID uint) error {
+	record, ok := r.keys[completion.Key]
+	if !ok || record.Status != model.IdempotencyStatusProcessing {
+		return repository.ErrIdempotencyKeyTaken
+	}
+	record.Status = model.IdempotencyStatusCompleted
+	record.OrderID = &orderID
+	record.Response = completion.Response
+	record.LockedUntil = nil
+	return nil
+}
+
+// fakeOrderStore stores orders in memory and, like the database version, completes
+// the idempotency key in the same step or not at all.
+type fakeOrderStore struct {
+	repository.OrderRepository
+	idem   *fakeIdemRepo
+	orders map[uint]*model.Order
+	err    error
+	// beforeCreate runs as the order is being stored, to simulate a parallel request.
+	beforeCreate func()
+}
+
+func (r *fakeOrderStore) Create(order *model.Order, idempotency *repository.IdempotencyCompletion) error {
+	if r.beforeCreate != nil {
+		r.beforeCreate()
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	id := uint(len(r.orders) + 1)
+	if idempotency != nil {
+		if err := r.idem.complete(idempotency, id); err != nil {
+			return err
+		}
+	}
+	order.ID = id
+	stored := *order
+	r.orders[id] = &stored
+	return nil
+}
+
+func (r *fakeOrderStore) FindByID(id uint) (*model.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return order, nil
+}
+
+type fakeMenuLookup struct {
+	repository.MenuRepository
+	menus map[uint]*model.Menu
+}
+
+func (r *fakeMenuLookup) FindByID(id uint) (*model.Menu, error) {
+	menu, ok := r.menus[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return menu, nil
+}
+
+type idempotencyFixture struct {
+	u      *orderUsecase
+	idem   *fakeIdemRepo
+	orders *fakeOrderStore
+}
+
+func newIdempotencyFixture() *idempotencyFixture {
+	idem := &fakeIdemRepo{keys: map[string]*model.IdempotencyKey{}}
+	orders := &fakeOrderStore{idem: idem, orders: map[uint]*model.Order{}}
+	menus := &fakeMenuLookup{menus: map[uint]*model.Menu{
+		1: {ID: 1, BoothID: 1, Name: "Bakso", Price: 15000, IsAvailable: true, Booth: model.Booth{ID: 1, IsActive: true}},
+	}}
+	u := NewOrderUsecase(orders, menus, nil, nil, nil, nil, nil, idem, nil, nil, nil)
+	return &idempotencyFixture{u: u, idem: idem, orders: orders}
+}
+
+// hold stores a processing key for req, locked until now plus lockFor.
+func (f *idempotencyFixture) hold(req dto.CreateOrderRequest, fingerprint string, lockFor time.Duration) {
+	lockedUntil := time.Now().Add(lockFor)
+	f.idem.keys[req.IdempotencyKey] = &model.IdempotencyKey{
+		Key:         req.IdempotencyKey,
+		Fingerprint: fingerprint,
+		Status:      model.IdempotencyStatusProcessing,
+		LockedUntil: &lockedUntil,
+	}
+}
+
+func orderRequest(name string) dto.CreateOrderRequest {
+	return dto.CreateOrderRequest{
+		CustomerName:   name,
+		PaymentMethod:  "cash",
+		OrderType:      model.OrderTypeTakeaway,
+		Items:          []dto.CreateOrderItemRequest{{MenuID: 1, Quantity: 2}},
+		IdempotencyKey: "key-1",
+	}
+}
+
+func TestCreateOrderIdempotency(t *testing.T) {
+	req := orderRequest("Budi")
+
+	tests := []struct {
+		name       string
+		setup      func(f *idempotencyFixture)
+		req        dto.CreateOrderRequest
+		wantErr    error
+		wantOrders int
+		// wantOrderID is the stored order the response must point at; 0 expects an error.
+		wantOrderID uint
+		wantKey     string
+	}{
+		{
+			name:       "new key creates the order and completes the key",
+			req:        req,
+			wantOrders: 1, wantOrderID: 1, wantKey: model.IdempotencyStatusCompleted,
+		},
+		{
+			name:       "retry gets the same order",
+			setup:      func(f *idempotencyFixture) { f.u.CreateOrder(req) },
+			req:        req,
+			wantOrders: 1, wantOrderID: 1, wantKey: model.IdempotencyStatusCompleted,
+		},
+		{
+			name:       "same key with another body is rejected",
+			setup:      func(f *idempotencyFixture) { f.u.CreateOrder(req) },
+			req:        orderRequest("Siti"),
+			wantErr:    errIdempotencyKeyReused,
+			wantOrders: 1, wantKey: model.IdempotencyStatusCompleted,
+		},
+		{
+			name:    "request still in flight",
+			setup:   func(f *idempotencyFixture) { f.hold(req, fingerprintOrderRequest(req), time.Minute) },
+			req:     req,
+			wantErr: errOrderInProgress, wantKey: model.IdempotencyStatusProcessing,
+		},
+		{
+			name:       "stale lock is taken over",
+			setup:      func(f *idempotencyFixture) { f.hold(req, fingerprintOrderRequest(req), -time.Second) },
+			req:        req,
+			wantOrders: 1, wantOrderID: 1, wantKey: model.IdempotencyStatusCompleted,
+		},
+		{
+			name:    "stale lock of another body is not taken over",
+			setup:   func(f *idempotencyFixture) { f.hold(req, "other", -time.Second) },
+			req:     req,
+			wantErr: errIdempotencyKeyReused, wantKey: model.IdempotencyStatusProcessing,
+		},
+		{
+			name: "failed create releases the key",
+			setup: func(f *idempotencyFixture) {
+				f.orders.err = errors.New("db down")
+			},
+			req: req,
+		},
+		{
+			name: "parallel takeover that finished first wins",
+			setup: func(f *idempotencyFixture) {
+				f.orders.beforeCreate = func() {
+					f.orders.beforeCreate = nil
+					winner := &model.Order{OrderCode: "ORD-WINNER", AccessToken: "t"}
+					f.orders.Create(winner, &repository.IdempotencyCompletion{Key: req.IdempotencyKey, Response: "{}"})
+				}
+			},
+			req:        req,
+			wantOrders: 1, wantOrderID: 1, wantKey: model.IdempotencyStatusCompleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newIdempotencyFixture()
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			resp, err := f.u.CreateOrder(tt.req)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateOrder() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantOrderID == 0:
+				if err == nil {
+					t.Fatal("CreateOrder() succeeded, want an error")
+				}
+			case err != nil:
+				t.Fatalf("CreateOrder(): %v", err)
+			default:
+				want := f.orders.orders[tt.wantOrderID]
+				if resp.OrderCode != want.OrderCode || resp.AccessToken != want.AccessToken {
+					t.Errorf("response = %s/%s, want order %s/%s", resp.OrderCode, resp.AccessToken, want.OrderCode, want.AccessToken)
+				}
+			}
+
+			if got := len(f.orders.orders); got != tt.wantOrders {
+				t.Errorf("stored %d orders, want %d", got, tt.wantOrders)
+			}
+
+			record, ok := f.idem.keys[tt.req.IdempotencyKey]
+			status := ""
+			if ok {
+				status = record.Status
+			}
+			if status != tt.wantKey {
+				t.Errorf("key status = %q, want %q", status, tt.wantKey)
+			}
+			if status == model.IdempotencyStatusCompleted && (record.OrderID == nil || f.orders.orders[*record.OrderID] == nil) {
+				t.Errorf("completed key is not linked to a stored order: %v", record.OrderID)
+			}
+		})
+	}
+}
+
+func TestReplayOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		order       model.Order
+		wantPayment string
+	}{
+		{
+			name:        "pending QRIS order gets the invoice made after the first response",
+			order:       model.Order{OrderCode: "ORD-A", AccessToken: "a", PaymentStatus: model.PaymentStatusPending, OrderStatus: model.OrderStatusPending, InvoiceURL: "https://pay/a"},
+			wantPayment: "https://pay/a",
+		},
+		{
+			name:  "paid order is not sent to pay again",
+			order: model.Order{OrderCode: "ORD-B", AccessToken: "b", PaymentStatus: model.PaymentStatusPaid, OrderStatus: model.OrderStatusConfirmed, InvoiceURL: "https://pay/b"},
+		},
+		{
+			name:  "cancelled order is not sent to pay again",
+			order: model.Order{OrderCode: "ORD-C", AccessToken: "c", PaymentStatus: model.PaymentStatusPending, OrderStatus: model.OrderStatusCancelled, InvoiceURL: "https://pay/c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newIdempotencyFixture()
+			f.idem.keys["key-1"] = &model.IdempotencyKey{Key: "key-1", Status: model.IdempotencyStatusProcessing}
+			order := tt.order
+			if err := f.orders.Create(&order, &repository.IdempotencyCompletion{Key: "key-1", Response: `{"message":"Pesanan berhasil dibuat"}`}); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			resp, err := f.u.ReplayOrder("key-1")
+			if err != nil {
+				t.Fatalf("ReplayOrder(): %v", err)
+			}
+			if resp.OrderCode != tt.order.OrderCode || resp.AccessToken != tt.order.AccessToken {
+				t.Errorf("response = %+v, want order %s", resp, tt.order.OrderCode)
+			}
+			if resp.PaymentURL != tt.wantPayment {
+				t.Errorf("payment URL = %q, want %q", resp.PaymentURL, tt.wantPayment)
+			}
+			if resp.Message != "Pesanan berhasil dibuat" {
+				t.Errorf("message = %q", resp.Message)
+			}
+		})
+	}
+
+	f := newIdempotencyFixture()
+	if resp, err := f.u.ReplayOrder("unknown"); resp != nil || err != nil {
+		t.Errorf("ReplayOrder(unknown) = %v, %v, want nothing", resp, err)
+	}
+}
@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/config"
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/utils"
+)
+
+// maxVoucherCodes is how many codes one order may combine.
+const maxVoucherCodes = 3
+
+// pricedOrder is an order request priced against the current menus, bundles, vouchers
+// and fees.
+type pricedOrder struct {
+	OrderType   string
+	Items       []model.OrderItem
+	Subtotal    int
+	Discount    int
+	Redemptions []model.VoucherRedemption
+	Charges     []model.OrderCharge
+}
+
+func (p *pricedOrder) Total() int {
+	total := p.Subtotal - p.Discount
+	for _, charge := range p.Charges {
+		total += charge.Amount
+	}
+	return total
+}
+
+// priceOrder turns the requested menus and bundles into order items, applies the
+// requested vouchers and adds the fees. Nothing is stored, so it also backs the cart
+// and checkout previews.
+func (u *orderUsecase) priceOrder(req dto.CreateOrderRequest) (*pricedOrder, error) {
+	orderType := req.OrderType
+	if orderType == "" {
+		orderType = model.OrderTypeDineIn
+	}
+	if !model.IsValidOrderType(orderType) {
+		return nil, fmt.Errorf("jenis pesanan '%s' tidak dikenal", req.OrderType)
+	}
+
+	priced := &pricedOrder{OrderType: orderType}
+	categories := make(map[uint]string)
+
+	for _, itemReq := range req.Items {
+		// Cart lines come from a cookie the customer can edit, and a negative
+		// quantity would add stock and lower the total.
+		if itemReq.Quantity <= 0 {
+			return nil, errors.New("jumlah pesanan harus lebih dari 0")
+		}
+
+		if itemReq.BundleID != 0 {
+			bundle, err := u.bundleRepo.FindByID(itemReq.BundleID)
+			if err != nil {
+				return nil, fmt.Errorf("paket ID %d tidak ditemukan", itemReq.BundleID)
+			}
+
+			items, err := bundleOrderItems(bundle, itemReq.Quantity, itemReq.Notes)
+			if err != nil {
+				return nil, err
+			}
+			for _, component := range bundle.Items {
+				categories[component.MenuID] = component.Menu.Category
+			}
+			for _, item := range items {
+				priced.Subtotal += item.PriceAtPurchase * item.Quantity
+			}
+			priced.Items = append(priced.Items, items...)
+			continue
+		}
+
+		menu, err := u.menuRepo.FindByID(itemReq.MenuID)
+		if err != nil {
+			return nil, fmt.Errorf("menu ID %d tidak ditemukan", itemReq.MenuID)
+		}
+		if !menu.IsAvailable {
+			return nil, fmt.Errorf("menu '%s' tidak tersedia", menu.Name)
+		}
+		if !menu.Booth.IsActive {
+			return nil, fmt.Errorf("booth '%s' tutup", menu.Booth.Name)
+		}
+
+		options, delta, err := resolveMenuOptions(menu, itemReq.OptionIDs)
+		if err != nil {
+			return nil, err
+		}
+		unitPrice := menu.Price + delta
+
+		priced.Subtotal += unitPrice * itemReq.Quantity
+		categories[menu.ID] = menu.Category
+
+		priced.Items = append(priced.Items, model.OrderItem{
+			MenuID:          itemReq.MenuID,
+			BoothID:         menu.BoothID,
+			Quantity:        itemReq.Quantity,
+			PriceAtPurchase: unitPrice,
+			Notes:           itemReq.Notes,
+			Options:         options,
+		})
+	}
+
+	redemptions, err := u.applyVouchers(req.VoucherCode, req.CustomerPhone, priced.Items, categories)
+	if err != nil {
+		return nil, err
+	}
+	priced.Redemptions = redemptions
+	for _, r := range redemptions {
+		priced.Discount += r.Amount
+	}
+	priced.Charges = applyCharges(config.OrderCharges(), priced.Items, model.IsPackedOrderType(orderType))
+
+	if priced.Total() <= 0 && req.PaymentMethod == "qris" {
+		return nil, errors.New("total setelah diskon Rp 0, silakan pilih pembayaran cash")
+	}
+	return priced, nil
+}
+
+func (u *orderUsecase) QuoteOrder(req dto.CreateOrderRequest) (*dto.OrderQuote, error) {
+	priced, err := u.priceOrder(req)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &dto.OrderQuote{
+		Subtotal: priced.Subtotal,
+		Discount: priced.Discount,
+		Total:    priced.Total(),
+	}
+	for _, r := range priced.Redemptions {
+		quote.Vouchers = append(quote.Vouchers, dto.AppliedVoucher{Code: r.Code, Amount: r.Amount})
+	}
+	for _, charge := range priced.Charges {
+		quote.Charges = append(quote.Charges, dto.AppliedCharge{Code: charge.Code, Label: charge.Label, Amount: charge.Amount})
+	}
+	return quote, nil
+}
+
+// applyCharges works out the fees of an order after its discount. Line charges are
+// summed over the items first, then order charges are taken in sequence over the
+// discounted subtotal, plus the charges before them when they compound. Every fee is
+// rounded half up to whole rupiah on its own, so the breakdown adds up to the total.
+// Fees that come to zero are left out.
+func applyCharges(charges []config.Charge, items []model.OrderItem, packed bool) []model.OrderCharge {
+	base := 0
+	for _, item := range items {
+		base += item.PriceAtPurchase*item.Quantity - item.DiscountAmount
+	}
+
+	var applied []model.OrderCharge
+	charged := 0
+	for _, charge := range charges {
+		if charge.PackedOnly && !packed {
+			continue
+		}
+
+		amount := 0
+		switch charge.Level {
+		case config.ChargeLevelLine:
+			for _, item := range items {
+				net := item.PriceAtPurchase*item.Quantity - item.DiscountAmount
+				amount += charge.Flat*item.Quantity + applyRate(net, charge.RateBP)
+			}
+		case config.ChargeLevelOrder:
+			over := base
+			if charge.Compound {
+				over += charged
+			}
+			amount = charge.Flat + applyRate(over, charge.RateBP)
+		}
+
+		if amount <= 0 {
+			continue
+		}
+		charged += amount
+		applied = append(applied, model.OrderCharge{Code: charge.Code, Label: charge.Label, Amount: amount})
+	}
+	return applied
+}
+
+// applyRate returns rateBP basis points of amount, rounded half up to whole rupiah.
+func applyRate(amount int, rateBP int) int {
+	if amount <= 0 || rateBP <= 0 {
+		return 0
+	}
+	return (amount*rateBP + 5000) / 10000
+}
+
+// applyVouchers validates the comma separated voucher codes against the order and
+// spreads each discount over the items in its scope, in proportion to what is left
+// of their price. It returns one redemption per voucher.
+func (u *orderUsecase) applyVouchers(rawCodes string, customerPhone string, items []model.OrderItem, categories map[uint]string) ([]model.VoucherRedemption, error) {
+	codes := parseVoucherCodes(rawCodes)
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	if len(codes) > maxVoucherCodes {
+		return nil, fmt.Errorf("maksimal %d voucher per pesanan", maxVoucherCodes)
+	}
+
+	now := time.Now()
+	customer := customerKey(customerPhone)
+
+	vouchers := make([]*model.Voucher, 0, len(codes))
+	for _, code := range codes {
+		voucher, err := u.voucherRepo.FindByCode(code)
+		if err != nil {
+			return nil, fmt.Errorf("kode voucher %s tidak ditemukan", code)
+		}
+		if !voucher.IsValidAt(now) {
+			return nil, fmt.Errorf("voucher %s tidak berlaku saat ini", code)
+		}
+		if len(codes) > 1 && !voucher.Stackable {
+			return nil, fmt.Errorf("voucher %s tidak dapat digabung dengan voucher lain", code)
+		}
+
+		if voucher.UsageLimit > 0 {
+			used, err := u.voucherRepo.CountRedemptions(voucher.ID, "")
+			if err != nil {
+				return nil, err
+			}
+			if used >= int64(voucher.UsageLimit) {
+				return nil, fmt.Errorf("kuota voucher %s sudah habis", code)
+			}
+		}
+		if voucher.PerCustomerLimit > 0 {
+			if customer == "" {
+				return nil, fmt.Errorf("voucher %s hanya bisa dipakai dengan nomor WhatsApp", code)
+			}
+			used, err := u.voucherRepo.CountRedemptions(voucher.ID, customer)
+			if err != nil {
+				return nil, err
+			}
+			if used >= int64(voucher.PerCustomerLimit) {
+				return nil, fmt.Errorf("voucher %s sudah mencapai batas pemakaian Anda", code)
+			}
+		}
+		vouchers = append(vouchers, voucher)
+	}
+
+	var redemptions []model.VoucherRedemption
+	for _, voucher := range vouchers {
+		var scope []int
+		spend := 0
+		remaining := make([]int, 0, len(items))
+		for i, item := range items {
+			if !voucher.AppliesTo(item.BoothID, categories[item.MenuID]) {
+				continue
+			}
+			lineTotal := item.PriceAtPurchase * item.Quantity
+			scope = append(scope, i)
+			spend += lineTotal
+			remaining = append(remaining, lineTotal-item.DiscountAmount)
+		}
+
+		if len(scope) == 0 {
+			return nil, fmt.Errorf("voucher %s tidak berlaku untuk menu yang dipesan", voucher.Code)
+		}
+		if spend < voucher.MinSpend {
+			return nil, fmt.Errorf("voucher %s butuh minimal belanja Rp %d", voucher.Code, voucher.MinSpend)
+		}
+
+		base := 0
+		for _, amount := range remaining {
+			base += amount
+		}
+
+		discount := voucher.Value
+		if voucher.Type == model.VoucherTypePercent {
+			discount = base * voucher.Value / 100
+			if voucher.MaxDiscount > 0 && discount > voucher.MaxDiscount {
+				discount = voucher.MaxDiscount
+			}
+		}
+		if discount > base {
+			discount = base
+		}
+		if discount <= 0 {
+			return nil, fmt.Errorf("voucher %s tidak memberi potongan untuk pesanan ini", voucher.Code)
+		}
+
+		for k, share := range splitProportionally(discount, remaining) {
+			items[scope[k]].DiscountAmount += share
+		}
+
+		redemptions = append(redemptions, model.VoucherRedemption{
+			VoucherID:   voucher.ID,
+			Code:        voucher.Code,
+			CustomerKey: customer,
+			Amount:      discount,
+		})
+	}
+	return redemptions, nil
+}
+
+// parseVoucherCodes splits "hemat10, MAKAN5" into unique upper-case codes.
+func parseVoucherCodes(raw string) []string {
+	seen := make(map[string]bool)
+	var codes []string
+	for _, code := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' }) {
+		code = strings.ToUpper(code)
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// customerKey identifies a customer for per-customer voucher limits by their
+// normalized WhatsApp number, which is harder to vary than a typed name. It is empty
+// when no valid number was given.
+func customerKey(phone string) string {
+	phone = utils.NormalizePhone(phone)
+	if !utils.IsValidPhone(phone) {
+		return ""
+	}
+	return phone
+}
+
+// splitProportionally divides amount over the weights in proportion to each weight.
+// The rupiah lost to rounding goes to the first non-zero entries, so the shares
+// always add up to amount.
+func splitProportionally(amount int, weights []int) []int {
+	shares := make([]int, len(weights))
+	if len(shares) == 0 {
+		return shares
+	}
+
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	if total == 0 {
+		shares[0] = amount
+		return shares
+	}
+
+	allocated := 0
+	for i, w := range weights {
+		shares[i] = amount * w / total
+		allocated += shares[i]
+	}
+	for i := 0; allocated < amount; i = (i + 1) % len(shares) {
+		if weights[i] == 0 {
+			continue
+		}
+		shares[i]++
+		allocated++
+	}
+	return shares
+}
@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Rakhulsr/foodcourt/config"
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+func TestSplitProportionally(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int
+		weights []int
+		want    []int
+	}{
+		{"no weights", 100, nil, []int{}},
+		{"even", 90, []int{1, 1, 1}, []int{30, 30, 30}},
+		{"remainder to first", 100, []int{1, 1, 1}, []int{34, 33, 33}},
+		{"proportional", 1000, []int{2000, 1000}, []int{667, 333}},
+		{"zero weight skipped", 11, []int{0, 5, 5}, []int{0, 6, 5}},
+		{"all zero weights", 7, []int{0, 0}, []int{7, 0}},
+		{"zero amount", 0, []int{3, 4}, []int{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitProportionally(tt.amount, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("splitProportionally(%d, %v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+			}
+
+			sum := 0
+			for _, share := range got {
+				sum += share
+			}
+			if len(got) > 0 && sum != tt.amount {
+				t.Errorf("shares add up to %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestApplyRate(t *testing.T) {
+	tests := []struct {
+		amount, rateBP, want int
+	}{
+		{0, 1000, 0},
+		{1000, 0, 0},
+		{-500, 1000, 0},
+		{23000, 500, 1150},
+		{15, 1000, 2}, // 1.5 rounds half up
+		{14, 1000, 1},
+		{24150, 1000, 2415},
+	}
+
+	for _, tt := range tests {
+		if got := applyRate(tt.amount, tt.rateBP); got != tt.want {
+			t.Errorf("applyRate(%d, %d) = %d, want %d", tt.amount, tt.rateBP, got, tt.want)
+		}
+	}
+}
+
+func TestApplyCharges(t *testing.T) {
+	items := []model.OrderItem{
+		{MenuID: 1, BoothID: 1, PriceAtPurchase: 10000, Quantity: 2, DiscountAmount: 2000},
+		{MenuID: 2, BoothID: 2, PriceAtPurchase: 5000, Quantity: 1},
+	}
+	packaging := config.Charge{Code: config.ChargeCodePackaging, Level: config.ChargeLevelLine, Flat: 2000, PackedOnly: true}
+	service := config.Charge{Code: config.ChargeCodeService, Level: config.ChargeLevelOrder, RateBP: 500}
+	tax := config.Charge{Code: config.ChargeCodeTax, Level: config.ChargeLevelOrder, RateBP: 1000, Compound: true}
+
+	tests := []struct {
+		name    string
+		charges []config.Charge
+		items   []model.OrderItem
+		packed  bool
+		want    map[string]int
+	}{
+		{
+			name:    "dine-in skips packaging, tax compounds on service",
+			charges: []config.Charge{packaging, service, tax},
+			items:   items,
+			want:    map[string]int{config.ChargeCodeService: 1150, config.ChargeCodeTax: 2415},
+		},
+		{
+			name:    "takeaway pays packaging per unit",
+			charges: []config.Charge{packaging, service, tax},
+			items:   items,
+			packed:  true,
+			want: map[string]int{
+				config.ChargeCodePackaging: 6000,
+				config.ChargeCodeService:   1150,
+				config.ChargeCodeTax:       3015,
+			},
+		},
+		{
+			name:    "line rate per item",
+			charges: []config.Charge{{Code: "line", Level: config.ChargeLevelLine, RateBP: 1000}},
+			items:   items,
+			want:    map[string]int{"line": 2300},
+		},
+		{
+			name:    "flat order fee",
+			charges: []config.Charge{{Code: "flat", Level: config.ChargeLevelOrder, Flat: 1000}},
+			items:   items,
+			want:    map[string]int{"flat": 1000},
+		},
+		{
+			name:    "zero fees are left out",
+			charges: []config.Charge{service, tax},
+			items:   nil,
+			want:    map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]int{}
+			for _, charge := range applyCharges(tt.charges, tt.items, tt.packed) {
+				got[charge.Code] = charge.Amount
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyCharges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeVoucherRepo serves vouchers by code and redemption counts per voucher and
+// customer key.
+type fakeVoucherRepo struct {
+	repository.VoucherRepository
+	vouchers map[string]*model.Voucher
+	used     map[string]int64
+}
+
+func (r *fakeVoucherRepo) FindByCode(code string) (*model.Voucher, error) {
+	voucher, ok := r.vouchers[code]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return voucher, nil
+}
+
+func (r *fakeVoucherRepo) CountRedemptions(voucherID uint, customerKey string) (int64, error) {
+	return r.used[fmt.Sprintf("%d/%s", voucherID, customerKey)], nil
+}
+
+func TestApplyVouchers(t *testing.T) {
+	boothTwo := uint(2)
+	vouchers := map[string]*model.Voucher{
+		"HEMAT10":  {ID: 1, Code: "HEMAT10", Type: model.VoucherTypePercent, Value: 10, IsActive: true, Stackable: true},
+		"POTONG1K": {ID: 2, Code: "POTONG1K", Type: model.VoucherTypeFixed, Value: 1000, IsActive: true, Stackable: true},
+		"BESAR":    {ID: 3, Code: "BESAR", Type: model.VoucherTypeFixed, Value: 50000, IsActive: true},
+		"MAKS1K":   {ID: 4, Code: "MAKS1K", Type: model.VoucherTypePercent, Value: 50, MaxDiscount: 1000, IsActive: true},
+		"BOOTH2":   {ID: 5, Code: "BOOTH2", Type: model.VoucherTypePercent, Value: 10, BoothID: &boothTwo, IsActive: true},
+		"MINUM":    {ID: 6, Code: "MINUM", Type: model.VoucherTypeFixed, Value: 500, Category: "minuman", IsActive: true},
+		"MIN50K":   {ID: 7, Code: "MIN50K", Type: model.VoucherTypeFixed, Value: 1000, MinSpend: 50000, IsActive: true},
+		"SENDIRI":  {ID: 8, Code: "SENDIRI", Type: model.VoucherTypeFixed, Value: 1000, IsActive: true},
+		"SEKALI":   {ID: 9, Code: "SEKALI", Type: model.VoucherTypeFixed, Value: 1000, PerCustomerLimit: 1, IsActive: true},
+		"HABIS":    {ID: 10, Code: "HABIS", Type: model.VoucherTypeFixed, Value: 1000, UsageLimit: 5, IsActive: true},
+		"MATI":     {ID: 11, Code: "MATI", Type: model.VoucherTypeFixed, Value: 1000, IsActive: false},
+	}
+	categories := map[uint]string{1: "makanan", 2: "minuman"}
+
+	tests := []struct {
+		name          string
+		codes         string
+		phone         string
+		used          map[string]int64
+		wantErr       bool
+		wantDiscounts []int
+		wantAmounts   []int
+		wantCustomer  string
+	}{
+		{name: "no codes", codes: " ", wantDiscounts: []int{0, 0}},
+		{name: "unknown code", codes: "NGASAL", wantErr: true},
+		{name: "inactive", codes: "MATI", wantErr: true},
+		{name: "percent split over items", codes: "hemat10", wantDiscounts: []int{2000, 500}, wantAmounts: []int{2500}},
+		{name: "fixed capped at order value", codes: "BESAR", wantDiscounts: []int{20000, 5000}, wantAmounts: []int{25000}},
+		{name: "percent capped by max discount", codes: "MAKS1K", wantDiscounts: []int{800, 200}, wantAmounts: []int{1000}},
+		{name: "booth scope", codes: "BOOTH2", wantDiscounts: []int{0, 500}, wantAmounts: []int{500}},
+		{name: "category scope", codes: "MINUM", wantDiscounts: []int{0, 500}, wantAmounts: []int{500}},
+		{name: "min spend not met", codes: "MIN50K", wantErr: true},
+		{name: "stacked on what is left", codes: "HEMAT10,POTONG1K", wantDiscounts: []int{2800, 700}, wantAmounts: []int{2500, 1000}},
+		{name: "duplicate codes count once", codes: "HEMAT10, hemat10", wantDiscounts: []int{2000, 500}, wantAmounts: []int{2500}},
+		{name: "not stackable", codes: "HEMAT10,SENDIRI", wantErr: true},
+		{name: "too many codes", codes: "A,B,C,D", wantErr: true},
+		{name: "per-customer limit needs a phone", codes: "SEKALI", wantErr: true},
+		{name: "per-customer limit ignores invalid phone", codes: "SEKALI", phone: "12345", wantErr: true},
+		{
+			name: "per-customer limit reached", codes: "SEKALI", phone: "0812-3456-7890",
+			used: map[string]int64{"9/6281234567890": 1}, wantErr: true,
+		},
+		{
+			name: "per-customer limit keyed on phone", codes: "SEKALI", phone: "+62 812 3456 7890",
+			used:          map[string]int64{"9/6289999999999": 1},
+			wantDiscounts: []int{800, 200}, wantAmounts: []int{1000}, wantCustomer: "6281234567890",
+		},
+		{name: "usage limit reached", codes: "HABIS", used: map[string]int64{"10/": 5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &orderUsecase{voucherRepo: &fakeVoucherRepo{vouchers: vouchers, used: tt.used}}
+			items := []model.OrderItem{
+				{MenuID: 1, BoothID: 1, PriceAtPurchase: 10000, Quantity: 2},
+				{MenuID: 2, BoothID: 2, PriceAtPurchase: 5000, Quantity: 1},
+			}
+
+			redemptions, err := u.applyVouchers(tt.codes, tt.phone, items, categories)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("applyVouchers(%q) succeeded, want an error", tt.codes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyVouchers(%q): %v", tt.codes, err)
+			}
+
+			discounts := []int{items[0].DiscountAmount, items[1].DiscountAmount}
+			if !reflect.DeepEqual(discounts, tt.wantDiscounts) {
+				t.Errorf("item discounts = %v, want %v", discounts, tt.wantDiscounts)
+			}
+
+			var amounts []int
+			for _, redemption := range redemptions {
+				amounts = append(amounts, redemption.Amount)
+				if redemption.CustomerKey != tt.wantCustomer {
+					t.Errorf("customer key = %q, want %q", redemption.CustomerKey, tt.wantCustomer)
+				}
+			}
+			if !reflect.DeepEqual(amounts, tt.wantAmounts) {
+				t.Errorf("redemption amounts = %v, want %v", amounts, tt.wantAmounts)
+			}
+		})
+	}
+}
+
+func TestPriceOrderRejectsNonPositiveQuantity(t *testing.T) {
+	tests := []struct {
+		name string
+		item dto.CreateOrderItemRequest
+	}{
+		{"zero menu", dto.CreateOrderItemRequest{MenuID: 1, Quantity: 0}},
+		{"negative menu", dto.CreateOrderItemRequest{MenuID: 1, Quantity: -5}},
+		{"negative bundle", dto.CreateOrderItemRequest{BundleID: 1, Quantity: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No repositories are set: the line must be refused before any lookup.
+			u := &orderUsecase{}
+			_, err := u.priceOrder(dto.CreateOrderRequest{PaymentMethod: "cash", Items: []dto.CreateOrderItemRequest{tt.item}})
+			if err == nil {
+				t.Fatal("priceOrder() accepted the line, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/config"
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+)
+
+// TrackOrder returns the progress of an order per booth, with ready times estimated
+// from each booth's queue. Orders still awaiting payment get no estimate since they
+// are not queued at the booths yet.
+func (u *orderUsecase) TrackOrder(code string, accessToken string) (*dto.OrderTracking, error) {
+	order, err := u.GetCustomerOrder(code, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	tracking := &dto.OrderTracking{
+		OrderCode:     order.OrderCode,
+		AccessToken:   order.AccessToken,
+		QueueNumber:   order.QueueLabel(),
+		OrderType:     order.OrderTypeLabel(),
+		TableNumber:   order.TableNumber,
+		Status:        order.OrderStatus,
+		StatusLabel:   model.OrderStatusLabel(order.OrderStatus),
+		PaymentStatus: order.PaymentStatus,
+		UpdatedAt:     order.UpdatedAt,
+	}
+
+	queued := order.OrderStatus == model.OrderStatusConfirmed || order.OrderStatus == model.OrderStatusPreparing
+	done, active := 0, 0
+	now := time.Now()
+
+	for _, ticket := range order.Tickets {
+		booth := dto.BoothProgress{
+			BoothName:   ticket.Booth.Name,
+			QueueNumber: ticket.QueueLabel(),
+			Status:      ticket.Status,
+			StatusLabel: model.TicketStatusLabel(ticket.Status),
+		}
+		for _, item := range order.Items {
+			if item.BoothID == ticket.BoothID {
+				booth.Items = append(booth.Items, dto.TrackedItem{Name: item.Menu.Name, Quantity: item.Quantity, Options: item.OptionSummary()})
+			}
+		}
+
+		if ticket.Status != model.TicketStatusCancelled {
+			active++
+			if ticket.Status == model.TicketStatusReady || ticket.Status == model.TicketStatusCompleted {
+				done++
+			}
+		}
+
+		if queued && (ticket.Status == model.TicketStatusPending || ticket.Status == model.TicketStatusPreparing) {
+			readyAt, ahead, err := u.estimateTicket(ticket, now)
+			if err != nil {
+				return nil, err
+			}
+			booth.TicketsAhead = ahead
+			booth.EstimatedReadyAt = &readyAt
+
+			if tracking.EstimatedReadyAt == nil || readyAt.After(*tracking.EstimatedReadyAt) {
+				tracking.EstimatedReadyAt = &readyAt
+			}
+		}
+
+		tracking.Booths = append(tracking.Booths, booth)
+	}
+
+	switch {
+	case order.OrderStatus == model.OrderStatusCompleted || order.OrderStatus == model.OrderStatusReady:
+		tracking.Progress = 100
+	case active > 0:
+		tracking.Progress = done * 100 / active
+	}
+	return tracking, nil
+}
+
+// estimateTicket guesses when a ticket will be ready: a cooking ticket one ticket
+// time after it was started, a queued one after every ticket ahead of it plus its own.
+func (u *orderUsecase) estimateTicket(ticket model.BoothTicket, now time.Time) (time.Time, int64, error) {
+	perTicket := config.KitchenTicketTime()
+
+	if ticket.Status == model.TicketStatusPreparing {
+		readyAt := ticket.UpdatedAt.Add(perTicket)
+		if readyAt.Before(now) {
+			readyAt = now
+		}
+		return readyAt, 0, nil
+	}
+
+	ahead, err := u.ticketRepo.CountOpenBefore(ticket.BoothID, ticket.ID)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return now.Add(time.Duration(ahead+1) * perTicket), ahead, nil
+}
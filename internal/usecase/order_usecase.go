@@ -2,141 +2,223 @@ package usecase
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/Rakhulsr/foodcourt/config"
 	"github.com/Rakhulsr/foodcourt/internal/dto"
 	"github.com/Rakhulsr/foodcourt/internal/model"
 	"github.com/Rakhulsr/foodcourt/internal/repository"
 	"github.com/Rakhulsr/foodcourt/utils"
+	"gorm.io/gorm"
 )
 
 type OrderUsecase interface {
 	CreateOrder(req dto.CreateOrderRequest) (*dto.CreateOrderResponse, error)
+	QuoteOrder(req dto.CreateOrderRequest) (*dto.OrderQuote, error)
+	ReplayOrder(idempotencyKey string) (*dto.CreateOrderResponse, error)
 	GetOrderByCode(code string) (*model.Order, error)
+	// GetCustomerOrder is GetOrderByCode for customer pages: the access token must
+	// match, and a wrong token looks the same as an unknown code.
+	GetCustomerOrder(code string, accessToken string) (*model.Order, error)
+	TrackOrder(code string, accessToken string) (*dto.OrderTracking, error)
 
 	ListOrders(page int, limit int, status string) (*dto.OrderListResponse, error)
-	UpdateOrderStatus(orderCode string, newStatus string) error
+	UpdateOrderStatus(orderCode string, newStatus string, actor model.StatusActor, reason string) error
+	UpdateTicketStatus(orderCode string, ticketID uint, newStatus string, actor model.StatusActor, reason string) error
 
-	ProcessXenditCallback(payload dto.XenditCallbackRequest) error
-	SendOrderNotificationToSeller(orderCode string) error
+	ProcessXenditCallback(token string, body []byte) error
+	SendOrderNotificationToSeller(orderCode string) ([]model.OutboundMessage, error)
+	SendTicketNotification(orderCode string, ticketID uint) ([]model.OutboundMessage, error)
+
+	RunPaymentOutbox(ctx context.Context, interval time.Duration)
+	RunPaymentReconciliation(ctx context.Context, interval time.Duration)
+	RunOrderExpiry(ctx context.Context, interval time.Duration)
+	RecheckPayment(orderCode string, actor model.StatusActor) (*model.PaymentEvent, error)
+	ReconciliationReport() (*dto.ReconciliationReport, error)
+
+	// Subscribe streams order changes to live screens such as the kitchen display.
+	Subscribe() (<-chan OrderEvent, func())
 }
 
 type orderUsecase struct {
-	orderRepo repository.OrderRepository
-	menuRepo  repository.MenuRepository
-	paymentUc *PaymentUsecase
-	waUc      *WhatsAppUsecase
-	logUC     LogUseCase
+	orderRepo   repository.OrderRepository
+	menuRepo    repository.MenuRepository
+	bundleRepo  repository.BundleRepository
+	voucherRepo repository.VoucherRepository
+	tableRepo   repository.TableRepository
+	ticketRepo  repository.TicketRepository
+	outboxRepo  repository.PaymentOutboxRepository
+	idemRepo    repository.IdempotencyRepository
+	eventRepo   repository.PaymentEventRepository
+	paymentUc   *PaymentUsecase
+	messages    MessageQueue
+
+	reconcileMu   sync.Mutex
+	lastReconcile *reconcileRun
+
+	events *OrderEvents
 }
 
-func NewOrderUsecase(or repository.OrderRepository, mr repository.MenuRepository, ps *PaymentUsecase, wa WhatsAppUsecase, log LogUseCase) *orderUsecase {
+func NewOrderUsecase(or repository.OrderRepository, mr repository.MenuRepository, br repository.BundleRepository, vr repository.VoucherRepository, tbr repository.TableRepository, tr repository.TicketRepository, obr repository.PaymentOutboxRepository, ir repository.IdempotencyRepository, er repository.PaymentEventRepository, ps *PaymentUsecase, mq MessageQueue) *orderUsecase {
 	return &orderUsecase{
-		orderRepo: or,
-		menuRepo:  mr,
-		paymentUc: ps,
-		waUc:      &wa,
-		logUC:     log,
+		orderRepo:   or,
+		menuRepo:    mr,
+		bundleRepo:  br,
+		voucherRepo: vr,
+		tableRepo:   tbr,
+		ticketRepo:  tr,
+		outboxRepo:  obr,
+		idemRepo:    ir,
+		eventRepo:   er,
+		paymentUc:   ps,
+		messages:    mq,
+		events:      NewOrderEvents(),
 	}
 }
-func (u *orderUsecase) CreateOrder(req dto.CreateOrderRequest) (*dto.CreateOrderResponse, error) {
-	var orderItems []model.OrderItem
-	total := 0
+
+// createOrder builds and stores the order and returns the checkout response. The
+// request's idempotency key, if any, is completed together with the order.
+func (u *orderUsecase) createOrder(req dto.CreateOrderRequest) (*dto.CreateOrderResponse, error) {
 	var paymentURL string
 
-	type NotificationData struct {
-		BoothName string
-		BoothWA   string
-		Items     []string
+	priced, err := u.priceOrder(req)
+	if err != nil {
+		return nil, err
 	}
+	orderItems := priced.Items
 
-	notifMap := make(map[uint]*NotificationData)
-
-	for _, itemReq := range req.Items {
-		menu, err := u.menuRepo.FindByID(itemReq.MenuID)
+	var tableID *uint
+	tableNumber := ""
+	if priced.OrderType == model.OrderTypeDineIn {
+		table, err := resolveTable(u.tableRepo, req.TableNumber)
 		if err != nil {
-			return nil, fmt.Errorf("menu ID %d tidak ditemukan", itemReq.MenuID)
-		}
-		if !menu.IsAvailable {
-			return nil, fmt.Errorf("menu '%s' tidak tersedia", menu.Name)
-		}
-		if !menu.Booth.IsActive {
-			return nil, fmt.Errorf("booth '%s' tutup", menu.Booth.Name)
+			return nil, err
 		}
+		tableID = &table.ID
+		tableNumber = table.Code
+	}
 
-		total += menu.Price * itemReq.Quantity
-
-		orderItems = append(orderItems, model.OrderItem{
-			MenuID:          itemReq.MenuID,
-			BoothID:         menu.BoothID,
-			Quantity:        itemReq.Quantity,
-			PriceAtPurchase: menu.Price,
-			Notes:           itemReq.Notes,
-		})
-
-		if _, exists := notifMap[menu.BoothID]; !exists {
-			notifMap[menu.BoothID] = &NotificationData{
-				BoothName: menu.Booth.Name,
-				BoothWA:   menu.Booth.WhatsApp,
-				Items:     []string{},
-			}
+	customerPhone := ""
+	if req.CustomerPhone != "" {
+		customerPhone = utils.NormalizePhone(req.CustomerPhone)
+		if !utils.IsValidPhone(customerPhone) {
+			return nil, errors.New("nomor WhatsApp tidak valid")
 		}
+	}
 
-		noteText := ""
-		if itemReq.Notes != "" {
-			noteText = fmt.Sprintf(" _(%s)_", itemReq.Notes)
+	var tickets []model.BoothTicket
+	seenBooth := make(map[uint]bool)
+	for _, item := range orderItems {
+		if seenBooth[item.BoothID] {
+			continue
 		}
-		itemString := fmt.Sprintf("▪️ %dx %s%s", itemReq.Quantity, menu.Name, noteText)
-		notifMap[menu.BoothID].Items = append(notifMap[menu.BoothID].Items, itemString)
+		seenBooth[item.BoothID] = true
+		tickets = append(tickets, model.BoothTicket{BoothID: item.BoothID, Status: model.TicketStatusPending})
 	}
 
 	order := model.Order{
-		OrderCode:     "ORD-" + utils.RandomString(8),
-		CustomerName:  req.CustomerName,
-		TableNumber:   req.TableNumber,
-		TotalAmount:   total,
-		PaymentMethod: req.PaymentMethod,
-		OrderStatus:   "pending",
-		PaymentStatus: "pending",
-		Items:         orderItems,
+		CustomerName:   req.CustomerName,
+		CustomerPhone:  customerPhone,
+		BusinessDate:   config.BusinessDay(time.Now()),
+		AccessToken:    utils.SecureToken(16),
+		TableNumber:    tableNumber,
+		TableID:        tableID,
+		OrderType:      priced.OrderType,
+		TotalAmount:    priced.Total(),
+		PaymentMethod:  req.PaymentMethod,
+		OrderStatus:    model.OrderStatusPending,
+		PaymentStatus:  model.PaymentStatusPending,
+		Items:          orderItems,
+		Tickets:        tickets,
+		Subtotal:       priced.Subtotal,
+		DiscountAmount: priced.Discount,
+		Redemptions:    priced.Redemptions,
+		Charges:        priced.Charges,
+		StatusHistory: []model.OrderStatusHistory{
+			{Field: model.StatusFieldOrder, ToStatus: model.OrderStatusPending, ActorType: model.ActorSystem, Reason: "Pesanan dibuat"},
+			{Field: model.StatusFieldPayment, ToStatus: model.PaymentStatusPending, ActorType: model.ActorSystem, Reason: "Pesanan dibuat"},
+		},
 	}
 
-	if err := u.orderRepo.Create(&order); err != nil {
-		return nil, err
+	if order.PaymentMethod == "qris" {
+		order.PaymentJobs = []model.PaymentOutbox{{
+			Action:        model.OutboxActionCreateInvoice,
+			Status:        model.OutboxStatusPending,
+			NextAttemptAt: time.Now(),
+		}}
 	}
 
-	if order.PaymentMethod == "qris" {
-		inv, err := u.paymentUc.CreateInvoice(order)
-		if err != nil {
+	if err := u.storeOrder(&order, req.IdempotencyKey); err != nil {
+		return nil, err
+	}
+	u.publishChange(&order)
 
-			return nil, fmt.Errorf("gagal membuat invoice xendit: %w", err)
+	message := "Pesanan berhasil dibuat"
+	if len(order.PaymentJobs) > 0 {
+		paymentURL = u.processOutboxNow(order.PaymentJobs[0])
+		if paymentURL == "" {
+			message = "Pesanan tersimpan, link pembayaran sedang disiapkan"
 		}
+	}
 
-		paymentURL = inv.InvoiceUrl
+	return &dto.CreateOrderResponse{
+		OrderCode:   order.OrderCode,
+		AccessToken: order.AccessToken,
+		PaymentURL:  paymentURL,
+		Message:     message,
+	}, nil
+}
 
-		err = u.orderRepo.UpdateInvoice(order.OrderCode, *inv.Id, inv.InvoiceUrl)
-		if err != nil {
-			return nil, err
+// orderCodeAttempts bounds how often storeOrder draws a new code after a collision.
+const orderCodeAttempts = 3
+
+// storeOrder gives the order a fresh code and saves it, drawing another code when the
+// unique index reports the code as taken. The idempotency key is completed with the
+// response its retries get back.
+func (u *orderUsecase) storeOrder(order *model.Order, idempotencyKey string) error {
+	for attempt := 1; ; attempt++ {
+		order.OrderCode = utils.OrderCode()
+
+		var completion *repository.IdempotencyCompletion
+		if idempotencyKey != "" {
+			stored, _ := json.Marshal(dto.CreateOrderResponse{
+				OrderCode:   order.OrderCode,
+				AccessToken: order.AccessToken,
+				Message:     "Pesanan berhasil dibuat",
+			})
+			completion = &repository.IdempotencyCompletion{Key: idempotencyKey, Response: string(stored)}
 		}
-	} else {
-		paymentURL = ""
-	}
 
-	inv, err := u.paymentUc.CreateInvoice(order)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Xendit invoice: %w", err)
+		err := u.orderRepo.Create(order, completion)
+		// The order row is inserted first, so an ID means the duplicate came from elsewhere.
+		if err == nil || !errors.Is(err, gorm.ErrDuplicatedKey) || order.ID != 0 || attempt == orderCodeAttempts {
+			return err
+		}
+		fmt.Printf("⚠️ Kode pesanan %s sudah dipakai, membuat kode baru\n", order.OrderCode)
 	}
+}
 
-	err = u.orderRepo.UpdateInvoice(order.OrderCode, *inv.Id, inv.InvoiceUrl)
+// ErrOrderNotFound is returned to customers for unknown codes and wrong access tokens alike.
+var ErrOrderNotFound = errors.New("order tidak ditemukan")
+
+func (u *orderUsecase) GetCustomerOrder(code string, accessToken string) (*model.Order, error) {
+	order, err := u.orderRepo.FindByCode(code)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update order with invoice: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
 	}
-
-	return &dto.CreateOrderResponse{
-		OrderCode:  order.OrderCode,
-		PaymentURL: paymentURL,
-	}, nil
+	if order.AccessToken == "" || subtle.ConstantTimeCompare([]byte(order.AccessToken), []byte(accessToken)) != 1 {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
 }
 
 func (u *orderUsecase) GetOrderByCode(code string) (*model.Order, error) {
@@ -164,13 +246,8 @@ func (u *orderUsecase) ListOrders(page int, limit int, status string) (*dto.Orde
 	}, nil
 }
 
-func (u *orderUsecase) UpdateOrderStatus(orderCode string, newStatus string) error {
-
-	validStatuses := map[string]bool{
-		"pending": true, "confirmed": true, "preparing": true,
-		"ready": true, "completed": true, "cancelled": true,
-	}
-	if !validStatuses[newStatus] {
+func (u *orderUsecase) UpdateOrderStatus(orderCode string, newStatus string, actor model.StatusActor, reason string) error {
+	if !model.IsValidOrderStatus(newStatus) {
 		return errors.New("invalid status")
 	}
 
@@ -179,130 +256,436 @@ func (u *orderUsecase) UpdateOrderStatus(orderCode string, newStatus string) err
 		return err
 	}
 
-	if newStatus == "cancelled" {
-		u.orderRepo.UpdatePaymentStatus(orderCode, "expired")
+	if model.IsFinalOrderStatus(order.OrderStatus) {
+		return errors.New("pesanan sudah final (selesai/batal) dan tidak dapat diubah lagi")
 	}
 
-	if order.PaymentMethod == "cash" {
+	if !model.CanTransitionOrderStatus(order.OrderStatus, newStatus) {
+		return fmt.Errorf("status tidak dapat diubah dari %s ke %s", order.OrderStatus, newStatus)
+	}
 
-		if newStatus == "confirmed" || newStatus == "preparing" || newStatus == "ready" || newStatus == "completed" {
-			if order.PaymentStatus != "paid" {
-				u.orderRepo.UpdatePaymentStatus(orderCode, "paid")
-			}
+	paymentStatus := order.PaymentStatus
+	if newStatus == model.OrderStatusCancelled {
+		if order.PaymentStatus == model.PaymentStatusPending {
+			paymentStatus = model.PaymentStatusExpired
 		}
+	} else if order.PaymentStatus != model.PaymentStatusPaid {
+		// Cash is settled at the counter, so moving a cash order forward means it has been paid.
+		// QRIS orders must be paid through Xendit before they can be processed.
+		if order.PaymentMethod != "cash" {
+			return errors.New("pembayaran QRIS belum lunas, pesanan belum dapat diproses")
+		}
+		paymentStatus = model.PaymentStatusPaid
+	}
+
+	return u.applyTransition(order, newStatus, paymentStatus, actor, reason)
+}
+
+var errInvalidTransition = errors.New("transisi status tidak diizinkan")
 
-		if newStatus == "pending" {
-			u.orderRepo.UpdatePaymentStatus(orderCode, "pending")
+// applyTransition validates the requested order and payment statuses against the
+// state machine and persists them, the tickets they cascade to and one history row
+// per change in a single transaction.
+func (u *orderUsecase) applyTransition(order *model.Order, orderStatus string, paymentStatus string, actor model.StatusActor, reason string) error {
+	return u.applyStatusChange(order, orderStatus, paymentStatus, actor, reason, nil)
+}
+
+// markExpired cancels an order whose payment window ran out and marks it expired,
+// which admin cancels and failed invoices do not get.
+func (u *orderUsecase) markExpired(order *model.Order, actor model.StatusActor, reason string) error {
+	now := time.Now()
+	return u.applyStatusChange(order, model.OrderStatusCancelled, model.PaymentStatusExpired, actor, reason, &now)
+}
+
+func (u *orderUsecase) applyStatusChange(order *model.Order, orderStatus string, paymentStatus string, actor model.StatusActor, reason string, expiredAt *time.Time) error {
+	updates := map[string]interface{}{}
+	var history []model.OrderStatusHistory
+
+	if orderStatus != order.OrderStatus {
+		if !model.CanTransitionOrderStatus(order.OrderStatus, orderStatus) {
+			return fmt.Errorf("%w: order %s -> %s", errInvalidTransition, order.OrderStatus, orderStatus)
 		}
+		updates["order_status"] = orderStatus
+		history = append(history, model.OrderStatusHistory{
+			Field:      model.StatusFieldOrder,
+			FromStatus: order.OrderStatus,
+			ToStatus:   orderStatus,
+			ActorType:  actor.Type,
+			AdminID:    actor.AdminID,
+			Reason:     reason,
+		})
 	}
 
-	if order.OrderStatus == "completed" || order.OrderStatus == "cancelled" {
-		return errors.New("pesanan sudah final (selesai/batal) dan tidak dapat diubah lagi")
+	if paymentStatus != order.PaymentStatus {
+		if !model.CanTransitionPaymentStatus(order.PaymentStatus, paymentStatus) {
+			return fmt.Errorf("%w: payment %s -> %s", errInvalidTransition, order.PaymentStatus, paymentStatus)
+		}
+		updates["payment_status"] = paymentStatus
+		history = append(history, model.OrderStatusHistory{
+			Field:      model.StatusFieldPayment,
+			FromStatus: order.PaymentStatus,
+			ToStatus:   paymentStatus,
+			ActorType:  actor.Type,
+			AdminID:    actor.AdminID,
+			Reason:     reason,
+		})
 	}
 
-	return u.orderRepo.UpdateOrderStatus(orderCode, newStatus)
-}
+	if len(updates) == 0 {
+		return nil
+	}
+	if expiredAt != nil {
+		updates["expired_at"] = *expiredAt
+	}
 
-func (u *orderUsecase) ProcessXenditCallback(payload dto.XenditCallbackRequest) error {
+	tickets := cascadeTickets(order, orderStatus)
+	for _, change := range tickets {
+		boothID := change.Ticket.BoothID
+		history = append(history, model.OrderStatusHistory{
+			BoothID:    &boothID,
+			Field:      model.StatusFieldTicket,
+			FromStatus: change.From,
+			ToStatus:   change.To,
+			ActorType:  actor.Type,
+			AdminID:    actor.AdminID,
+			Reason:     reason,
+		})
+	}
 
-	order, err := u.orderRepo.FindByCode(payload.ExternalID)
-	if err != nil {
+	if err := u.orderRepo.ApplyStatusChange(order, updates, tickets, history); err != nil {
 		return err
 	}
 
-	if payload.Status == "PAID" || payload.Status == "SETTLED" {
+	prevOrderStatus, prevPaymentStatus := order.OrderStatus, order.PaymentStatus
+	order.OrderStatus = orderStatus
+	order.PaymentStatus = paymentStatus
+	if expiredAt != nil {
+		order.ExpiredAt = expiredAt
+	}
+	for _, change := range tickets {
+		change.Ticket.Status = change.To
+	}
+	u.publishChange(order)
+	u.notifyCustomerOfTransition(order, prevOrderStatus, prevPaymentStatus, reason)
+	return nil
+}
 
-		if err := u.orderRepo.UpdatePaymentStatus(order.OrderCode, "paid"); err != nil {
-			return err
-		}
+// cascadeTickets lists the ticket changes that push an order-level decision (ready,
+// completed, cancelled) down to the kitchen tickets that have not reached it yet.
+func cascadeTickets(order *model.Order, orderStatus string) []repository.TicketChange {
+	var target string
+	switch orderStatus {
+	case model.OrderStatusReady:
+		target = model.TicketStatusReady
+	case model.OrderStatusCompleted:
+		target = model.TicketStatusCompleted
+	case model.OrderStatusCancelled:
+		target = model.TicketStatusCancelled
+	default:
+		return nil
+	}
 
-		if err := u.orderRepo.UpdateOrderStatus(order.OrderCode, "preparing"); err != nil {
-			return err
+	var changes []repository.TicketChange
+	for i := range order.Tickets {
+		ticket := &order.Tickets[i]
+		if ticket.Status == target || !model.CanTransitionTicketStatus(ticket.Status, target) {
+			continue
+		}
+		from := ticket.Status
+		if target == model.TicketStatusCompleted && from != model.TicketStatusReady {
+			changes = append(changes, repository.TicketChange{Ticket: ticket, From: from, To: model.TicketStatusReady})
+			from = model.TicketStatusReady
 		}
+		changes = append(changes, repository.TicketChange{Ticket: ticket, From: from, To: target})
+	}
+	return changes
+}
 
-	} else if payload.Status == "EXPIRED" {
-		u.orderRepo.UpdatePaymentStatus(order.OrderCode, "expired")
-		u.orderRepo.UpdateOrderStatus(order.OrderCode, "cancelled")
+func (u *orderUsecase) UpdateTicketStatus(orderCode string, ticketID uint, newStatus string, actor model.StatusActor, reason string) error {
+	if !model.IsValidTicketStatus(newStatus) {
+		return errors.New("invalid status")
 	}
 
-	return nil
+	order, err := u.orderRepo.FindByCode(orderCode)
+	if err != nil {
+		return err
+	}
+
+	ticket := findTicket(order, ticketID)
+	if ticket == nil {
+		return errors.New("tiket tidak ditemukan pada pesanan ini")
+	}
+
+	if model.IsFinalOrderStatus(order.OrderStatus) {
+		return errors.New("pesanan sudah final (selesai/batal) dan tidak dapat diubah lagi")
+	}
+	if order.OrderStatus == model.OrderStatusPending {
+		return errors.New("pesanan belum dikonfirmasi, tiket belum dapat diproses")
+	}
+	if !model.CanTransitionTicketStatus(ticket.Status, newStatus) {
+		return fmt.Errorf("status tiket tidak dapat diubah dari %s ke %s", ticket.Status, newStatus)
+	}
+
+	return u.applyTicketTransition(order, ticket, newStatus, actor, reason)
 }
 
-func (u *orderUsecase) SendOrderNotificationToSeller(orderCode string) error {
-	order, err := u.orderRepo.FindByCode(orderCode)
+// ticketSyncReason is the history reason of order changes that follow from tickets.
+const ticketSyncReason = "Otomatis dari status tiket booth"
+
+// applyTicketTransition moves one ticket and, in the same transaction, walks the order
+// to the status its tickets now add up to.
+func (u *orderUsecase) applyTicketTransition(order *model.Order, ticket *model.BoothTicket, status string, actor model.StatusActor, reason string) error {
+	change := repository.TicketChange{Ticket: ticket, From: ticket.Status, To: status}
+	boothID := ticket.BoothID
+	history := []model.OrderStatusHistory{{
+		OrderID:    order.ID,
+		BoothID:    &boothID,
+		Field:      model.StatusFieldTicket,
+		FromStatus: ticket.Status,
+		ToStatus:   status,
+		ActorType:  actor.Type,
+		AdminID:    actor.AdminID,
+		Reason:     reason,
+	}}
+
+	ticket.Status = status
+	path := orderPathFromTickets(order)
+	ticket.Status = change.From
+
+	var err error
+	if len(path) == 0 {
+		err = u.ticketRepo.ApplyStatusChange(ticket, status, history)
+	} else {
+		from := order.OrderStatus
+		for _, next := range path {
+			history = append(history, model.OrderStatusHistory{
+				Field:      model.StatusFieldOrder,
+				FromStatus: from,
+				ToStatus:   next,
+				ActorType:  actor.Type,
+				AdminID:    actor.AdminID,
+				Reason:     ticketSyncReason,
+			})
+			from = next
+		}
+		updates := map[string]interface{}{"order_status": from}
+		err = u.orderRepo.ApplyStatusChange(order, updates, []repository.TicketChange{change}, history)
+	}
 	if err != nil {
 		return err
 	}
 
-	type ItemDetail struct {
-		MenuName string
-		Qty      int
-		Notes    string
+	prevOrderStatus := order.OrderStatus
+	ticket.Status = status
+	if len(path) > 0 {
+		order.OrderStatus = path[len(path)-1]
+	}
+	u.publishChange(order)
+	u.notifyCustomerOfTicket(order, ticket)
+	if order.OrderStatus != prevOrderStatus {
+		u.notifyCustomerOfTransition(order, prevOrderStatus, order.PaymentStatus, ticketSyncReason)
 	}
+	return nil
+}
 
-	type GroupData struct {
-		BoothID   uint
-		BoothName string
-		BoothWA   string
-		Items     []ItemDetail
+func (u *orderUsecase) Subscribe() (<-chan OrderEvent, func()) {
+	return u.events.Subscribe()
+}
+
+// publishChange tells live screens that the order changed.
+func (u *orderUsecase) publishChange(order *model.Order) {
+	event := OrderEvent{OrderID: order.ID, OrderCode: order.OrderCode}
+	for _, ticket := range order.Tickets {
+		event.BoothIDs = append(event.BoothIDs, ticket.BoothID)
 	}
+	u.events.Publish(event)
+}
 
-	groups := make(map[uint]*GroupData)
+// orderProgression is the path a paid order walks through while its tickets are worked on.
+var orderProgression = []string{
+	model.OrderStatusConfirmed,
+	model.OrderStatusPreparing,
+	model.OrderStatusReady,
+	model.OrderStatusCompleted,
+}
 
-	for _, item := range order.Items {
+// orderPathFromTickets lists the statuses the order steps through to match its
+// tickets: preparing once any booth starts, ready only when every booth is ready, and
+// completed when every booth has handed over. Cancelled tickets are ignored, unless
+// all of them are cancelled, which cancels the order.
+func orderPathFromTickets(order *model.Order) []string {
+	active, started, readyOrDone, done := 0, 0, 0, 0
+	for _, t := range order.Tickets {
+		switch t.Status {
+		case model.TicketStatusCancelled:
+			continue
+		case model.TicketStatusPreparing:
+			started++
+		case model.TicketStatusReady:
+			started++
+			readyOrDone++
+		case model.TicketStatusCompleted:
+			started++
+			readyOrDone++
+			done++
+		}
+		active++
+	}
 
-		if _, exists := groups[item.BoothID]; !exists {
-			groups[item.BoothID] = &GroupData{
-				BoothID:   item.BoothID,
-				BoothName: item.Booth.Name,
-				BoothWA:   item.Booth.WhatsApp,
-				Items:     []ItemDetail{},
-			}
+	if active == 0 {
+		if len(order.Tickets) > 0 && model.CanTransitionOrderStatus(order.OrderStatus, model.OrderStatusCancelled) {
+			return []string{model.OrderStatusCancelled}
 		}
+		return nil
+	}
 
-		detail := ItemDetail{
-			MenuName: item.Menu.Name,
-			Qty:      item.Quantity,
-			Notes:    item.Notes,
+	target := order.OrderStatus
+	switch {
+	case done == active:
+		target = model.OrderStatusCompleted
+	case readyOrDone == active:
+		target = model.OrderStatusReady
+	case started > 0:
+		target = model.OrderStatusPreparing
+	}
+
+	current := progressionIndex(order.OrderStatus)
+	wanted := progressionIndex(target)
+	if current < 0 || wanted <= current {
+		return nil
+	}
+	return orderProgression[current+1 : wanted+1]
+}
+
+func progressionIndex(status string) int {
+	for i, s := range orderProgression {
+		if s == status {
+			return i
 		}
-		groups[item.BoothID].Items = append(groups[item.BoothID].Items, detail)
 	}
+	return -1
+}
 
-	for _, group := range groups {
-		targetWA := group.BoothWA
+func findTicket(order *model.Order, ticketID uint) *model.BoothTicket {
+	for i := range order.Tickets {
+		if order.Tickets[i].ID == ticketID {
+			return &order.Tickets[i]
+		}
+	}
+	return nil
+}
 
-		paymentStatus := "BELUM LUNAS ❌"
-		if order.PaymentStatus == "paid" {
-			paymentStatus = "SUDAH LUNAS ✅"
+// SendOrderNotificationToSeller queues the order for every booth in it and returns
+// the queued messages without waiting for delivery.
+func (u *orderUsecase) SendOrderNotificationToSeller(orderCode string) ([]model.OutboundMessage, error) {
+	order, err := u.orderRepo.FindByCode(orderCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []model.OutboundMessage
+	seen := make(map[uint]bool)
+	for _, item := range order.Items {
+		if seen[item.BoothID] {
+			continue
+		}
+		seen[item.BoothID] = true
+
+		message, err := boothMessage(order, item.BoothID)
+		if err != nil {
+			return nil, err
 		}
+		messages = append(messages, message)
+	}
+
+	return u.messages.Enqueue(messages)
+}
+
+func (u *orderUsecase) SendTicketNotification(orderCode string, ticketID uint) ([]model.OutboundMessage, error) {
+	order, err := u.orderRepo.FindByCode(orderCode)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := findTicket(order, ticketID)
+	if ticket == nil {
+		return nil, errors.New("tiket tidak ditemukan pada pesanan ini")
+	}
+
+	message, err := boothMessage(order, ticket.BoothID)
+	if err != nil {
+		return nil, err
+	}
+	return u.messages.Enqueue([]model.OutboundMessage{message})
+}
 
-		msg := fmt.Sprintf("*PESANAN MASUK!* 🔔\nKepada: *%s*\n\nOrder: *%s*\nMeja: *%s*\nPemesan: *%s*\nStatus: *%s*\n\n🍽️ *MENU:*\n",
-			group.BoothName, order.OrderCode, order.TableNumber, order.CustomerName, paymentStatus)
+// optionText renders an item's bundle and chosen options for WhatsApp messages,
+// e.g. " [Besar, Level 3]" or " [Paket Hemat]".
+func optionText(item model.OrderItem) string {
+	var parts []string
+	if item.BundleName != "" {
+		parts = append(parts, item.BundleName)
+	}
+	if len(item.Options) > 0 {
+		parts = append(parts, item.OptionSummary())
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, "; ") + "]"
+}
 
-		for _, item := range group.Items {
-			noteText := ""
-			if item.Notes != "" {
-				noteText = fmt.Sprintf(" _(%s)_", item.Notes)
-			}
-			msg += fmt.Sprintf("▪️ %dx %s%s\n", item.Qty, item.MenuName, noteText)
+// boothMessage builds the message telling one booth its part of the order, addressed
+// over the booth's notification channel.
+func boothMessage(order *model.Order, boothID uint) (model.OutboundMessage, error) {
+	var booth model.Booth
+	var items []model.OrderItem
+	for _, item := range order.Items {
+		if item.BoothID == boothID {
+			booth = item.Booth
+			items = append(items, item)
 		}
-		msg += "\nMohon segera diproses. Terima kasih! 🙏"
+	}
+	if len(items) == 0 {
+		return model.OutboundMessage{}, fmt.Errorf("tidak ada item untuk booth ID %d", boothID)
+	}
 
-		bgCtx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	channel, target := booth.NotifyAddress()
 
-		err := u.waUc.SendMessage(bgCtx, targetWA, msg)
-		cancel()
+	paymentStatus := "BELUM LUNAS ❌"
+	if order.PaymentStatus == "paid" {
+		paymentStatus = "SUDAH LUNAS ✅"
+	}
 
-		if err != nil {
-			return fmt.Errorf("gagal kirim ke %s: %v", group.BoothName, err)
+	queue := order.QueueLabel()
+	for _, ticket := range order.Tickets {
+		if ticket.BoothID == boothID && ticket.QueueNumber > 0 {
+			queue = fmt.Sprintf("%s (booth #%s)", queue, ticket.QueueLabel())
 		}
+	}
 
-		err = u.logUC.RecordLog(order.ID, group.BoothID, targetWA)
-		if err != nil {
-			fmt.Printf("⚠️ Gagal menyimpan log WA: %v\n", err)
+	msg := fmt.Sprintf("*PESANAN MASUK!* 🔔\nKepada: *%s*\n\nNo. Antrean: *%s*\nOrder: *%s*\nMeja: *%s*\nPemesan: *%s*\nStatus: *%s*\n\n🍽️ *MENU:*\n",
+		booth.Name, queue, order.OrderCode, order.TableNumber, order.CustomerName, paymentStatus)
 
+	for _, item := range items {
+		noteText := ""
+		if item.Notes != "" {
+			noteText = fmt.Sprintf(" _(%s)_", item.Notes)
 		}
+		msg += fmt.Sprintf("▪️ %dx %s%s%s\n", item.Quantity, item.Menu.Name, optionText(item), noteText)
+	}
+	msg += "\nMohon segera diproses. Terima kasih! 🙏"
+	if channel == model.NotifyChannelWhatsApp {
+		msg += fmt.Sprintf("\n\nBalas *SIAP %s* bila pesanan sudah siap.", order.OrderCode)
 	}
 
-	return nil
+	return model.OutboundMessage{
+		OrderID:   &order.ID,
+		BoothID:   &booth.ID,
+		Kind:      model.MessageKindBoothOrder,
+		Channel:   channel,
+		Recipient: target,
+		Subject:   fmt.Sprintf("Pesanan masuk %s untuk %s", order.OrderCode, booth.Name),
+		Body:      msg,
+	}, nil
 }
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+func ticketsWith(statuses ...string) []model.BoothTicket {
+	tickets := make([]model.BoothTicket, len(statuses))
+	for i, status := range statuses {
+		tickets[i] = model.BoothTicket{ID: uint(i + 1), BoothID: uint(i + 1), Status: status}
+	}
+	return tickets
+}
+
+func TestOrderPathFromTickets(t *testing.T) {
+	const (
+		pending   = model.TicketStatusPending
+		preparing = model.TicketStatusPreparing
+		ready     = model.TicketStatusReady
+		completed = model.TicketStatusCompleted
+		cancelled = model.TicketStatusCancelled
+	)
+
+	tests := []struct {
+		name    string
+		order   string
+		tickets []model.BoothTicket
+		want    []string
+	}{
+		{"nothing started", model.OrderStatusConfirmed, ticketsWith(pending, pending), nil},
+		{"one booth starts", model.OrderStatusConfirmed, ticketsWith(preparing, pending), []string{model.OrderStatusPreparing}},
+		{"one booth ready", model.OrderStatusPreparing, ticketsWith(ready, preparing), nil},
+		{"every booth ready", model.OrderStatusConfirmed, ticketsWith(ready, ready), []string{model.OrderStatusPreparing, model.OrderStatusReady}},
+		{"every booth handed over", model.OrderStatusReady, ticketsWith(completed, completed), []string{model.OrderStatusCompleted}},
+		{"cancelled ticket is ignored", model.OrderStatusPreparing, ticketsWith(ready, cancelled), []string{model.OrderStatusReady}},
+		{"every ticket cancelled", model.OrderStatusPreparing, ticketsWith(cancelled, cancelled), []string{model.OrderStatusCancelled}},
+		{"every ticket cancelled on a final order", model.OrderStatusCompleted, ticketsWith(cancelled), nil},
+		{"never moves back", model.OrderStatusReady, ticketsWith(ready, preparing), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := orderPathFromTickets(&model.Order{OrderStatus: tt.order, Tickets: tt.tickets})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderPathFromTickets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeStatusStore records the order writes; fakeTicketStore counts the writes that
+// only touch a ticket.
+type fakeStatusStore struct {
+	repository.OrderRepository
+	order *model.Order
+
+	orderUpdates []map[string]interface{}
+	ticketMoves  [][]repository.TicketChange
+	ticketOnly   int
+}
+
+func (s *fakeStatusStore) FindByCode(code string) (*model.Order, error) {
+	return s.order, nil
+}
+
+func (s *fakeStatusStore) ApplyStatusChange(order *model.Order, updates map[string]interface{}, tickets []repository.TicketChange, history []model.OrderStatusHistory) error {
+	s.orderUpdates = append(s.orderUpdates, updates)
+	s.ticketMoves = append(s.ticketMoves, tickets)
+	return nil
+}
+
+type fakeTicketStore struct {
+	repository.TicketRepository
+	store *fakeStatusStore
+}
+
+func (t fakeTicketStore) ApplyStatusChange(ticket *model.BoothTicket, status string, history []model.OrderStatusHistory) error {
+	t.store.ticketOnly++
+	return nil
+}
+
+func TestUpdateTicketStatusMovesOrderInOneChange(t *testing.T) {
+	tests := []struct {
+		name       string
+		order      string
+		tickets    []model.BoothTicket
+		ticketID   uint
+		status     string
+		wantOrder  string
+		wantSingle bool
+	}{
+		{
+			name: "ticket alone", order: model.OrderStatusPreparing,
+			tickets:  ticketsWith(model.TicketStatusPreparing, model.TicketStatusPending),
+			ticketID: 1, status: model.TicketStatusReady,
+			wantOrder: model.OrderStatusPreparing, wantSingle: true,
+		},
+		{
+			name: "last booth ready", order: model.OrderStatusPreparing,
+			tickets:  ticketsWith(model.TicketStatusReady, model.TicketStatusPreparing),
+			ticketID: 2, status: model.TicketStatusReady,
+			wantOrder: model.OrderStatusReady,
+		},
+		{
+			name: "last open ticket cancelled", order: model.OrderStatusConfirmed,
+			tickets:  ticketsWith(model.TicketStatusCancelled, model.TicketStatusPending),
+			ticketID: 2, status: model.TicketStatusCancelled,
+			wantOrder: model.OrderStatusCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &model.Order{ID: 7, OrderCode: "ORD-TEST", OrderStatus: tt.order, PaymentStatus: model.PaymentStatusPaid, Tickets: tt.tickets}
+			store := &fakeStatusStore{order: order}
+			u := NewOrderUsecase(store, nil, nil, nil, nil, fakeTicketStore{store: store}, nil, nil, nil, nil, nil)
+
+			if err := u.UpdateTicketStatus("ORD-TEST", tt.ticketID, tt.status, model.StatusActor{Type: model.ActorBooth}, "test"); err != nil {
+				t.Fatalf("UpdateTicketStatus(): %v", err)
+			}
+
+			if order.OrderStatus != tt.wantOrder {
+				t.Errorf("order status = %s, want %s", order.OrderStatus, tt.wantOrder)
+			}
+			if got := findTicket(order, tt.ticketID).Status; got != tt.status {
+				t.Errorf("ticket status = %s, want %s", got, tt.status)
+			}
+
+			if tt.wantSingle {
+				if store.ticketOnly != 1 || len(store.orderUpdates) != 0 {
+					t.Errorf("ticket-only writes = %d, order writes = %d, want 1 and 0", store.ticketOnly, len(store.orderUpdates))
+				}
+				return
+			}
+			if store.ticketOnly != 0 || len(store.orderUpdates) != 1 {
+				t.Fatalf("ticket-only writes = %d, order writes = %d, want 0 and 1", store.ticketOnly, len(store.orderUpdates))
+			}
+			if got := store.orderUpdates[0]["order_status"]; got != tt.wantOrder {
+				t.Errorf("order update = %v, want %s", got, tt.wantOrder)
+			}
+			moves := store.ticketMoves[0]
+			if len(moves) != 1 || moves[0].Ticket.ID != tt.ticketID || moves[0].To != tt.status {
+				t.Errorf("ticket changes in the order write = %+v, want ticket %d to %s", moves, tt.ticketID, tt.status)
+			}
+		})
+	}
+}
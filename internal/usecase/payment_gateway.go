@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/config"
+)
+
+// Invoice statuses shared by every gateway. They follow Xendit's naming because
+// the webhook payload uses the same values.
+const (
+	InvoiceStatusPending = "PENDING"
+	InvoiceStatusPaid    = "PAID"
+	InvoiceStatusSettled = "SETTLED"
+	InvoiceStatusExpired = "EXPIRED"
+)
+
+type InvoiceRequest struct {
+	ExternalID  string
+	Amount      int
+	Description string
+	SuccessURL  string
+	FailureURL  string
+	// Duration is how long the invoice stays payable; zero keeps the provider default.
+	Duration time.Duration
+}
+
+type PaymentInvoice struct {
+	ID         string
+	ExternalID string
+	URL        string
+	Status     string
+	Amount     int
+}
+
+// IsPaid reports whether the invoice has been paid, settled or not.
+func (i *PaymentInvoice) IsPaid() bool {
+	return i.Status == InvoiceStatusPaid || i.Status == InvoiceStatusSettled
+}
+
+type PaymentGateway interface {
+	Name() string
+	CreateInvoice(ctx context.Context, req InvoiceRequest) (*PaymentInvoice, error)
+	// FindInvoiceByExternalID returns the latest invoice for an external ID, or nil when there is none.
+	FindInvoiceByExternalID(ctx context.Context, externalID string) (*PaymentInvoice, error)
+	GetInvoice(ctx context.Context, invoiceID string) (*PaymentInvoice, error)
+	ExpireInvoice(ctx context.Context, invoiceID string) (*PaymentInvoice, error)
+}
+
+// NewPaymentGateway builds the gateway named by provider (see config.PaymentProvider).
+func NewPaymentGateway(provider string) (PaymentGateway, error) {
+	switch provider {
+	case config.PaymentProviderXendit:
+		client, err := config.NewXenditClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewXenditGateway(client), nil
+	case config.PaymentProviderMock:
+		return NewMockPaymentGateway(), nil
+	default:
+		return nil, fmt.Errorf("payment provider tidak dikenal: %s", provider)
+	}
+}
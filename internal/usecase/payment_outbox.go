@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/utils"
+)
+
+const (
+	outboxMaxAttempts  = 5
+	outboxLockDuration = 2 * time.Minute
+	outboxBatchSize    = 20
+
+	// invoiceSyncTimeout bounds how long checkout waits for the provider before
+	// handing the invoice over to the worker.
+	invoiceSyncTimeout = 10 * time.Second
+)
+
+var errOutboxBusy = errors.New("outbox sedang diproses worker lain")
+
+// RunPaymentOutbox processes due outbox records every interval until ctx is cancelled.
+func (u *orderUsecase) RunPaymentOutbox(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		records, err := u.outboxRepo.FindDue(time.Now(), outboxBatchSize)
+		if err != nil {
+			fmt.Printf("⚠️ Gagal membaca payment outbox: %v\n", err)
+		}
+
+		for _, record := range records {
+			if _, err := u.processOutbox(ctx, record); err != nil && !errors.Is(err, errOutboxBusy) {
+				fmt.Printf("⚠️ Payment outbox #%d gagal: %v\n", record.ID, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processOutboxNow runs a freshly created record inside the checkout request so the
+// customer can be redirected straight to the payment page. Anything that fails or
+// takes too long is left for the worker.
+func (u *orderUsecase) processOutboxNow(record model.PaymentOutbox) string {
+	ctx, cancel := context.WithTimeout(context.Background(), invoiceSyncTimeout)
+	defer cancel()
+
+	paymentURL, err := u.processOutbox(ctx, record)
+	if err != nil {
+		fmt.Printf("⚠️ Invoice order #%d diserahkan ke worker: %v\n", record.OrderID, err)
+		return ""
+	}
+	return paymentURL
+}
+
+// processOutbox claims one record and executes it, scheduling a retry with
+// exponential backoff on failure. It returns the payment URL when there is one.
+func (u *orderUsecase) processOutbox(ctx context.Context, record model.PaymentOutbox) (string, error) {
+	claimed, err := u.outboxRepo.Claim(record.ID, time.Now(), outboxLockDuration)
+	if err != nil {
+		return "", err
+	}
+	if !claimed {
+		return "", errOutboxBusy
+	}
+
+	var paymentURL string
+	switch record.Action {
+	case model.OutboxActionCreateInvoice:
+		paymentURL, err = u.createInvoiceFromOutbox(ctx, record)
+	default:
+		err = fmt.Errorf("aksi outbox tidak dikenal: %s", record.Action)
+	}
+
+	if err == nil {
+		return paymentURL, u.outboxRepo.MarkDone(record.ID)
+	}
+
+	attempts := record.Attempts + 1
+	if attempts >= outboxMaxAttempts {
+		if markErr := u.outboxRepo.MarkFailed(record.ID, err.Error()); markErr != nil {
+			return "", markErr
+		}
+		return "", u.cancelUnpayableOrder(record.OrderID, err)
+	}
+
+	backoff := time.Duration(1<<attempts) * 15 * time.Second
+	if markErr := u.outboxRepo.MarkRetry(record.ID, time.Now().Add(backoff), err.Error()); markErr != nil {
+		return "", markErr
+	}
+	return "", err
+}
+
+func (u *orderUsecase) createInvoiceFromOutbox(ctx context.Context, record model.PaymentOutbox) (string, error) {
+	order, err := u.orderRepo.FindByID(record.OrderID)
+	if err != nil {
+		return "", err
+	}
+
+	if order.XenditInvoiceID != "" {
+		return order.InvoiceURL, nil
+	}
+	if order.PaymentStatus != model.PaymentStatusPending || model.IsFinalOrderStatus(order.OrderStatus) {
+		return "", nil
+	}
+
+	// A previous attempt may have reached the provider before failing locally, so
+	// reuse its invoice instead of creating a second one.
+	var inv *PaymentInvoice
+	if record.Attempts > 0 {
+		inv, err = u.paymentUc.FindInvoiceByExternalID(ctx, order.OrderCode)
+		if err != nil {
+			return "", err
+		}
+		if inv != nil && inv.Status == InvoiceStatusExpired {
+			inv = nil
+		}
+	}
+
+	if inv == nil {
+		inv, err = u.paymentUc.CreateInvoice(ctx, *order)
+		if err != nil {
+			return "", fmt.Errorf("gagal membuat invoice %s: %w", u.paymentUc.Provider(), err)
+		}
+	}
+
+	if err := u.orderRepo.UpdateInvoice(order.OrderCode, inv.ID, inv.URL); err != nil {
+		return "", err
+	}
+	return inv.URL, nil
+}
+
+// cancelUnpayableOrder cancels an order whose invoice could not be created after all
+// retries, so it does not stay pending forever and its stock is released.
+func (u *orderUsecase) cancelUnpayableOrder(orderID uint, cause error) error {
+	order, err := u.orderRepo.FindByID(orderID)
+	if err != nil {
+		return err
+	}
+	if model.IsFinalOrderStatus(order.OrderStatus) || order.PaymentStatus != model.PaymentStatusPending {
+		return nil
+	}
+
+	actor := model.StatusActor{Type: model.ActorSystem}
+	reason := utils.Truncate("Invoice pembayaran gagal dibuat: "+cause.Error(), 255)
+	return u.applyTransition(order, model.OrderStatusCancelled, model.PaymentStatusExpired, actor, reason)
+}
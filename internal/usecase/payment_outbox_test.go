@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+// fakeOutboxRepo keeps outbox records in memory with the same claim rule as the
+// database version.
+type fakeOutboxRepo struct {
+	repository.PaymentOutboxRepository
+	records map[uint]*model.PaymentOutbox
+}
+
+func (r *fakeOutboxRepo) Claim(id uint, now time.Time, lockFor time.Duration) (bool, error) {
+	record := r.records[id]
+	due := record.Status == model.OutboxStatusPending && !record.NextAttemptAt.After(now)
+	stale := record.Status == model.OutboxStatusProcessing && record.LockedUntil != nil && record.LockedUntil.Before(now)
+	if !due && !stale {
+		return false, nil
+	}
+	lockedUntil := now.Add(lockFor)
+	record.Status = model.OutboxStatusProcessing
+	record.LockedUntil = &lockedUntil
+	record.Attempts++
+	return true, nil
+}
+
+func (r *fakeOutboxRepo) MarkDone(id uint) error {
+	record := r.records[id]
+	record.Status = model.OutboxStatusDone
+	record.LockedUntil = nil
+	record.LastError = ""
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkRetry(id uint, nextAttemptAt time.Time, lastError string) error {
+	record := r.records[id]
+	record.Status = model.OutboxStatusPending
+	record.NextAttemptAt = nextAttemptAt
+	record.LockedUntil = nil
+	record.LastError = lastError
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkFailed(id uint, lastError string) error {
+	record := r.records[id]
+	record.Status = model.OutboxStatusFailed
+	record.LockedUntil = nil
+	record.LastError = lastError
+	return nil
+}
+
+// fakeInvoiceOrders holds the one order an outbox record points at.
+type fakeInvoiceOrders struct {
+	repository.OrderRepository
+	order   *model.Order
+	updates []map[string]interface{}
+}
+
+func (r *fakeInvoiceOrders) FindByID(id uint) (*model.Order, error) {
+	return r.order, nil
+}
+
+func (r *fakeInvoiceOrders) UpdateInvoice(orderCode string, invoiceID string, invoiceURL string) error {
+	r.order.XenditInvoiceID = invoiceID
+	r.order.InvoiceURL = invoiceURL
+	return nil
+}
+
+func (r *fakeInvoiceOrders) ApplyStatusChange(order *model.Order, updates map[string]interface{}, tickets []repository.TicketChange, history []model.OrderStatusHistory) error {
+	r.updates = append(r.updates, updates)
+	return nil
+}
+
+// fakeGateway answers invoice calls from fixed values and counts them.
+type fakeGateway struct {
+	PaymentGateway
+	createErr error
+	existing  *PaymentInvoice
+	created   int
+	looked    int
+}
+
+func (g *fakeGateway) Name() string { return "fake" }
+
+func (g *fakeGateway) CreateInvoice(ctx context.Context, req InvoiceRequest) (*PaymentInvoice, error) {
+	g.created++
+	if g.createErr != nil {
+		return nil, g.createErr
+	}
+	return &PaymentInvoice{ID: "inv-new", ExternalID: req.ExternalID, URL: "https://pay/new", Status: InvoiceStatusPending, Amount: req.Amount}, nil
+}
+
+func (g *fakeGateway) FindInvoiceByExternalID(ctx context.Context, externalID string) (*PaymentInvoice, error) {
+	g.looked++
+	return g.existing, nil
+}
+
+func TestProcessOutbox(t *testing.T) {
+	tests := []struct {
+		name string
+		// attempts made before this run, and whether another worker holds the record.
+		attempts int
+		locked   bool
+		gateway  fakeGateway
+
+		wantErr     bool
+		wantURL     string
+		wantStatus  string
+		wantBackoff time.Duration
+		wantCreated int
+		wantCancel  bool
+	}{
+		{
+			name:       "first attempt creates the invoice",
+			wantURL:    "https://pay/new",
+			wantStatus: model.OutboxStatusDone, wantCreated: 1,
+		},
+		{
+			name:       "record held by another worker is left alone",
+			locked:     true,
+			wantErr:    true,
+			wantStatus: model.OutboxStatusProcessing,
+		},
+		{
+			name:        "failed first attempt is retried after 30 seconds",
+			gateway:     fakeGateway{createErr: errors.New("timeout")},
+			wantErr:     true,
+			wantStatus:  model.OutboxStatusPending,
+			wantBackoff: 30 * time.Second, wantCreated: 1,
+		},
+		{
+			name:        "backoff doubles with every attempt",
+			attempts:    2,
+			gateway:     fakeGateway{createErr: errors.New("timeout")},
+			wantErr:     true,
+			wantStatus:  model.OutboxStatusPending,
+			wantBackoff: 2 * time.Minute, wantCreated: 1,
+		},
+		{
+			name:       "retry reuses the invoice an earlier attempt created",
+			attempts:   1,
+			gateway:    fakeGateway{existing: &PaymentInvoice{ID: "inv-old", URL: "https://pay/old", Status: InvoiceStatusPending}},
+			wantURL:    "https://pay/old",
+			wantStatus: model.OutboxStatusDone,
+		},
+		{
+			name:        "retry ignores an expired invoice",
+			attempts:    1,
+			gateway:     fakeGateway{existing: &PaymentInvoice{ID: "inv-old", URL: "https://pay/old", Status: InvoiceStatusExpired}},
+			wantURL:     "https://pay/new",
+			wantStatus:  model.OutboxStatusDone,
+			wantCreated: 1,
+		},
+		{
+			name:        "last attempt fails the record and cancels the order",
+			attempts:    outboxMaxAttempts - 1,
+			gateway:     fakeGateway{createErr: errors.New("timeout")},
+			wantStatus:  model.OutboxStatusFailed,
+			wantCreated: 1, wantCancel: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := model.PaymentOutbox{ID: 1, OrderID: 7, Action: model.OutboxActionCreateInvoice, Status: model.OutboxStatusPending, Attempts: tt.attempts}
+			if tt.locked {
+				lockedUntil := time.Now().Add(time.Minute)
+				record.Status = model.OutboxStatusProcessing
+				record.LockedUntil = &lockedUntil
+			}
+			stored := record
+			outbox := &fakeOutboxRepo{records: map[uint]*model.PaymentOutbox{1: &stored}}
+			orders := &fakeInvoiceOrders{order: &model.Order{
+				ID: 7, OrderCode: "ORD-TEST", TotalAmount: 30000,
+				OrderStatus: model.OrderStatusPending, PaymentStatus: model.PaymentStatusPending,
+			}}
+			gateway := tt.gateway
+			u := NewOrderUsecase(orders, nil, nil, nil, nil, nil, outbox, nil, nil, NewPaymentService(&gateway), nil)
+
+			before := time.Now()
+			url, err := u.processOutbox(context.Background(), record)
+			after := time.Now()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processOutbox() error = %v, want error %t", err, tt.wantErr)
+			}
+			if tt.locked && !errors.Is(err, errOutboxBusy) {
+				t.Errorf("processOutbox() error = %v, want %v", err, errOutboxBusy)
+			}
+			if url != tt.wantURL {
+				t.Errorf("payment URL = %q, want %q", url, tt.wantURL)
+			}
+			if stored.Status != tt.wantStatus {
+				t.Errorf("record status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+			if gateway.created != tt.wantCreated {
+				t.Errorf("created %d invoices, want %d", gateway.created, tt.wantCreated)
+			}
+			if tt.wantURL != "" && orders.order.InvoiceURL != tt.wantURL {
+				t.Errorf("order invoice URL = %q, want %q", orders.order.InvoiceURL, tt.wantURL)
+			}
+
+			if tt.wantBackoff > 0 {
+				if stored.NextAttemptAt.Before(before.Add(tt.wantBackoff)) || stored.NextAttemptAt.After(after.Add(tt.wantBackoff)) {
+					t.Errorf("next attempt in %s, want %s", stored.NextAttemptAt.Sub(before), tt.wantBackoff)
+				}
+				if stored.LastError == "" {
+					t.Error("retry did not record the error")
+				}
+			}
+
+			if !tt.wantCancel {
+				if len(orders.updates) != 0 {
+					t.Errorf("order changed: %v", orders.updates)
+				}
+				return
+			}
+			if len(orders.updates) != 1 ||
+				orders.updates[0]["order_status"] != model.OrderStatusCancelled ||
+				orders.updates[0]["payment_status"] != model.PaymentStatusExpired {
+				t.Errorf("order updates = %v, want cancelled and expired", orders.updates)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+)
+
+const (
+	// reconcileMinAge leaves fresh orders to their webhook before polling the provider.
+	reconcileMinAge      = 2 * time.Minute
+	reconcileBatchSize   = 50
+	reconcileCallTimeout = 15 * time.Second
+	reconcileReportSize  = 10
+)
+
+var errNoInvoice = errors.New("order belum memiliki invoice pembayaran")
+
+type reconcileRun struct {
+	At        time.Time
+	Checked   int
+	Corrected int
+	LastError string
+}
+
+// RunPaymentReconciliation polls the provider for orders still waiting for payment
+// every interval until ctx is cancelled, covering webhooks that never arrived.
+func (u *orderUsecase) RunPaymentReconciliation(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		u.reconcilePendingPayments(ctx)
+	}
+}
+
+func (u *orderUsecase) reconcilePendingPayments(ctx context.Context) {
+	run := reconcileRun{At: time.Now()}
+	defer func() {
+		u.reconcileMu.Lock()
+		u.lastReconcile = &run
+		u.reconcileMu.Unlock()
+	}()
+
+	orders, err := u.orderRepo.FindAwaitingPayment(time.Now().Add(-reconcileMinAge), reconcileBatchSize)
+	if err != nil {
+		run.LastError = err.Error()
+		fmt.Printf("⚠️ Gagal membaca order untuk rekonsiliasi: %v\n", err)
+		return
+	}
+
+	actor := model.StatusActor{Type: model.ActorSystem}
+	for i := range orders {
+		run.Checked++
+
+		event, err := u.reconcileOrder(ctx, &orders[i], model.PaymentEventSourceReconcile, actor)
+		if err != nil {
+			run.LastError = fmt.Sprintf("%s: %v", orders[i].OrderCode, err)
+			fmt.Printf("⚠️ Rekonsiliasi %s gagal: %v\n", orders[i].OrderCode, err)
+			continue
+		}
+		if event != nil && isCorrection(event) {
+			run.Corrected++
+		}
+	}
+
+	if run.Corrected > 0 {
+		fmt.Printf("🔁 Rekonsiliasi: %d dari %d order diperbaiki\n", run.Corrected, run.Checked)
+	}
+}
+
+// RecheckPayment asks the provider for the current invoice status of one order on
+// behalf of an admin. It returns the recorded event, or nil while the invoice is
+// still unpaid.
+func (u *orderUsecase) RecheckPayment(orderCode string, actor model.StatusActor) (*model.PaymentEvent, error) {
+	order, err := u.orderRepo.FindByCode(orderCode)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileCallTimeout)
+	defer cancel()
+
+	return u.reconcileOrder(ctx, order, model.PaymentEventSourceRecheck, actor)
+}
+
+// reconcileOrder fetches the order's invoice and feeds any settled status through
+// the same path as a webhook.
+func (u *orderUsecase) reconcileOrder(ctx context.Context, order *model.Order, source string, actor model.StatusActor) (*model.PaymentEvent, error) {
+	if order.XenditInvoiceID == "" {
+		return nil, errNoInvoice
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, reconcileCallTimeout)
+	defer cancel()
+
+	inv, err := u.paymentUc.GetInvoice(callCtx, order.XenditInvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if inv.Status == InvoiceStatusPending {
+		return nil, nil
+	}
+
+	payload, _ := json.Marshal(inv)
+	provider := u.paymentUc.Provider()
+	event := &model.PaymentEvent{
+		Provider:   provider,
+		Source:     source,
+		DedupeKey:  paymentDedupeKey(provider, inv.ID, inv.Status),
+		InvoiceID:  inv.ID,
+		ExternalID: order.OrderCode,
+		Status:     inv.Status,
+		Amount:     inv.Amount,
+		Payload:    string(payload),
+	}
+
+	if err := u.processPaymentEvent(event, actor); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func isCorrection(event *model.PaymentEvent) bool {
+	return event.Source != model.PaymentEventSourceWebhook &&
+		(event.Outcome == model.PaymentEventApplied || event.Outcome == model.PaymentEventAmountMismatch)
+}
+
+// ReconciliationReport summarises the last worker run and the latest events found
+// by reconciliation for the dashboard.
+func (u *orderUsecase) ReconciliationReport() (*dto.ReconciliationReport, error) {
+	events, err := u.eventRepo.FindRecentBySource(
+		[]string{model.PaymentEventSourceReconcile, model.PaymentEventSourceRecheck},
+		reconcileReportSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.ReconciliationReport{Events: events}
+
+	u.reconcileMu.Lock()
+	if run := u.lastReconcile; run != nil {
+		report.LastRunAt = run.At
+		report.Checked = run.Checked
+		report.Corrected = run.Corrected
+		report.LastError = run.LastError
+	}
+	u.reconcileMu.Unlock()
+
+	return report, nil
+}
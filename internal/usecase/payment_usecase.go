@@ -3,48 +3,65 @@ package usecase
 import (
 	"context"
 	"fmt"
-	"os"
+	"strings"
 
 	"github.com/Rakhulsr/foodcourt/config"
 	"github.com/Rakhulsr/foodcourt/internal/model"
-	"github.com/xendit/xendit-go/v7/invoice"
 )
 
-type PaymentUsecase struct{}
+type PaymentUsecase struct {
+	gateway PaymentGateway
+}
 
-func NewPaymentService() *PaymentUsecase {
-	return &PaymentUsecase{}
+func NewPaymentService(gateway PaymentGateway) *PaymentUsecase {
+	return &PaymentUsecase{gateway: gateway}
 }
 
-func (s *PaymentUsecase) CreateInvoice(order model.Order) (*invoice.Invoice, error) {
+func (s *PaymentUsecase) Provider() string {
+	return s.gateway.Name()
+}
 
-	baseURL := os.Getenv("BASE_URL")
-	if baseURL == "" {
-		baseURL = "http://localhost:8080"
-	}
+func (s *PaymentUsecase) CreateInvoice(ctx context.Context, order model.Order) (*PaymentInvoice, error) {
 
-	successURL := fmt.Sprintf("%s/order/success/%s", baseURL, order.OrderCode)
-	failURL := fmt.Sprintf("%s/cart", baseURL)
+	baseURL := appBaseURL()
 
-	createInvoiceRequest := *invoice.NewCreateInvoiceRequest(
-		order.OrderCode,
-		float64(order.TotalAmount),
-	)
+	return s.gateway.CreateInvoice(ctx, InvoiceRequest{
+		ExternalID:  order.OrderCode,
+		Amount:      order.TotalAmount,
+		Description: invoiceDescription(order),
+		SuccessURL:  baseURL + order.SuccessPath(),
+		FailureURL:  fmt.Sprintf("%s/cart", baseURL),
+		Duration:    config.OrderExpiry(order.PaymentMethod),
+	})
+}
 
-	createInvoiceRequest.SetDescription(fmt.Sprintf("Pembayaran Order %s - %s", order.OrderCode, order.CustomerName))
-	createInvoiceRequest.SetSuccessRedirectUrl(successURL)
-	createInvoiceRequest.SetFailureRedirectUrl(failURL)
-	createInvoiceRequest.SetCurrency("IDR")
-	createInvoiceRequest.SetReminderTime(1)
+// invoiceDescription names the order and lists how its amount is made up, so the
+// fees show on the invoice the customer pays.
+func invoiceDescription(order model.Order) string {
+	parts := []string{fmt.Sprintf("Subtotal Rp %d", order.Subtotal)}
+	if order.DiscountAmount > 0 {
+		parts = append(parts, fmt.Sprintf("Diskon -Rp %d", order.DiscountAmount))
+	}
+	for _, charge := range order.Charges {
+		parts = append(parts, fmt.Sprintf("%s Rp %d", charge.Label, charge.Amount))
+	}
+	reference := order.OrderCode
+	if queue := order.QueueLabel(); queue != "" {
+		reference += " / Antrean " + queue
+	}
+	return fmt.Sprintf("Pembayaran Order %s - %s (%s)", reference, order.CustomerName, strings.Join(parts, ", "))
+}
 
-	resp, _, err := config.XenditClient.InvoiceApi.
-		CreateInvoice(context.Background()).
-		CreateInvoiceRequest(createInvoiceRequest).
-		Execute()
+// FindInvoiceByExternalID returns the latest invoice created for an order code,
+// or nil when the provider has none.
+func (s *PaymentUsecase) FindInvoiceByExternalID(ctx context.Context, externalID string) (*PaymentInvoice, error) {
+	return s.gateway.FindInvoiceByExternalID(ctx, externalID)
+}
 
-	if err != nil {
-		return nil, err
-	}
+func (s *PaymentUsecase) GetInvoice(ctx context.Context, invoiceID string) (*PaymentInvoice, error) {
+	return s.gateway.GetInvoice(ctx, invoiceID)
+}
 
-	return resp, nil
+func (s *PaymentUsecase) ExpireInvoice(ctx context.Context, invoiceID string) (*PaymentInvoice, error) {
+	return s.gateway.ExpireInvoice(ctx, invoiceID)
 }
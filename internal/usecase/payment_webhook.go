@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCallbackToken   = errors.New("callback token tidak valid")
+	ErrInvalidCallbackPayload = errors.New("payload callback tidak valid")
+)
+
+// ProcessXenditCallback verifies a payment callback and hands it to processPaymentEvent.
+func (u *orderUsecase) ProcessXenditCallback(token string, body []byte) error {
+	if err := verifyCallbackToken(token); err != nil {
+		fmt.Printf("⛔ Callback Xendit ditolak: %v\n", err)
+		return err
+	}
+
+	var payload dto.XenditCallbackRequest
+	if err := json.Unmarshal(body, &payload); err != nil || payload.ExternalID == "" || payload.Status == "" {
+		return ErrInvalidCallbackPayload
+	}
+
+	reference := payload.ID
+	if reference == "" {
+		reference = payload.ExternalID
+	}
+
+	provider := u.paymentUc.Provider()
+	status := strings.ToUpper(payload.Status)
+	event := &model.PaymentEvent{
+		Provider:   provider,
+		Source:     model.PaymentEventSourceWebhook,
+		DedupeKey:  paymentDedupeKey(provider, reference, status),
+		InvoiceID:  payload.ID,
+		ExternalID: payload.ExternalID,
+		Status:     status,
+		Amount:     int(payload.Amount),
+		PaidAmount: int(payload.PaidAmount),
+		Payload:    string(body),
+	}
+
+	return u.processPaymentEvent(event, model.StatusActor{Type: model.ActorXendit})
+}
+
+// paymentDedupeKey identifies one status of one invoice, whichever way it was learned.
+// A webhook and a reconciliation that report the same thing share the key.
+func paymentDedupeKey(provider string, invoiceRef string, status string) string {
+	return fmt.Sprintf("%s:%s:%s", provider, invoiceRef, status)
+}
+
+// processPaymentEvent records the event and applies it to its order at most once.
+// Events that fail before an outcome is stored stay in "received" state, so a later
+// retry processes them again. The stored outcome is written back into event.
+func (u *orderUsecase) processPaymentEvent(event *model.PaymentEvent, actor model.StatusActor) error {
+	event.Outcome = model.PaymentEventReceived
+
+	created, err := u.eventRepo.Record(event)
+	if err != nil {
+		return err
+	}
+	if !created && event.Outcome != model.PaymentEventReceived {
+		fmt.Printf("ℹ️ Event pembayaran %s sudah diproses (%s)\n", event.DedupeKey, event.Outcome)
+		return nil
+	}
+
+	outcome, note, err := u.applyPaymentEvent(event, actor)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("💳 Event pembayaran %s %s (%s): %s %s\n", event.ExternalID, event.Status, event.Source, outcome, note)
+	if err := u.eventRepo.SetOutcome(event.ID, event.OrderID, outcome, note); err != nil {
+		return err
+	}
+
+	event.Outcome, event.Note = outcome, note
+	return nil
+}
+
+func verifyCallbackToken(token string) error {
+	expected := os.Getenv("XENDIT_CALLBACK_TOKEN")
+	if expected == "" {
+		// Without a configured token only local development may skip verification.
+		if os.Getenv("APP_ENV") == "development" {
+			return nil
+		}
+		return fmt.Errorf("%w: XENDIT_CALLBACK_TOKEN belum diatur", ErrInvalidCallbackToken)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return ErrInvalidCallbackToken
+	}
+	return nil
+}
+
+// paymentEventRank orders provider statuses. An event whose rank is not higher than
+// the last applied one is stale: PAID and EXPIRED exclude each other, and only
+// SETTLED may follow PAID.
+func paymentEventRank(status string) int {
+	switch status {
+	case InvoiceStatusPaid, InvoiceStatusExpired:
+		return 1
+	case InvoiceStatusSettled:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// applyPaymentEvent decides what a recorded event does to its order and returns the
+// outcome to store. Only unexpected errors are returned as err.
+func (u *orderUsecase) applyPaymentEvent(event *model.PaymentEvent, actor model.StatusActor) (string, string, error) {
+	order, err := u.orderRepo.FindByCode(event.ExternalID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.PaymentEventUnknownOrder, "order tidak ditemukan", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	event.OrderID = &order.ID
+
+	if order.PaymentMethod != "qris" {
+		return model.PaymentEventIgnored, "order tidak dibayar lewat QRIS", nil
+	}
+
+	var orderStatus, paymentStatus, note string
+	switch event.Status {
+	case InvoiceStatusPaid, InvoiceStatusSettled:
+		paid := event.PaidAmount
+		if paid == 0 {
+			paid = event.Amount
+		}
+
+		if paid != order.TotalAmount {
+			note = fmt.Sprintf("dibayar %d, tagihan %d", paid, order.TotalAmount)
+			if err := u.flagPayment(order, model.PaymentFlagAmountMismatch); err != nil {
+				return "", "", err
+			}
+			// An underpaid order must not be treated as paid.
+			if paid < order.TotalAmount {
+				return model.PaymentEventAmountMismatch, note, nil
+			}
+		}
+
+		if order.PaymentStatus == model.PaymentStatusExpired {
+			if err := u.flagPayment(order, model.PaymentFlagPaidAfterClose); err != nil {
+				return "", "", err
+			}
+			return model.PaymentEventStale, "pembayaran masuk setelah order ditutup", nil
+		}
+
+		orderStatus, paymentStatus = model.OrderStatusPreparing, model.PaymentStatusPaid
+	case InvoiceStatusExpired:
+		if event.InvoiceID != "" && order.XenditInvoiceID != "" && event.InvoiceID != order.XenditInvoiceID {
+			return model.PaymentEventIgnored, "invoice lama kedaluwarsa", nil
+		}
+		orderStatus, paymentStatus = model.OrderStatusCancelled, model.PaymentStatusExpired
+	default:
+		return model.PaymentEventIgnored, "status tidak ditangani", nil
+	}
+
+	latest, err := u.eventRepo.FindLatestApplied(order.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", "", err
+	}
+	if latest != nil && paymentEventRank(event.Status) <= paymentEventRank(latest.Status) {
+		return model.PaymentEventStale, "sudah diproses event " + latest.Status, nil
+	}
+
+	if order.OrderStatus != model.OrderStatusPending {
+		orderStatus = order.OrderStatus
+	}
+
+	if orderStatus == model.OrderStatusCancelled {
+		err = u.markExpired(order, actor, paymentEventReason(event))
+	} else {
+		err = u.applyTransition(order, orderStatus, paymentStatus, actor, paymentEventReason(event))
+	}
+	if errors.Is(err, errInvalidTransition) {
+		return model.PaymentEventStale, err.Error(), nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return model.PaymentEventApplied, note, nil
+}
+
+func (u *orderUsecase) flagPayment(order *model.Order, flag string) error {
+	if order.PaymentFlag == flag {
+		return nil
+	}
+	fmt.Printf("🚩 Order %s ditandai: %s\n", order.OrderCode, flag)
+	order.PaymentFlag = flag
+	return u.orderRepo.SetPaymentFlag(order.ID, flag)
+}
+
+func paymentEventReason(event *model.PaymentEvent) string {
+	switch event.Source {
+	case model.PaymentEventSourceReconcile:
+		return "Rekonsiliasi pembayaran: " + event.Status
+	case model.PaymentEventSourceRecheck:
+		return "Cek ulang pembayaran: " + event.Status
+	default:
+		return "Callback Xendit: " + event.Status
+	}
+}
@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeEventRepo stores payment events by dedupe key like the unique index does.
+type fakeEventRepo struct {
+	repository.PaymentEventRepository
+	events []*model.PaymentEvent
+}
+
+func (r *fakeEventRepo) Record(event *model.PaymentEvent) (bool, error) {
+	for _, stored := range r.events {
+		if stored.DedupeKey == event.DedupeKey {
+			*event = *stored
+			return false, nil
+		}
+	}
+	event.ID = uint(len(r.events) + 1)
+	stored := *event
+	r.events = append(r.events, &stored)
+	return true, nil
+}
+
+func (r *fakeEventRepo) FindLatestApplied(orderID uint) (*model.PaymentEvent, error) {
+	for i := len(r.events) - 1; i >= 0; i-- {
+		event := r.events[i]
+		if event.OrderID != nil && *event.OrderID == orderID && event.Outcome == model.PaymentEventApplied {
+			copied := *event
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeEventRepo) SetOutcome(id uint, orderID *uint, outcome string, note string) error {
+	for _, event := range r.events {
+		if event.ID == id {
+			event.OrderID, event.Outcome, event.Note = orderID, outcome, note
+		}
+	}
+	return nil
+}
+
+// fakePaymentOrders holds the one order callbacks are about.
+type fakePaymentOrders struct {
+	repository.OrderRepository
+	order  *model.Order
+	writes []map[string]interface{}
+}
+
+func (r *fakePaymentOrders) FindByCode(code string) (*model.Order, error) {
+	if r.order == nil || r.order.OrderCode != code {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.order, nil
+}
+
+func (r *fakePaymentOrders) SetPaymentFlag(orderID uint, flag string) error {
+	return nil
+}
+
+func (r *fakePaymentOrders) ApplyStatusChange(order *model.Order, updates map[string]interface{}, tickets []repository.TicketChange, history []model.OrderStatusHistory) error {
+	r.writes = append(r.writes, updates)
+	return nil
+}
+
+func callbackBody(status string, paid int) []byte {
+	return []byte(fmt.Sprintf(`{"id":"inv-1","external_id":"ORD-TEST","status":%q,"amount":30000,"paid_amount":%d}`, status, paid))
+}
+
+func TestProcessXenditCallback(t *testing.T) {
+	const token = "rahasia"
+
+	tests := []struct {
+		name        string
+		tokenEnv    string
+		token       string
+		orderStatus string
+		payment     string
+		// prior events are already stored when the callback arrives.
+		prior  []model.PaymentEvent
+		status string
+		paid   int
+		// sends is how often the provider delivers the callback; 0 means once.
+		sends int
+
+		wantErr     error
+		wantOutcome string
+		wantFlag    string
+		wantWrites  int
+		wantStatus  string
+		wantPayment string
+	}{
+		{
+			name: "wrong token is rejected", tokenEnv: token, token: "salah",
+			status: InvoiceStatusPaid, paid: 30000,
+			wantErr: ErrInvalidCallbackToken,
+		},
+		{
+			name: "missing token setting rejects every callback", token: token,
+			status: InvoiceStatusPaid, paid: 30000,
+			wantErr: ErrInvalidCallbackToken,
+		},
+		{
+			name: "paid in full confirms the order", tokenEnv: token, token: token,
+			status: InvoiceStatusPaid, paid: 30000,
+			wantOutcome: model.PaymentEventApplied, wantWrites: 1,
+			wantStatus: model.OrderStatusPreparing, wantPayment: model.PaymentStatusPaid,
+		},
+		{
+			name: "repeated callback is applied once", tokenEnv: token, token: token,
+			status: InvoiceStatusPaid, paid: 30000, sends: 3,
+			wantOutcome: model.PaymentEventApplied, wantWrites: 1,
+			wantStatus: model.OrderStatusPreparing, wantPayment: model.PaymentStatusPaid,
+		},
+		{
+			name: "callback that failed before its outcome is processed again", tokenEnv: token, token: token,
+			prior:  []model.PaymentEvent{{DedupeKey: "fake:inv-1:PAID", InvoiceID: "inv-1", ExternalID: "ORD-TEST", Status: InvoiceStatusPaid, Amount: 30000, PaidAmount: 30000, Outcome: model.PaymentEventReceived}},
+			status: InvoiceStatusPaid, paid: 30000,
+			wantOutcome: model.PaymentEventApplied, wantWrites: 1,
+			wantStatus: model.OrderStatusPreparing, wantPayment: model.PaymentStatusPaid,
+		},
+		{
+			name: "underpayment is flagged and not applied", tokenEnv: token, token: token,
+			status: InvoiceStatusPaid, paid: 25000,
+			wantOutcome: model.PaymentEventAmountMismatch, wantFlag: model.PaymentFlagAmountMismatch,
+			wantStatus: model.OrderStatusPending, wantPayment: model.PaymentStatusPending,
+		},
+		{
+			name: "overpayment is flagged and applied", tokenEnv: token, token: token,
+			status: InvoiceStatusPaid, paid: 35000,
+			wantOutcome: model.PaymentEventApplied, wantFlag: model.PaymentFlagAmountMismatch, wantWrites: 1,
+			wantStatus: model.OrderStatusPreparing, wantPayment: model.PaymentStatusPaid,
+		},
+		{
+			name: "payment after the order was cancelled is flagged, not applied", tokenEnv: token, token: token,
+			orderStatus: model.OrderStatusCancelled, payment: model.PaymentStatusExpired,
+			status: InvoiceStatusPaid, paid: 30000,
+			wantOutcome: model.PaymentEventStale, wantFlag: model.PaymentFlagPaidAfterClose,
+			wantStatus: model.OrderStatusCancelled, wantPayment: model.PaymentStatusExpired,
+		},
+		{
+			name: "late paid after the expiry callback is flagged, not applied", tokenEnv: token, token: token,
+			prior:       []model.PaymentEvent{{DedupeKey: "fake:inv-1:EXPIRED", InvoiceID: "inv-1", ExternalID: "ORD-TEST", Status: InvoiceStatusExpired, Outcome: model.PaymentEventApplied}},
+			orderStatus: model.OrderStatusCancelled, payment: model.PaymentStatusExpired,
+			status: InvoiceStatusPaid, paid: 30000,
+			wantOutcome: model.PaymentEventStale, wantFlag: model.PaymentFlagPaidAfterClose,
+			wantStatus: model.OrderStatusCancelled, wantPayment: model.PaymentStatusExpired,
+		},
+		{
+			name: "expiry cancels a pending order", tokenEnv: token, token: token,
+			status: InvoiceStatusExpired, paid: 0,
+			wantOutcome: model.PaymentEventApplied, wantWrites: 1,
+			wantStatus: model.OrderStatusCancelled, wantPayment: model.PaymentStatusExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XENDIT_CALLBACK_TOKEN", tt.tokenEnv)
+			t.Setenv("APP_ENV", "production")
+
+			order := &model.Order{
+				ID: 7, OrderCode: "ORD-TEST", PaymentMethod: "qris", TotalAmount: 30000,
+				OrderStatus: model.OrderStatusPending, PaymentStatus: model.PaymentStatusPending,
+				XenditInvoiceID: "inv-1",
+			}
+			if tt.orderStatus != "" {
+				order.OrderStatus, order.PaymentStatus = tt.orderStatus, tt.payment
+			}
+			orders := &fakePaymentOrders{order: order}
+			events := &fakeEventRepo{}
+			for _, prior := range tt.prior {
+				prior.OrderID = &order.ID
+				events.Record(&prior)
+			}
+			u := NewOrderUsecase(orders, nil, nil, nil, nil, nil, nil, nil, events, NewPaymentService(&fakeGateway{}), nil)
+
+			sends := tt.sends
+			if sends == 0 {
+				sends = 1
+			}
+			for i := 0; i < sends; i++ {
+				err := u.ProcessXenditCallback(tt.token, callbackBody(tt.status, tt.paid))
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("ProcessXenditCallback() error = %v, want %v", err, tt.wantErr)
+					}
+					if len(events.events) != len(tt.prior) {
+						t.Errorf("rejected callback was recorded")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("ProcessXenditCallback(): %v", err)
+				}
+			}
+
+			var event *model.PaymentEvent
+			seen := map[string]bool{}
+			for _, stored := range events.events {
+				if seen[stored.DedupeKey] {
+					t.Errorf("dedupe key %s stored twice", stored.DedupeKey)
+				}
+				seen[stored.DedupeKey] = true
+				if stored.DedupeKey == "fake:inv-1:"+tt.status {
+					event = stored
+				}
+			}
+			if event == nil {
+				t.Fatal("no event stored under the callback's dedupe key")
+			}
+			if event.Outcome != tt.wantOutcome {
+				t.Errorf("outcome = %s (%s), want %s", event.Outcome, event.Note, tt.wantOutcome)
+			}
+			if order.PaymentFlag != tt.wantFlag {
+				t.Errorf("payment flag = %q, want %q", order.PaymentFlag, tt.wantFlag)
+			}
+			if len(orders.writes) != tt.wantWrites {
+				t.Errorf("order written %d times, want %d", len(orders.writes), tt.wantWrites)
+			}
+			if order.OrderStatus != tt.wantStatus || order.PaymentStatus != tt.wantPayment {
+				t.Errorf("order = %s/%s, want %s/%s", order.OrderStatus, order.PaymentStatus, tt.wantStatus, tt.wantPayment)
+			}
+		})
+	}
+}
+
+func TestPaymentDedupeKey(t *testing.T) {
+	webhook := paymentDedupeKey("xendit", "inv-1", InvoiceStatusPaid)
+	if webhook != "xendit:inv-1:PAID" {
+		t.Errorf("paymentDedupeKey() = %q", webhook)
+	}
+	if webhook == paymentDedupeKey("xendit", "inv-1", InvoiceStatusSettled) {
+		t.Error("PAID and SETTLED of one invoice share a dedupe key")
+	}
+	if webhook == paymentDedupeKey("xendit", "inv-2", InvoiceStatusPaid) {
+		t.Error("two invoices share a dedupe key")
+	}
+}
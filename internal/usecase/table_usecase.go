@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+	"github.com/Rakhulsr/foodcourt/utils"
+)
+
+// defaultTableZone groups tables that were not given a zone.
+const defaultTableZone = "Umum"
+
+type TableUseCase interface {
+	ListAll() ([]model.Table, error)
+	ListActive() ([]model.Table, error)
+	Create(req dto.TableRequest) error
+	Update(id uint, req dto.TableRequest) error
+	ToggleActive(id uint) error
+	Delete(id uint) error
+
+	Board() ([]dto.TableZone, error)
+
+	// Link is the signed URL printed as the table's QR code.
+	Link(table model.Table) string
+	QRCode(id uint, format string) ([]byte, error)
+	// Scan checks a scanned table link and returns the table with the value that
+	// locks it in the customer's cookie.
+	Scan(code string, signature string) (*model.Table, string, error)
+	// LockedTable returns the table a lock value from Scan points at.
+	LockedTable(lock string) (*model.Table, error)
+}
+
+type tableUseCase struct {
+	repo      repository.TableRepository
+	orderRepo repository.OrderRepository
+	secret    []byte
+}
+
+// NewTableUseCase signs and checks table links with secret (see config.TableLinkSecret).
+func NewTableUseCase(repo repository.TableRepository, or repository.OrderRepository, secret []byte) *tableUseCase {
+	return &tableUseCase{repo: repo, orderRepo: or, secret: secret}
+}
+
+func (u *tableUseCase) ListAll() ([]model.Table, error) {
+	return u.repo.FindAll()
+}
+
+func (u *tableUseCase) ListActive() ([]model.Table, error) {
+	return u.repo.FindActive()
+}
+
+func (u *tableUseCase) Create(req dto.TableRequest) error {
+	table := &model.Table{IsActive: true}
+	if err := applyTableRequest(table, req); err != nil {
+		return err
+	}
+
+	if _, err := u.repo.FindByCode(table.Code); err == nil {
+		return fmt.Errorf("meja %s sudah ada", table.Code)
+	}
+	return u.repo.Create(table)
+}
+
+func (u *tableUseCase) Update(id uint, req dto.TableRequest) error {
+	table, err := u.repo.FindByID(id)
+	if err != nil {
+		return errors.New("meja tidak ditemukan")
+	}
+	if err := applyTableRequest(table, req); err != nil {
+		return err
+	}
+
+	if existing, err := u.repo.FindByCode(table.Code); err == nil && existing.ID != id {
+		return fmt.Errorf("meja %s sudah ada", table.Code)
+	}
+	return u.repo.Update(table)
+}
+
+func (u *tableUseCase) ToggleActive(id uint) error {
+	table, err := u.repo.FindByID(id)
+	if err != nil {
+		return errors.New("meja tidak ditemukan")
+	}
+	table.IsActive = !table.IsActive
+	return u.repo.Update(table)
+}
+
+func (u *tableUseCase) Delete(id uint) error {
+	return u.repo.Delete(id)
+}
+
+// Board lists the active tables per zone with the dine-in orders still open at each.
+func (u *tableUseCase) Board() ([]dto.TableZone, error) {
+	tables, err := u.repo.FindActive()
+	if err != nil {
+		return nil, err
+	}
+	orders, err := u.orderRepo.FindOpenDineIn()
+	if err != nil {
+		return nil, err
+	}
+
+	byTable := make(map[uint][]model.Order)
+	for _, order := range orders {
+		byTable[*order.TableID] = append(byTable[*order.TableID], order)
+	}
+
+	var zones []dto.TableZone
+	index := make(map[string]int)
+	for _, table := range tables {
+		name := table.Zone
+		if name == "" {
+			name = defaultTableZone
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(zones)
+			index[name] = i
+			zones = append(zones, dto.TableZone{Zone: name})
+		}
+
+		status := dto.TableStatus{Table: table, Orders: byTable[table.ID]}
+		if status.IsOccupied() {
+			zones[i].Occupied++
+		}
+		zones[i].Tables = append(zones[i].Tables, status)
+	}
+	return zones, nil
+}
+
+func (u *tableUseCase) Link(table model.Table) string {
+	return fmt.Sprintf("%s/t/%s?sig=%s", appBaseURL(), url.PathEscape(table.Code), u.signCode(table.Code))
+}
+
+func (u *tableUseCase) QRCode(id uint, format string) ([]byte, error) {
+	table, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("meja tidak ditemukan")
+	}
+
+	switch format {
+	case "png":
+		return utils.QRPNG(u.Link(*table), 8)
+	case "svg":
+		return utils.QRSVG(u.Link(*table))
+	}
+	return nil, fmt.Errorf("format QR %s tidak didukung", format)
+}
+
+func (u *tableUseCase) Scan(code string, signature string) (*model.Table, string, error) {
+	code = model.NormalizeTableCode(code)
+	if !hmac.Equal([]byte(signature), []byte(u.signCode(code))) {
+		return nil, "", errors.New("QR meja tidak valid, silakan scan ulang")
+	}
+
+	table, err := resolveTable(u.repo, code)
+	if err != nil {
+		return nil, "", err
+	}
+	return table, table.Code + "." + signature, nil
+}
+
+func (u *tableUseCase) LockedTable(lock string) (*model.Table, error) {
+	code, signature, ok := strings.Cut(lock, ".")
+	if !ok {
+		return nil, errors.New("silakan scan QR di meja Anda untuk makan di sini")
+	}
+	table, _, err := u.Scan(code, signature)
+	return table, err
+}
+
+// signCode returns the signature that proves a table link was printed by us.
+func (u *tableUseCase) signCode(code string) string {
+	mac := hmac.New(sha256.New, u.secret)
+	mac.Write([]byte("table:" + code))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// resolveTable finds the active table a dine-in order is for.
+func resolveTable(repo repository.TableRepository, code string) (*model.Table, error) {
+	normalized := model.NormalizeTableCode(code)
+	if normalized == "" {
+		return nil, errors.New("nomor meja wajib diisi untuk makan di sini")
+	}
+
+	table, err := repo.FindByCode(normalized)
+	if err != nil || !table.IsActive {
+		return nil, fmt.Errorf("meja %s tidak ditemukan", normalized)
+	}
+	return table, nil
+}
+
+// applyTableRequest validates the admin form and copies it onto the table.
+func applyTableRequest(t *model.Table, req dto.TableRequest) error {
+	code := model.NormalizeTableCode(req.Code)
+	if code == "" || len(code) > 10 {
+		return errors.New("kode meja harus 1-10 huruf atau angka")
+	}
+	if req.Capacity < 0 {
+		return errors.New("kapasitas meja tidak boleh negatif")
+	}
+
+	t.Code = code
+	t.Zone = strings.TrimSpace(req.Zone)
+	t.Capacity = req.Capacity
+	return nil
+}
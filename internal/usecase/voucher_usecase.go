@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+const voucherTimeLayout = "2006-01-02T15:04"
+
+type VoucherUseCase interface {
+	ListAll() ([]model.Voucher, error)
+	GetByID(id uint) (*model.Voucher, error)
+	Create(req dto.VoucherRequest) error
+	Update(id uint, req dto.VoucherRequest) error
+	ToggleActive(id uint) error
+	Delete(id uint) error
+
+	Report(recentLimit int) (*dto.VoucherReport, error)
+}
+
+type voucherUseCase struct {
+	repo repository.VoucherRepository
+}
+
+func NewVoucherUseCase(repo repository.VoucherRepository) *voucherUseCase {
+	return &voucherUseCase{repo: repo}
+}
+
+func (u *voucherUseCase) ListAll() ([]model.Voucher, error) {
+	return u.repo.FindAll()
+}
+
+func (u *voucherUseCase) GetByID(id uint) (*model.Voucher, error) {
+	voucher, err := u.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("voucher tidak ditemukan")
+	}
+	return voucher, nil
+}
+
+func (u *voucherUseCase) Create(req dto.VoucherRequest) error {
+	voucher := &model.Voucher{}
+	if err := applyVoucherRequest(voucher, req); err != nil {
+		return err
+	}
+
+	if _, err := u.repo.FindByCode(voucher.Code); err == nil {
+		return fmt.Errorf("kode %s sudah dipakai voucher lain", voucher.Code)
+	}
+	return u.repo.Create(voucher)
+}
+
+func (u *voucherUseCase) Update(id uint, req dto.VoucherRequest) error {
+	voucher, err := u.repo.FindByID(id)
+	if err != nil {
+		return errors.New("voucher tidak ditemukan")
+	}
+	if err := applyVoucherRequest(voucher, req); err != nil {
+		return err
+	}
+
+	if existing, err := u.repo.FindByCode(voucher.Code); err == nil && existing.ID != id {
+		return fmt.Errorf("kode %s sudah dipakai voucher lain", voucher.Code)
+	}
+	return u.repo.Update(voucher)
+}
+
+func (u *voucherUseCase) ToggleActive(id uint) error {
+	voucher, err := u.repo.FindByID(id)
+	if err != nil {
+		return errors.New("voucher tidak ditemukan")
+	}
+	voucher.IsActive = !voucher.IsActive
+	return u.repo.Update(voucher)
+}
+
+func (u *voucherUseCase) Delete(id uint) error {
+	return u.repo.Delete(id)
+}
+
+func (u *voucherUseCase) Report(recentLimit int) (*dto.VoucherReport, error) {
+	vouchers, err := u.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	usage, err := u.repo.UsageSummary()
+	if err != nil {
+		return nil, err
+	}
+	recent, err := u.repo.FindRecentRedemptions(recentLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]model.Voucher, len(vouchers))
+	for _, v := range vouchers {
+		byID[v.ID] = v
+	}
+
+	report := &dto.VoucherReport{Recent: recent}
+	for _, row := range usage {
+		report.Rows = append(report.Rows, dto.VoucherUsageRow{
+			Voucher:       byID[row.VoucherID],
+			Uses:          row.Uses,
+			TotalDiscount: row.TotalDiscount,
+			LastUsedAt:    row.LastUsedAt,
+		})
+		report.TotalUses += row.Uses
+		report.TotalDiscount += row.TotalDiscount
+	}
+	return report, nil
+}
+
+// applyVoucherRequest validates the admin form and copies it onto the voucher.
+func applyVoucherRequest(v *model.Voucher, req dto.VoucherRequest) error {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if code == "" || strings.ContainsAny(code, ", ") {
+		return errors.New("kode voucher tidak boleh kosong atau mengandung spasi/koma")
+	}
+	if req.Type == model.VoucherTypePercent && req.Value > 100 {
+		return errors.New("diskon persen maksimal 100")
+	}
+
+	startsAt, err := parseVoucherTime(req.StartsAt)
+	if err != nil {
+		return errors.New("format tanggal mulai tidak valid")
+	}
+	endsAt, err := parseVoucherTime(req.EndsAt)
+	if err != nil {
+		return errors.New("format tanggal berakhir tidak valid")
+	}
+	if startsAt != nil && endsAt != nil && endsAt.Before(*startsAt) {
+		return errors.New("tanggal berakhir harus setelah tanggal mulai")
+	}
+
+	v.Code = code
+	v.Description = req.Description
+	v.Type = req.Type
+	v.Value = req.Value
+	v.MaxDiscount = req.MaxDiscount
+	v.MinSpend = req.MinSpend
+	v.Category = req.Category
+	v.StartsAt = startsAt
+	v.EndsAt = endsAt
+	v.UsageLimit = req.UsageLimit
+	v.PerCustomerLimit = req.PerCustomerLimit
+	v.Stackable = req.Stackable
+	v.IsActive = req.IsActive
+
+	v.BoothID = nil
+	v.Booth = nil
+	if req.BoothID != 0 {
+		boothID := req.BoothID
+		v.BoothID = &boothID
+	}
+	return nil
+}
+
+func parseVoucherTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(voucherTimeLayout, value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
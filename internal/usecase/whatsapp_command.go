@@ -0,0 +1,336 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+	"github.com/Rakhulsr/foodcourt/utils"
+)
+
+// ticketCommands maps WhatsApp replies such as "SIAP ORD-XXXX" to ticket statuses,
+// the same steps as the buttons on the kitchen display.
+var ticketCommands = map[string]string{
+	"PROSES":  model.TicketStatusPreparing,
+	"SIAP":    model.TicketStatusReady,
+	"SELESAI": model.TicketStatusCompleted,
+}
+
+// Inbound command log statuses.
+const (
+	commandOK       = "ok"
+	commandFailed   = "failed"
+	commandRejected = "rejected"
+)
+
+const commandHelp = "Perintah yang bisa dipakai:\n" +
+	"▪️ *PROSES ORD-XXXX* — mulai masak\n" +
+	"▪️ *SIAP ORD-XXXX* — pesanan siap diambil\n" +
+	"▪️ *SELESAI ORD-XXXX* — pesanan sudah diserahkan\n" +
+	"▪️ *HABIS Nama Menu* — menu tidak tersedia\n" +
+	"▪️ *ADA Nama Menu* — menu tersedia lagi\n" +
+	"▪️ *TUTUP* / *BUKA* — tutup atau buka booth (tambahkan nama booth bila nomor ini dipakai beberapa booth)"
+
+// WhatsAppCommandUseCase runs commands booths send by replying on WhatsApp.
+type WhatsAppCommandUseCase interface {
+	// Handle runs one message and returns the reply, or "" when the message is not
+	// a command from a registered booth and is ignored.
+	Handle(sender string, text string) string
+}
+
+type whatsAppCommandUseCase struct {
+	boothRepo repository.BoothRepository
+	menuRepo  repository.MenuRepository
+	orderRepo repository.OrderRepository
+	orderUC   OrderUsecase
+	logUC     LogUseCase
+}
+
+func NewWhatsAppCommandUseCase(br repository.BoothRepository, mr repository.MenuRepository, or repository.OrderRepository, ouc OrderUsecase, log LogUseCase) WhatsAppCommandUseCase {
+	return &whatsAppCommandUseCase{boothRepo: br, menuRepo: mr, orderRepo: or, orderUC: ouc, logUC: log}
+}
+
+// commandResult is what one command did, for the reply and the log.
+type commandResult struct {
+	reply   string
+	boothID *uint
+	orderID *uint
+	ok      bool
+}
+
+func (u *whatsAppCommandUseCase) Handle(sender string, text string) string {
+	text = strings.TrimSpace(text)
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+	verb := strings.ToUpper(fields[0])
+	arg := strings.Join(fields[1:], " ")
+
+	booths, err := u.findBooths(sender)
+	if err != nil || len(booths) == 0 {
+		// Anyone can message the number, so only command-looking messages from
+		// unknown senders are logged and none of them get an answer.
+		if isCommandVerb(verb) {
+			fmt.Printf("⚠️ Perintah WhatsApp dari nomor tidak dikenal %s: %s\n", sender, text)
+			u.logUC.RecordCommand(nil, nil, commandRejected, "+"+sender+": "+text)
+		}
+		return ""
+	}
+
+	var result commandResult
+	switch {
+	case ticketCommands[verb] != "":
+		result = u.updateTickets(booths, verb, arg)
+	case verb == "HABIS" || verb == "ADA":
+		result = u.setMenuAvailable(booths, arg, verb == "ADA")
+	case verb == "TUTUP" || verb == "BUKA":
+		result = u.setBoothOpen(booths, arg, verb == "BUKA")
+	case verb == "BANTUAN" || verb == "HELP":
+		result = commandResult{reply: commandHelp, ok: true}
+	default:
+		// Booth owners also chat with this number about other things.
+		return ""
+	}
+
+	if result.boothID == nil && len(booths) == 1 {
+		result.boothID = &booths[0].ID
+	}
+	status := commandOK
+	if !result.ok {
+		status = commandFailed
+	}
+	u.logUC.RecordCommand(result.boothID, result.orderID, status, text+" → "+result.reply)
+	return result.reply
+}
+
+func isCommandVerb(verb string) bool {
+	switch verb {
+	case "HABIS", "ADA", "TUTUP", "BUKA":
+		return true
+	}
+	return ticketCommands[verb] != ""
+}
+
+// findBooths returns every booth registered with the sender's WhatsApp number. One
+// owner may run several booths from the same phone.
+func (u *whatsAppCommandUseCase) findBooths(sender string) ([]model.Booth, error) {
+	all, err := u.boothRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	sender = utils.NormalizePhone(sender)
+	if sender == "" {
+		return nil, nil
+	}
+
+	var booths []model.Booth
+	for _, booth := range all {
+		if utils.NormalizePhone(booth.WhatsApp) == sender {
+			booths = append(booths, booth)
+		}
+	}
+	return booths, nil
+}
+
+// updateTickets moves the sender's tickets in the order. The order tells which of
+// their booths the command is for; with more than one, all of them are moved.
+func (u *whatsAppCommandUseCase) updateTickets(booths []model.Booth, verb string, arg string) commandResult {
+	if arg == "" {
+		return commandResult{reply: fmt.Sprintf("Sertakan kode pesanan, contoh: *%s ORD-XXXX*", verb)}
+	}
+
+	code := strings.ToUpper(strings.Fields(arg)[0])
+	if !strings.HasPrefix(code, "ORD-") {
+		code = "ORD-" + code
+	}
+
+	order, err := u.orderRepo.FindByCode(code)
+	if err != nil {
+		return commandResult{reply: fmt.Sprintf("Pesanan *%s* tidak ditemukan.", code)}
+	}
+	result := commandResult{orderID: &order.ID}
+
+	var tickets []model.BoothTicket
+	var names []string
+	for _, booth := range booths {
+		names = append(names, booth.Name)
+		if ticket := findBoothTicket(order, booth.ID); ticket != nil {
+			tickets = append(tickets, *ticket)
+		}
+	}
+	if len(tickets) == 0 {
+		result.reply = fmt.Sprintf("Pesanan *%s* tidak berisi menu dari %s.", code, strings.Join(names, ", "))
+		return result
+	}
+	if len(tickets) == 1 {
+		result.boothID = &tickets[0].BoothID
+	}
+
+	status := ticketCommands[verb]
+	actor := model.StatusActor{Type: model.ActorBooth}
+	subject := fmt.Sprintf("*%s*", code)
+	if queue := order.QueueLabel(); queue != "" {
+		subject = fmt.Sprintf("*%s* (antrean %s)", code, queue)
+	}
+
+	var lines []string
+	result.ok = true
+	for _, ticket := range tickets {
+		prefix := ""
+		if len(tickets) > 1 {
+			prefix = ticket.Booth.Name + ": "
+		}
+		if err := u.orderUC.UpdateTicketStatus(order.OrderCode, ticket.ID, status, actor, "WhatsApp "+ticket.Booth.Name); err != nil {
+			lines = append(lines, fmt.Sprintf("%sGagal mengubah *%s*: %s", prefix, code, err.Error()))
+			result.ok = false
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s✅ %s sekarang *%s*.", prefix, subject, model.TicketStatusLabel(status)))
+	}
+	result.reply = strings.Join(lines, "\n")
+	return result
+}
+
+func findBoothTicket(order *model.Order, boothID uint) *model.BoothTicket {
+	for i := range order.Tickets {
+		if order.Tickets[i].BoothID == boothID {
+			return &order.Tickets[i]
+		}
+	}
+	return nil
+}
+
+// setMenuAvailable matches name against the menus of the sender's booths, exact
+// name first and then by the part of the name given.
+func (u *whatsAppCommandUseCase) setMenuAvailable(booths []model.Booth, name string, available bool) commandResult {
+	if name == "" {
+		return commandResult{reply: "Sertakan nama menu, contoh: *HABIS Nasi Goreng*"}
+	}
+
+	// "Es Teh (Booth A)" picks one booth's menu when several share the name.
+	if i := strings.LastIndex(name, " ("); i > 0 && strings.HasSuffix(name, ")") {
+		var picked []model.Booth
+		for _, booth := range booths {
+			if strings.EqualFold(booth.Name, name[i+2:len(name)-1]) {
+				picked = append(picked, booth)
+			}
+		}
+		if len(picked) > 0 {
+			booths, name = picked, name[:i]
+		}
+	}
+
+	var menus []model.Menu
+	for _, booth := range booths {
+		boothMenus, err := u.menuRepo.FindByBoothID(booth.ID)
+		if err != nil {
+			return commandResult{reply: "Gagal membaca menu: " + err.Error()}
+		}
+		menus = append(menus, boothMenus...)
+	}
+
+	var exact, partial []model.Menu
+	for _, menu := range menus {
+		if strings.EqualFold(menu.Name, name) {
+			exact = append(exact, menu)
+		} else if strings.Contains(strings.ToLower(menu.Name), strings.ToLower(name)) {
+			partial = append(partial, menu)
+		}
+	}
+	matches := exact
+	if len(matches) == 0 {
+		matches = partial
+	}
+
+	if len(matches) == 0 {
+		return commandResult{reply: fmt.Sprintf("Menu *%s* tidak ada di %s.", name, boothNames(booths))}
+	}
+	if len(matches) > 1 {
+		names := make([]string, len(matches))
+		for i, menu := range matches {
+			names[i] = "▪️ " + menu.Name
+			if len(booths) > 1 {
+				names[i] += " (" + boothName(booths, menu.BoothID) + ")"
+			}
+		}
+		return commandResult{reply: "Ada beberapa menu yang cocok, tulis nama lengkapnya:\n" + strings.Join(names, "\n")}
+	}
+
+	menu := matches[0]
+	result := commandResult{boothID: &menu.BoothID}
+	menu.IsAvailable = available
+	if err := u.menuRepo.Update(&menu); err != nil {
+		result.reply = "Gagal mengubah menu: " + err.Error()
+		return result
+	}
+
+	result.ok = true
+	if available {
+		result.reply = fmt.Sprintf("✅ *%s* tersedia lagi.", menu.Name)
+	} else {
+		result.reply = fmt.Sprintf("✅ *%s* ditandai habis.", menu.Name)
+	}
+	return result
+}
+
+// setBoothOpen opens or closes the sender's booth. A number shared by several booths
+// has to name the booth, e.g. "TUTUP Bakso Pak Kumis".
+func (u *whatsAppCommandUseCase) setBoothOpen(booths []model.Booth, name string, open bool) commandResult {
+	verb := "TUTUP"
+	if open {
+		verb = "BUKA"
+	}
+
+	var matches []model.Booth
+	for _, booth := range booths {
+		if name == "" || strings.Contains(strings.ToLower(booth.Name), strings.ToLower(name)) {
+			matches = append(matches, booth)
+		}
+	}
+	if len(matches) == 0 {
+		return commandResult{reply: fmt.Sprintf("Booth *%s* tidak terdaftar dengan nomor ini. Booth Anda: %s.", name, boothNames(booths))}
+	}
+	if len(matches) > 1 {
+		names := make([]string, len(matches))
+		for i, booth := range matches {
+			names[i] = fmt.Sprintf("▪️ *%s %s*", verb, booth.Name)
+		}
+		return commandResult{reply: "Nomor ini dipakai beberapa booth, sebutkan booth-nya:\n" + strings.Join(names, "\n")}
+	}
+
+	booth := matches[0]
+	result := commandResult{boothID: &booth.ID}
+	booth.IsActive = open
+	if err := u.boothRepo.Update(&booth); err != nil {
+		result.reply = "Gagal mengubah status booth: " + err.Error()
+		return result
+	}
+
+	result.ok = true
+	if open {
+		result.reply = fmt.Sprintf("✅ %s *BUKA*, menu tampil lagi untuk pelanggan.", booth.Name)
+	} else {
+		result.reply = fmt.Sprintf("✅ %s *TUTUP*, menu disembunyikan dari pelanggan.", booth.Name)
+	}
+	return result
+}
+
+func boothNames(booths []model.Booth) string {
+	names := make([]string, len(booths))
+	for i, booth := range booths {
+		names[i] = booth.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func boothName(booths []model.Booth, id uint) string {
+	for _, booth := range booths {
+		if booth.ID == id {
+			return booth.Name
+		}
+	}
+	return ""
+}
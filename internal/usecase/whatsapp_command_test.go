@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Rakhulsr/foodcourt/internal/model"
+	"github.com/Rakhulsr/foodcourt/internal/repository"
+)
+
+type fakeBoothRepo struct {
+	repository.BoothRepository
+	booths  []model.Booth
+	updated []string
+}
+
+func (r *fakeBoothRepo) FindAll() ([]model.Booth, error) {
+	return r.booths, nil
+}
+
+func (r *fakeBoothRepo) Update(booth *model.Booth) error {
+	r.updated = append(r.updated, fmt.Sprintf("%d=%t", booth.ID, booth.IsActive))
+	return nil
+}
+
+type fakeMenuRepo struct {
+	repository.MenuRepository
+	menus   []model.Menu
+	updated []string
+}
+
+func (r *fakeMenuRepo) FindByBoothID(boothID uint) ([]model.Menu, error) {
+	var menus []model.Menu
+	for _, menu := range r.menus {
+		if menu.BoothID == boothID {
+			menus = append(menus, menu)
+		}
+	}
+	return menus, nil
+}
+
+func (r *fakeMenuRepo) Update(menu *model.Menu) error {
+	r.updated = append(r.updated, fmt.Sprintf("%d=%t", menu.ID, menu.IsAvailable))
+	return nil
+}
+
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	orders map[string]*model.Order
+}
+
+func (r *fakeOrderRepo) FindByCode(code string) (*model.Order, error) {
+	order, ok := r.orders[code]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return order, nil
+}
+
+// fakeOrderUC records ticket status changes as "ORDER/ticket=status".
+type fakeOrderUC struct {
+	OrderUsecase
+	updated []string
+}
+
+func (u *fakeOrderUC) UpdateTicketStatus(orderCode string, ticketID uint, newStatus string, actor model.StatusActor, reason string) error {
+	u.updated = append(u.updated, fmt.Sprintf("%s/%d=%s", orderCode, ticketID, newStatus))
+	return nil
+}
+
+// fakeLog records the statuses of the WhatsApp log entries written through it.
+type fakeLog struct {
+	LogUseCase
+	mu       sync.Mutex
+	commands []string
+	updates  []string
+}
+
+func (l *fakeLog) RecordCommand(boothID *uint, orderID *uint, status string, detail string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.commands = append(l.commands, status)
+	return nil
+}
+
+func TestWhatsAppCommandHandle(t *testing.T) {
+	const (
+		shared = "081111111111" // Bakso Pak Kumis and Es Teh Manis
+		single = "082222222222" // Nasi Goreng
+	)
+	booths := []model.Booth{
+		{ID: 1, Name: "Bakso Pak Kumis", WhatsApp: shared, IsActive: true},
+		{ID: 2, Name: "Es Teh Manis", WhatsApp: "+62 811-1111-1111", IsActive: true},
+		{ID: 3, Name: "Nasi Goreng", WhatsApp: single, IsActive: true},
+	}
+	menus := []model.Menu{
+		{ID: 10, BoothID: 1, Name: "Bakso Urat"},
+		{ID: 11, BoothID: 1, Name: "Es Jeruk"},
+		{ID: 12, BoothID: 1, Name: "Es Teh Tarik"},
+		{ID: 20, BoothID: 2, Name: "Es Jeruk"},
+		{ID: 21, BoothID: 2, Name: "Es Teh"},
+		{ID: 30, BoothID: 3, Name: "Nasi Goreng Spesial"},
+		{ID: 31, BoothID: 3, Name: "Nasi Goreng Biasa"},
+	}
+	orders := map[string]*model.Order{
+		"ORD-AAAA": {ID: 100, OrderCode: "ORD-AAAA", QueueNumber: 7, Tickets: []model.BoothTicket{
+			{ID: 1, BoothID: 1, Booth: booths[0]},
+			{ID: 2, BoothID: 2, Booth: booths[1]},
+			{ID: 3, BoothID: 3, Booth: booths[2]},
+		}},
+		"ORD-BBBB": {ID: 101, OrderCode: "ORD-BBBB", Tickets: []model.BoothTicket{
+			{ID: 4, BoothID: 3, Booth: booths[2]},
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		sender      string
+		text        string
+		wantReply   []string
+		wantLog     []string
+		wantTickets []string
+		wantMenus   []string
+		wantBooths  []string
+	}{
+		{name: "unknown sender command", sender: "089999999999", text: "SIAP ORD-AAAA", wantLog: []string{commandRejected}},
+		{name: "unknown sender chatter", sender: "089999999999", text: "halo"},
+		{name: "unknown sender help", sender: "089999999999", text: "HELP"},
+		{name: "booth chatter", sender: single, text: "terima kasih"},
+		{name: "empty", sender: single, text: "  "},
+		{name: "help", sender: single, text: "bantuan", wantReply: []string{"Perintah yang bisa dipakai"}, wantLog: []string{commandOK}},
+		{
+			name: "ticket code without prefix", sender: single, text: "siap aaaa",
+			wantReply: []string{"✅ *ORD-AAAA* (antrean 007) sekarang"}, wantLog: []string{commandOK},
+			wantTickets: []string{"ORD-AAAA/3=ready"},
+		},
+		{name: "ticket without code", sender: single, text: "SIAP", wantReply: []string{"Sertakan kode pesanan"}, wantLog: []string{commandFailed}},
+		{name: "unknown order", sender: single, text: "SIAP ORD-ZZZZ", wantReply: []string{"tidak ditemukan"}, wantLog: []string{commandFailed}},
+		{
+			name: "order without the booth", sender: shared, text: "PROSES ORD-BBBB",
+			wantReply: []string{"tidak berisi menu dari Bakso Pak Kumis, Es Teh Manis"}, wantLog: []string{commandFailed},
+		},
+		{
+			name: "shared number moves every ticket", sender: shared, text: "SELESAI ORD-AAAA",
+			wantReply: []string{"Bakso Pak Kumis: ✅", "Es Teh Manis: ✅"}, wantLog: []string{commandOK},
+			wantTickets: []string{"ORD-AAAA/1=completed", "ORD-AAAA/2=completed"},
+		},
+		{
+			name: "menu by part of the name", sender: single, text: "HABIS spesial",
+			wantReply: []string{"*Nasi Goreng Spesial* ditandai habis"}, wantLog: []string{commandOK},
+			wantMenus: []string{"30=false"},
+		},
+		{
+			name: "ambiguous menu", sender: single, text: "HABIS nasi goreng",
+			wantReply: []string{"Ada beberapa menu", "▪️ Nasi Goreng Spesial\n"}, wantLog: []string{commandFailed},
+		},
+		{
+			name: "same menu in two booths", sender: shared, text: "HABIS es jeruk",
+			wantReply: []string{"Es Jeruk (Bakso Pak Kumis)", "Es Jeruk (Es Teh Manis)"}, wantLog: []string{commandFailed},
+		},
+		{
+			name: "menu picked by booth", sender: shared, text: "ADA Es Jeruk (es teh manis)",
+			wantReply: []string{"*Es Jeruk* tersedia lagi"}, wantLog: []string{commandOK},
+			wantMenus: []string{"20=true"},
+		},
+		{
+			name: "exact name wins", sender: shared, text: "HABIS es teh",
+			wantReply: []string{"*Es Teh* ditandai habis"}, wantLog: []string{commandOK},
+			wantMenus: []string{"21=false"},
+		},
+		{name: "unknown menu", sender: single, text: "HABIS sate", wantReply: []string{"tidak ada di Nasi Goreng"}, wantLog: []string{commandFailed}},
+		{
+			name: "close single booth", sender: single, text: "TUTUP",
+			wantReply: []string{"Nasi Goreng *TUTUP*"}, wantLog: []string{commandOK},
+			wantBooths: []string{"3=false"},
+		},
+		{
+			name: "close shared booth asks which", sender: shared, text: "TUTUP",
+			wantReply: []string{"*TUTUP Bakso Pak Kumis*", "*TUTUP Es Teh Manis*"}, wantLog: []string{commandFailed},
+		},
+		{
+			name: "open shared booth by name", sender: shared, text: "buka bakso",
+			wantReply: []string{"Bakso Pak Kumis *BUKA*"}, wantLog: []string{commandOK},
+			wantBooths: []string{"1=true"},
+		},
+		{name: "booth not on the number", sender: shared, text: "TUTUP nasi", wantReply: []string{"Booth *nasi* tidak terdaftar"}, wantLog: []string{commandFailed}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			boothRepo := &fakeBoothRepo{booths: booths}
+			menuRepo := &fakeMenuRepo{menus: menus}
+			orderUC := &fakeOrderUC{}
+			log := &fakeLog{}
+			u := NewWhatsAppCommandUseCase(boothRepo, menuRepo, &fakeOrderRepo{orders: orders}, orderUC, log)
+
+			reply := u.Handle(tt.sender, tt.text)
+			if len(tt.wantReply) == 0 && reply != "" {
+				t.Errorf("reply = %q, want none", reply)
+			}
+			for _, want := range tt.wantReply {
+				if !strings.Contains(reply, want) {
+					t.Errorf("reply = %q, want it to contain %q", reply, want)
+				}
+			}
+
+			if !reflect.DeepEqual(log.commands, tt.wantLog) {
+				t.Errorf("logged %v, want %v", log.commands, tt.wantLog)
+			}
+			if !reflect.DeepEqual(orderUC.updated, tt.wantTickets) {
+				t.Errorf("ticket updates = %v, want %v", orderUC.updated, tt.wantTickets)
+			}
+			if !reflect.DeepEqual(menuRepo.updated, tt.wantMenus) {
+				t.Errorf("menu updates = %v, want %v", menuRepo.updated, tt.wantMenus)
+			}
+			if !reflect.DeepEqual(boothRepo.updated, tt.wantBooths) {
+				t.Errorf("booth updates = %v, want %v", boothRepo.updated, tt.wantBooths)
+			}
+		})
+	}
+}
@@ -2,111 +2,394 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"regexp"
-	"strings"
+	"sync"
 	"time"
 
+	"github.com/Rakhulsr/foodcourt/internal/dto"
+	"github.com/Rakhulsr/foodcourt/utils"
 	"github.com/mdp/qrterminal/v3"
 	"go.mau.fi/whatsmeow"
 	_ "modernc.org/sqlite"
 
 	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/store/sqlstore"
 	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
 	waLog "go.mau.fi/whatsmeow/util/log"
 )
 
+const (
+	waConnectBaseBackoff = 5 * time.Second
+	waConnectMaxBackoff  = 5 * time.Minute
+	// waCommandMaxAge skips commands that arrive late, e.g. when messages sent while
+	// the server was offline are delivered on reconnect.
+	waCommandMaxAge = 10 * time.Minute
+	waReplyTimeout  = 30 * time.Second
+)
+
+// WhatsAppUsecase is the WhatsApp Notifier, sending from the linked WhatsApp account.
+// It also owns the session: pairing by QR code, reconnecting and logging out.
 type WhatsAppUsecase struct {
-	Client *whatsmeow.Client
+	container *sqlstore.Container
+
+	mu     sync.RWMutex
+	client *whatsmeow.Client
+	status dto.WhatsAppStatus
+	// starting is set while start runs, so a second connect request does not race it.
+	starting bool
+
+	commands WhatsAppCommandUseCase
 }
 
-func NewWhattsAppUsecase() *WhatsAppUsecase {
+// NewWhattsAppUsecase opens the WhatsApp session store and connects in the
+// background. An error means WhatsApp is unavailable; the caller carries on without it.
+func NewWhattsAppUsecase() (*WhatsAppUsecase, error) {
 	dbLog := waLog.Stdout("Database", "ERROR", true)
 
 	connectionString := "file:wa_session.db?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous=NORMAL"
 	container, err := sqlstore.New(context.Background(), "sqlite", connectionString, dbLog)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("session WhatsApp gagal dibuka: %w", err)
 	}
 
 	deviceStore, err := container.GetFirstDevice(context.Background())
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("device WhatsApp gagal dibaca: %w", err)
+	}
+
+	s := &WhatsAppUsecase{container: container}
+	s.client = s.newClient(deviceStore)
+	s.status = dto.WhatsAppStatus{State: dto.WhatsAppConnecting, Since: time.Now()}
+
+	s.starting = true
+	go s.start()
+
+	return s, nil
+}
+
+func (s *WhatsAppUsecase) newClient(device *store.Device) *whatsmeow.Client {
+	client := whatsmeow.NewClient(device, waLog.Stdout("Client", "INFO", true))
+	// whatsmeow reconnects by itself after a dropped connection; the hook only
+	// records why the last attempt failed.
+	client.EnableAutoReconnect = true
+	client.InitialAutoReconnect = true
+	client.AutoReconnectHook = func(err error) bool {
+		fmt.Printf("⚠️ WhatsApp gagal menyambung ulang: %v\n", err)
+		s.setState(client, dto.WhatsAppReconnecting, err.Error())
+		return true
+	}
+	client.AddEventHandler(func(evt any) {
+		s.handleEvent(client, evt)
+	})
+	return client
+}
+
+// SetCommands lets booths run commands by messaging the linked number.
+func (s *WhatsAppUsecase) SetCommands(commands WhatsAppCommandUseCase) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = commands
+}
+
+func (s *WhatsAppUsecase) currentClient() *whatsmeow.Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
+
+// setState records the session state unless client has been replaced meanwhile.
+func (s *WhatsAppUsecase) setState(client *whatsmeow.Client, state string, lastError string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != client {
+		return
+	}
+	if s.status.State != state {
+		s.status.Since = time.Now()
+	}
+	s.status.State = state
+	s.status.LastError = lastError
+	if state != dto.WhatsAppPairing {
+		s.status.QRCode = ""
+	}
+}
+
+func (s *WhatsAppUsecase) setQRCode(client *whatsmeow.Client, code string, timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != client {
+		return
 	}
+	if s.status.State != dto.WhatsAppPairing {
+		s.status.Since = time.Now()
+	}
+	s.status.State = dto.WhatsAppPairing
+	s.status.LastError = ""
+	s.status.QRCode = code
+	s.status.QRExpiresAt = time.Now().Add(timeout)
+}
 
-	clientLog := waLog.Stdout("Client", "INFO", true)
-	client := whatsmeow.NewClient(deviceStore, clientLog)
+func (s *WhatsAppUsecase) handleEvent(client *whatsmeow.Client, evt any) {
+	switch e := evt.(type) {
+	case *events.Message:
+		go s.handleMessage(client, e)
+	case *events.Connected:
+		fmt.Println("✅ WhatsApp Service Terhubung!")
+		s.setState(client, dto.WhatsAppConnected, "")
+	case *events.Disconnected:
+		fmt.Println("⚠️ Koneksi WhatsApp terputus, menyambung ulang...")
+		s.setState(client, dto.WhatsAppReconnecting, "")
+	case *events.StreamReplaced:
+		fmt.Println("⚠️ Sesi WhatsApp dipakai di tempat lain")
+		s.setState(client, dto.WhatsAppDisconnected, "Sesi WhatsApp dibuka di server lain")
+	case *events.TemporaryBan:
+		s.setState(client, dto.WhatsAppDisconnected, "Akun diblokir sementara: "+e.String())
+	case *events.ConnectFailure:
+		s.setState(client, dto.WhatsAppDisconnected, fmt.Sprintf("Koneksi ditolak (%d) %s", int(e.Reason), e.Message))
+	case *events.LoggedOut:
+		// whatsmeow has already deleted the session, so a fresh device is needed to pair again.
+		fmt.Println("❌ WhatsApp dilepas dari HP, perlu ditautkan ulang")
+		s.replaceClient(client, "Perangkat dilepas dari HP")
+	}
+}
+
+// handleMessage runs a direct text message as a booth command and replies in the
+// same chat.
+func (s *WhatsAppUsecase) handleMessage(client *whatsmeow.Client, msg *events.Message) {
+	s.mu.RLock()
+	commands := s.commands
+	s.mu.RUnlock()
 
-	go connectWA(client)
+	if commands == nil || msg.Info.IsFromMe || msg.Info.IsGroup || msg.Info.Chat.IsBroadcastList() {
+		return
+	}
+	if time.Since(msg.Info.Timestamp) > waCommandMaxAge {
+		return
+	}
 
-	return &WhatsAppUsecase{Client: client}
+	text := msg.Message.GetConversation()
+	if text == "" {
+		text = msg.Message.GetExtendedTextMessage().GetText()
+	}
+	if text == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), waReplyTimeout)
+	defer cancel()
+
+	reply := commands.Handle(senderPhone(ctx, client, msg.Info.MessageSource), text)
+	if reply == "" {
+		return
+	}
+
+	if _, err := client.SendMessage(ctx, msg.Info.Chat, &waE2E.Message{Conversation: &reply}); err != nil {
+		fmt.Printf("⚠️ Balasan WhatsApp ke %s gagal: %v\n", msg.Info.Chat.User, err)
+	}
 }
 
-func connectWA(client *whatsmeow.Client) {
+// senderPhone returns the phone number of a message sender. Newer chats address
+// senders by LID, which is mapped back to the phone number.
+func senderPhone(ctx context.Context, client *whatsmeow.Client, source types.MessageSource) string {
+	sender := source.Sender.ToNonAD()
+	if sender.Server != types.HiddenUserServer {
+		return sender.User
+	}
+	if source.SenderAlt.Server == types.DefaultUserServer {
+		return source.SenderAlt.User
+	}
+	if pn, err := client.Store.LIDs.GetPNForLID(ctx, sender); err == nil && !pn.IsEmpty() {
+		return pn.User
+	}
+	return ""
+}
+
+// replaceClient swaps in a client for a new, unpaired device after the session
+// of old was removed.
+func (s *WhatsAppUsecase) replaceClient(old *whatsmeow.Client, reason string) {
+	fresh := s.newClient(s.container.NewDevice())
+
+	s.mu.Lock()
+	if s.client != old {
+		s.mu.Unlock()
+		return
+	}
+	s.client = fresh
+	s.status = dto.WhatsAppStatus{State: dto.WhatsAppLoggedOut, LastError: reason, Since: time.Now()}
+	s.mu.Unlock()
+
+	// Removing handlers waits for running ones, and this may be called from one.
+	go old.RemoveEventHandlers()
+}
+
+// start connects the current client, showing a pairing QR code first when no phone
+// is linked yet. A first connect that fails is retried with backoff.
+func (s *WhatsAppUsecase) start() {
+	defer func() {
+		s.mu.Lock()
+		s.starting = false
+		s.mu.Unlock()
+	}()
+
+	client := s.currentClient()
 	if client.Store.ID == nil {
+		s.pair(client)
+		return
+	}
 
-		qrChan, _ := client.GetQRChannel(context.Background())
+	s.setState(client, dto.WhatsAppConnecting, "")
+	backoff := waConnectBaseBackoff
+	for {
 		err := client.Connect()
-		if err != nil {
-			panic(err)
+		if err == nil || errors.Is(err, whatsmeow.ErrAlreadyConnected) {
+			return
 		}
-		for evt := range qrChan {
-			if evt.Event == "code" {
-
-				fmt.Println("\n\n===================================================")
-				fmt.Println("SCAN QR CODE INI UNTUK LOGIN WA ADMIN:")
-				qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
-				fmt.Println("===================================================\n\n")
-			} else {
-				fmt.Println("Login Event:", evt.Event)
-			}
+		if s.currentClient() != client {
+			return
 		}
-	} else {
 
-		err := client.Connect()
-		if err != nil {
-			panic(err)
+		fmt.Printf("❌ WhatsApp gagal terhubung, coba lagi dalam %v: %v\n", backoff, err)
+		s.setState(client, dto.WhatsAppReconnecting, err.Error())
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > waConnectMaxBackoff {
+			backoff = waConnectMaxBackoff
 		}
-		fmt.Println("✅ WhatsApp Service Terhubung!")
 	}
 }
 
-func (s *WhatsAppUsecase) SendMessage(ctx context.Context, phone string, message string) error {
+// pair connects an unlinked client and publishes each QR code until a phone scans
+// one or the codes run out.
+func (s *WhatsAppUsecase) pair(client *whatsmeow.Client) {
+	qrChan, err := client.GetQRChannel(context.Background())
+	if err != nil {
+		s.setState(client, dto.WhatsAppLoggedOut, err.Error())
+		return
+	}
 
-	re := regexp.MustCompile(`[^0-9]`)
-	phone = re.ReplaceAllString(phone, "")
+	s.setState(client, dto.WhatsAppConnecting, "")
+	if err := client.Connect(); err != nil {
+		fmt.Printf("❌ WhatsApp gagal terhubung: %v\n", err)
+		s.setState(client, dto.WhatsAppLoggedOut, err.Error())
+		return
+	}
+
+	for evt := range qrChan {
+		switch evt.Event {
+		case whatsmeow.QRChannelEventCode:
+			s.setQRCode(client, evt.Code, evt.Timeout)
 
-	if strings.HasPrefix(phone, "0") {
-		phone = "62" + phone[1:]
+			fmt.Println("\n\n===================================================")
+			fmt.Println("SCAN QR CODE INI UNTUK LOGIN WA ADMIN (atau buka menu WhatsApp di admin):")
+			qrterminal.GenerateHalfBlock(evt.Code, qrterminal.L, os.Stdout)
+			fmt.Print("===================================================\n\n\n")
+		case whatsmeow.QRChannelSuccess.Event:
+			// The Connected event follows once whatsmeow has logged in with the new
+			// session; it may already have arrived.
+			fmt.Println("✅ WhatsApp berhasil ditautkan")
+			s.mu.Lock()
+			if s.client == client && s.status.State == dto.WhatsAppPairing {
+				s.status.State = dto.WhatsAppConnecting
+				s.status.QRCode = ""
+			}
+			s.mu.Unlock()
+		default:
+			reason := "Waktu scan QR habis"
+			if evt.Error != nil {
+				reason = evt.Error.Error()
+			} else if evt.Event != whatsmeow.QRChannelTimeout.Event {
+				reason = "Penautan gagal: " + evt.Event
+			}
+			fmt.Println("Login Event:", evt.Event)
+			client.Disconnect()
+			s.setState(client, dto.WhatsAppLoggedOut, reason)
+		}
 	}
+}
 
-	fmt.Printf("DEBUG: Sending to Clean JID: %s@s.whatsapp.net\n", phone)
+// Status returns the current session state, with the linked phone number once paired.
+func (s *WhatsAppUsecase) Status() dto.WhatsAppStatus {
+	s.mu.RLock()
+	status := s.status
+	client := s.client
+	s.mu.RUnlock()
 
-	if !s.Client.IsConnected() {
-		return fmt.Errorf("whatsapp belum terhubung")
+	if id := client.Store.ID; id != nil {
+		status.Phone = id.User
+		status.PushName = client.Store.PushName
+	}
+	if status.QRCode != "" && time.Now().After(status.QRExpiresAt) {
+		status.QRCode = ""
 	}
+	return status
+}
 
-	jid, _ := types.ParseJID(phone + "@s.whatsapp.net")
+// Connect starts pairing when no phone is linked, or reconnects a session that
+// was disconnected for good.
+func (s *WhatsAppUsecase) Connect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	var err error
-	for i := 0; i < 3; i++ {
+	if s.starting {
+		return errors.New("WhatsApp sedang dihubungkan")
+	}
+	if s.status.State == dto.WhatsAppConnected {
+		return errors.New("WhatsApp sudah terhubung")
+	}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	s.starting = true
+	go s.start()
+	return nil
+}
 
-		_, err = s.Client.SendMessage(ctx, jid, &waE2E.Message{
-			Conversation: &message,
-		})
-		cancel()
+// Logout unlinks the device from the phone and prepares a fresh device for
+// pairing again. When WhatsApp cannot be reached the local session is dropped anyway.
+func (s *WhatsAppUsecase) Logout(ctx context.Context) error {
+	client := s.currentClient()
+	if client.Store.ID == nil {
+		return errors.New("WhatsApp belum tertaut")
+	}
 
-		if err == nil {
-			return nil
+	if err := client.Logout(ctx); err != nil {
+		fmt.Printf("⚠️ Logout WhatsApp gagal, sesi lokal tetap dihapus: %v\n", err)
+		client.Disconnect()
+		if err := client.Store.Delete(ctx); err != nil {
+			return fmt.Errorf("sesi WhatsApp gagal dihapus: %w", err)
 		}
+	}
 
-		fmt.Printf("⚠️ Gagal kirim ke %s (Percobaan %d/3): %v. Mencoba lagi...\n", phone, i+1, err)
-		time.Sleep(1 * time.Second)
+	s.replaceClient(client, "")
+	return nil
+}
+
+func (s *WhatsAppUsecase) Send(ctx context.Context, n Notification) error {
+	return s.SendMessage(ctx, n.To, n.Body)
+}
+
+// SendMessage sends one text message. It does not retry; failed messages are
+// retried by the message queue.
+func (s *WhatsAppUsecase) SendMessage(ctx context.Context, phone string, message string) error {
+	phone = utils.NormalizePhone(phone)
+
+	client := s.currentClient()
+	if !client.IsConnected() || !client.IsLoggedIn() {
+		return fmt.Errorf("whatsapp belum terhubung")
+	}
+
+	jid, err := types.ParseJID(phone + "@s.whatsapp.net")
+	if err != nil {
+		return fmt.Errorf("nomor WhatsApp %s tidak valid: %v", phone, err)
 	}
 
-	return fmt.Errorf("gagal mengirim pesan setelah 3x percobaan: %v", err)
+	_, err = client.SendMessage(ctx, jid, &waE2E.Message{
+		Conversation: &message,
+	})
+	return err
 }
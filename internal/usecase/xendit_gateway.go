@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+
+	xendit "github.com/xendit/xendit-go/v7"
+	"github.com/xendit/xendit-go/v7/invoice"
+)
+
+type xenditGateway struct {
+	client *xendit.APIClient
+}
+
+func NewXenditGateway(client *xendit.APIClient) PaymentGateway {
+	return &xenditGateway{client: client}
+}
+
+func (g *xenditGateway) Name() string {
+	return "xendit"
+}
+
+func (g *xenditGateway) CreateInvoice(ctx context.Context, req InvoiceRequest) (*PaymentInvoice, error) {
+	createInvoiceRequest := *invoice.NewCreateInvoiceRequest(
+		req.ExternalID,
+		float64(req.Amount),
+	)
+
+	createInvoiceRequest.SetDescription(req.Description)
+	createInvoiceRequest.SetSuccessRedirectUrl(req.SuccessURL)
+	createInvoiceRequest.SetFailureRedirectUrl(req.FailureURL)
+	createInvoiceRequest.SetCurrency("IDR")
+	createInvoiceRequest.SetReminderTime(1)
+	if req.Duration > 0 {
+		createInvoiceRequest.SetInvoiceDuration(float32(req.Duration.Seconds()))
+	}
+
+	resp, _, err := g.client.InvoiceApi.
+		CreateInvoice(ctx).
+		CreateInvoiceRequest(createInvoiceRequest).
+		Execute()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return toPaymentInvoice(resp), nil
+}
+
+func (g *xenditGateway) FindInvoiceByExternalID(ctx context.Context, externalID string) (*PaymentInvoice, error) {
+	invoices, _, err := g.client.InvoiceApi.
+		GetInvoices(ctx).
+		ExternalId(externalID).
+		Execute()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(invoices) == 0 {
+		return nil, nil
+	}
+	return toPaymentInvoice(&invoices[0]), nil
+}
+
+func (g *xenditGateway) GetInvoice(ctx context.Context, invoiceID string) (*PaymentInvoice, error) {
+	resp, _, err := g.client.InvoiceApi.GetInvoiceById(ctx, invoiceID).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return toPaymentInvoice(resp), nil
+}
+
+func (g *xenditGateway) ExpireInvoice(ctx context.Context, invoiceID string) (*PaymentInvoice, error) {
+	resp, _, err := g.client.InvoiceApi.ExpireInvoice(ctx, invoiceID).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return toPaymentInvoice(resp), nil
+}
+
+func toPaymentInvoice(inv *invoice.Invoice) *PaymentInvoice {
+	return &PaymentInvoice{
+		ID:         inv.GetId(),
+		ExternalID: inv.ExternalId,
+		URL:        inv.InvoiceUrl,
+		Status:     string(inv.Status),
+		Amount:     int(inv.Amount),
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"html/template"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -41,25 +42,80 @@ func SetupViewEngine(r *gin.Engine) {
 			return t.Format("02 Jan 2006, 15:04")
 		},
 
+		"minutesSince": func(t time.Time) int {
+			return int(time.Since(t).Minutes())
+		},
+
 		"add": func(a, b int) int {
 			return a + b
 		},
 
+		"deref": func(v *int) int {
+			if v == nil {
+				return 0
+			}
+			return *v
+		},
+
 		"statusColor": func(status string) string {
 			switch strings.ToLower(status) {
-			case "paid", "ready", "completed", "active":
+			case "paid", "ready", "completed", "active", "sent":
 				return "bg-green-100 text-green-800 border border-green-200"
-			case "pending":
+			case "pending", "queued":
 				return "bg-yellow-100 text-yellow-800 border border-yellow-200"
-			case "confirmed", "preparing":
+			case "confirmed", "preparing", "sending":
 				return "bg-blue-100 text-blue-800 border border-blue-200"
-			case "cancelled", "expired", "inactive":
+			case "cancelled", "expired", "inactive", "dead":
 				return "bg-red-100 text-red-800 border border-red-200"
 			default:
 				return "bg-gray-100 text-gray-800"
 			}
 		},
 
+		"notifyChannelLabel": model.NotifyChannelLabel,
+		"messageStatusLabel": model.MessageStatusLabel,
+
+		// messageIDs joins message IDs for the ?ids= query of the status poll.
+		"messageIDs": func(messages []model.OutboundMessage) string {
+			ids := make([]string, len(messages))
+			for i, m := range messages {
+				ids[i] = strconv.FormatUint(uint64(m.ID), 10)
+			}
+			return strings.Join(ids, ",")
+		},
+
+		"messagesPending": func(messages []model.OutboundMessage) bool {
+			for _, m := range messages {
+				if !m.IsFinal() {
+					return true
+				}
+			}
+			return false
+		},
+
+		"canTransition": func(from, to string) bool {
+			return from == to || model.CanTransitionOrderStatus(from, to)
+		},
+
+		"historyActor": func(h model.OrderStatusHistory) string {
+			switch h.ActorType {
+			case model.ActorAdmin:
+				if h.Admin != nil {
+					return "Admin " + h.Admin.Username
+				}
+				return "Admin"
+			case model.ActorXendit:
+				return "Xendit"
+			case model.ActorBooth:
+				if h.Booth != nil {
+					return "Dapur " + h.Booth.Name
+				}
+				return "Dapur"
+			default:
+				return "Sistem"
+			}
+		},
+
 		"dict": func(values ...interface{}) (map[string]interface{}, error) {
 			if len(values)%2 != 0 {
 				return nil, fmt.Errorf("invalid dict call")
@@ -75,13 +131,27 @@ func SetupViewEngine(r *gin.Engine) {
 			return dict, nil
 		},
 
+		"canTransitionTicket": func(from, to string) bool {
+			return from == to || model.CanTransitionTicketStatus(from, to)
+		},
+
 		"groupByBooth": func(order model.Order) []map[string]interface{} {
 			grouped := make(map[uint]map[string]interface{})
+			var boothOrder []uint
 
 			for _, item := range order.Items {
 				boothID := item.BoothID
 
 				if _, exists := grouped[boothID]; !exists {
+					boothOrder = append(boothOrder, boothID)
+
+					var ticket *model.BoothTicket
+					for i := range order.Tickets {
+						if order.Tickets[i].BoothID == boothID {
+							ticket = &order.Tickets[i]
+							break
+						}
+					}
 
 					isNotified := false
 					for _, log := range order.Logs {
@@ -92,10 +162,19 @@ func SetupViewEngine(r *gin.Engine) {
 						}
 					}
 
+					var messages []model.OutboundMessage
+					for _, m := range order.Messages {
+						if m.BoothID != nil && *m.BoothID == boothID {
+							messages = append(messages, m)
+						}
+					}
+
 					grouped[boothID] = map[string]interface{}{
 						"Booth":      item.Booth,
 						"Items":      []model.OrderItem{},
 						"IsNotified": isNotified,
+						"Ticket":     ticket,
+						"Messages":   messages,
 					}
 				}
 
@@ -104,8 +183,8 @@ func SetupViewEngine(r *gin.Engine) {
 			}
 
 			var result []map[string]interface{}
-			for _, v := range grouped {
-				result = append(result, v)
+			for _, boothID := range boothOrder {
+				result = append(result, grouped[boothID])
 			}
 			return result
 		},
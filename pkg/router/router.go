@@ -1,10 +1,16 @@
 package router
 
 import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Rakhulsr/foodcourt/config"
 	"github.com/Rakhulsr/foodcourt/internal/delivery/http"
 	adminHandler "github.com/Rakhulsr/foodcourt/internal/delivery/http/admin"
 	"github.com/Rakhulsr/foodcourt/internal/delivery/http/client"
 	"github.com/Rakhulsr/foodcourt/internal/middleware"
+	"github.com/Rakhulsr/foodcourt/internal/model"
 	"github.com/Rakhulsr/foodcourt/internal/repository"
 	"github.com/Rakhulsr/foodcourt/internal/usecase"
 	"github.com/Rakhulsr/foodcourt/pkg/engine"
@@ -15,6 +21,10 @@ import (
 func NewRouter(db *gorm.DB) *gin.Engine {
 
 	r := gin.New()
+	// Rate limits key on ClientIP, so only configured proxies may forward it.
+	if err := r.SetTrustedProxies(config.TrustedProxies()); err != nil {
+		log.Fatal("TRUSTED_PROXIES:", err)
+	}
 	r.Use(gin.Recovery())
 	engine.SetupViewEngine(r)
 
@@ -23,30 +33,68 @@ func NewRouter(db *gorm.DB) *gin.Engine {
 
 	boothRepo := repository.NewBoothRepository(db)
 	menuRepo := repository.NewMenuRepository(db)
+	menuOptionRepo := repository.NewMenuOptionRepository(db)
+	bundleRepo := repository.NewBundleRepository(db)
+	voucherRepo := repository.NewVoucherRepository(db)
+	tableRepo := repository.NewTableRepository(db)
 	adminRepo := repository.NewAdminRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
 	logRepo := repository.NewWhatsAppLogRepository(db)
+	ticketRepo := repository.NewTicketRepository(db)
+	outboxRepo := repository.NewPaymentOutboxRepository(db)
+	idemRepo := repository.NewIdempotencyRepository(db)
+	eventRepo := repository.NewPaymentEventRepository(db)
+	messageRepo := repository.NewMessageRepository(db)
 
-	waUC := usecase.NewWhattsAppUsecase()
+	wa := newWhatsApp()
+	notifier := newNotifier(wa)
+	logUC := usecase.NewLogUseCase(logRepo)
+	messageQueue := usecase.NewMessageQueue(messageRepo, notifier, logUC)
 	boothUC := usecase.NewBoothUseCase(boothRepo)
-	menuUC := usecase.NewMenuUseCase(menuRepo, boothRepo)
+	menuUC := usecase.NewMenuUseCase(menuRepo, boothRepo, menuOptionRepo)
+	bundleUC := usecase.NewBundleUseCase(bundleRepo, menuRepo)
+	voucherUC := usecase.NewVoucherUseCase(voucherRepo)
 	authUC := usecase.NewAuthUseCase(adminRepo)
-	paymentUC := usecase.NewPaymentService()
 
-	logUC := usecase.NewLogUseCase(logRepo)
-	orderUC := usecase.NewOrderUsecase(orderRepo, menuRepo, paymentUC, *waUC, logUC)
+	gateway, err := usecase.NewPaymentGateway(config.PaymentProvider())
+	if err != nil {
+		log.Fatal("Payment gateway:", err)
+	}
+	log.Println("Payment provider:", gateway.Name())
+	paymentUC := usecase.NewPaymentService(gateway)
+
+	tableUC := usecase.NewTableUseCase(tableRepo, orderRepo, config.TableLinkSecret())
+	orderUC := usecase.NewOrderUsecase(orderRepo, menuRepo, bundleRepo, voucherRepo, tableRepo, ticketRepo, outboxRepo, idemRepo, eventRepo, paymentUC, messageQueue)
+	kitchenUC := usecase.NewKitchenUseCase(boothRepo, orderRepo, orderUC)
+	displayUC := usecase.NewDisplayUseCase(orderRepo)
+
+	if wa != nil {
+		wa.SetCommands(usecase.NewWhatsAppCommandUseCase(boothRepo, menuRepo, orderRepo, orderUC, logUC))
+	}
+
+	go orderUC.RunPaymentOutbox(context.Background(), 15*time.Second)
+	go orderUC.RunPaymentReconciliation(context.Background(), time.Minute)
+	go orderUC.RunOrderExpiry(context.Background(), time.Minute)
+	go messageQueue.Run(context.Background(), config.MessageWorkers(), 5*time.Second)
 
 	adminMenuHandler := adminHandler.NewMenuHandler(menuUC, boothUC)
 	adminBoothHandler := adminHandler.NewBoothHandler(boothUC)
+	adminBundleHandler := adminHandler.NewBundleHandler(bundleUC, menuUC)
+	adminVoucherHandler := adminHandler.NewVoucherHandler(voucherUC, boothUC)
+	adminTableHandler := adminHandler.NewTableHandler(tableUC)
 	adminOrderHandler := adminHandler.NewOrderHandler(orderUC)
-	dashboardHandler := adminHandler.NewDashboardHandler(orderRepo)
+	dashboardHandler := adminHandler.NewDashboardHandler(orderRepo, orderUC)
 	adminLogHandler := adminHandler.NewLogHandler(logUC)
+	adminMessageHandler := adminHandler.NewMessageHandler(messageQueue)
+	adminWhatsAppHandler := adminHandler.NewWhatsAppHandler(wa)
 
-	menuHandler := client.NewMenuHandler(menuUC, boothUC)
-	cartHandler := client.NewCartHandler(menuUC)
-	orderHandler := client.NewOrderHandler(orderUC)
+	menuHandler := client.NewMenuHandler(menuUC, boothUC, bundleUC)
+	cartHandler := client.NewCartHandler(menuUC, bundleUC, orderUC, tableUC)
+	orderHandler := client.NewOrderHandler(orderUC, tableUC)
 
 	authHandler := http.NewAuthHandler(authUC)
+	kitchenHandler := http.NewKitchenHandler(kitchenUC, orderUC)
+	displayHandler := http.NewDisplayHandler(displayUC, orderUC)
 
 	r.GET("/", menuHandler.ClientHome)
 	r.GET("/home", menuHandler.ClientHome)
@@ -56,8 +104,39 @@ func NewRouter(db *gorm.DB) *gin.Engine {
 	r.POST("/cart/update", cartHandler.UpdateCartItem)
 	r.POST("/cart/proceed", cartHandler.ProceedCheckout)
 	r.GET("/checkout", cartHandler.ShowCheckoutPage)
+	r.GET("/t/:code", cartHandler.ScanTable)
 
-	r.GET("/order/success/:code", orderHandler.ShowSuccessPage)
+	// Customer order links need the order's access token; guessing is rate limited.
+	orderLookupLimit := middleware.LimitFailedLookups(10, 15*time.Minute)
+
+	customerOrders := r.Group("/order", orderLookupLimit)
+	{
+		customerOrders.GET("/success/:code", orderHandler.ShowSuccessPage)
+		customerOrders.GET("/track/:code", orderHandler.ShowTracking)
+		customerOrders.GET("/track/:code/status", orderHandler.TrackingStatus)
+		customerOrders.GET("/track/:code/events", orderHandler.TrackingEvents)
+	}
+
+	if mockGateway, ok := gateway.(*usecase.MockPaymentGateway); ok {
+		mockPaymentHandler := client.NewMockPaymentHandler(mockGateway)
+
+		r.GET("/mock-payment/:id", mockPaymentHandler.Show)
+		r.POST("/mock-payment/:id/pay", mockPaymentHandler.Pay)
+		r.POST("/mock-payment/:id/expire", mockPaymentHandler.Expire)
+	}
+
+	r.GET("/display", displayHandler.Show)
+	r.GET("/display/board", displayHandler.Board)
+	r.GET("/display/events", displayHandler.Events)
+
+	kitchen := r.Group("/kitchen/:token")
+	kitchen.Use(middleware.BoothTokenAuth(kitchenUC.Authenticate))
+	{
+		kitchen.GET("", kitchenHandler.Show)
+		kitchen.GET("/tickets", kitchenHandler.Tickets)
+		kitchen.GET("/events", kitchenHandler.Events)
+		kitchen.POST("/tickets/:ticketId/:action", kitchenHandler.Advance)
+	}
 
 	api := r.Group("/api")
 	{
@@ -66,6 +145,7 @@ func NewRouter(db *gorm.DB) *gin.Engine {
 		})
 
 		api.POST("/orders", orderHandler.Create)
+		api.GET("/orders/:code", orderLookupLimit, orderHandler.GetOrderDetail)
 
 		api.POST("/webhooks/xendit", orderHandler.HandleXenditWebhook)
 
@@ -83,6 +163,7 @@ func NewRouter(db *gorm.DB) *gin.Engine {
 			adminRoutes.GET("/booths/edit/:id", adminBoothHandler.ShowEditForm)
 			adminRoutes.PUT("/booths/:id", adminBoothHandler.Update)
 			adminRoutes.DELETE("/booths/:id", adminBoothHandler.Delete)
+			adminRoutes.POST("/booths/:id/kitchen-token", adminBoothHandler.RotateKitchenToken)
 
 			adminRoutes.GET("/menus", adminMenuHandler.ListAll)
 			adminRoutes.GET("/menus/create", adminMenuHandler.ShowCreateForm)
@@ -90,14 +171,59 @@ func NewRouter(db *gorm.DB) *gin.Engine {
 			adminRoutes.GET("/menus/edit/:id", adminMenuHandler.ShowEditForm)
 			adminRoutes.PUT("/menus/:id", adminMenuHandler.Update)
 			adminRoutes.DELETE("/menus/:id", adminMenuHandler.Delete)
+			adminRoutes.GET("/menus/stock", adminMenuHandler.ShowStock)
+			adminRoutes.POST("/menus/:id/stock", adminMenuHandler.Restock)
+			adminRoutes.GET("/menus/:id/options", adminMenuHandler.ShowOptions)
+			adminRoutes.POST("/menus/:id/option-groups", adminMenuHandler.AddOptionGroup)
+			adminRoutes.POST("/menus/:id/option-groups/:groupId/delete", adminMenuHandler.DeleteOptionGroup)
+			adminRoutes.POST("/menus/:id/option-groups/:groupId/options", adminMenuHandler.AddOption)
+			adminRoutes.POST("/menus/:id/options/:optionId/toggle", adminMenuHandler.ToggleOption)
+			adminRoutes.POST("/menus/:id/options/:optionId/delete", adminMenuHandler.DeleteOption)
+
+			adminRoutes.GET("/bundles", adminBundleHandler.List)
+			adminRoutes.POST("/bundles", adminBundleHandler.Create)
+			adminRoutes.POST("/bundles/:id/toggle", adminBundleHandler.ToggleActive)
+			adminRoutes.POST("/bundles/:id/delete", adminBundleHandler.Delete)
+
+			adminRoutes.GET("/vouchers", adminVoucherHandler.List)
+			adminRoutes.GET("/vouchers/create", adminVoucherHandler.ShowCreateForm)
+			adminRoutes.GET("/vouchers/report", adminVoucherHandler.Report)
+			adminRoutes.POST("/vouchers", adminVoucherHandler.Create)
+			adminRoutes.GET("/vouchers/edit/:id", adminVoucherHandler.ShowEditForm)
+			adminRoutes.POST("/vouchers/:id", adminVoucherHandler.Update)
+			adminRoutes.POST("/vouchers/:id/toggle", adminVoucherHandler.ToggleActive)
+			adminRoutes.POST("/vouchers/:id/delete", adminVoucherHandler.Delete)
+
+			adminRoutes.GET("/tables", adminTableHandler.List)
+			adminRoutes.GET("/tables/board", adminTableHandler.Board)
+			adminRoutes.GET("/tables/qr-sheet", adminTableHandler.QRSheet)
+			adminRoutes.GET("/tables/:id/qr", adminTableHandler.QRCode)
+			adminRoutes.POST("/tables", adminTableHandler.Create)
+			adminRoutes.POST("/tables/:id", adminTableHandler.Update)
+			adminRoutes.POST("/tables/:id/toggle", adminTableHandler.ToggleActive)
+			adminRoutes.POST("/tables/:id/delete", adminTableHandler.Delete)
 
 			adminRoutes.GET("/orders", adminOrderHandler.AdminList)
 			adminRoutes.PATCH("/orders/:code/status", adminOrderHandler.AdminUpdateStatus)
 			adminRoutes.POST("/orders/:code/notify", adminOrderHandler.SendNotification)
+			adminRoutes.POST("/orders/:code/recheck-payment", adminOrderHandler.RecheckPayment)
+			adminRoutes.PATCH("/orders/:code/tickets/:id/status", adminOrderHandler.AdminUpdateTicketStatus)
+			adminRoutes.POST("/orders/:code/tickets/:id/notify", adminOrderHandler.SendTicketNotification)
 
 			adminRoutes.GET("/logs", adminLogHandler.List)
 
 			adminRoutes.GET("/logs/track", adminLogHandler.TrackAndRedirect)
+
+			adminRoutes.GET("/messages", adminMessageHandler.List)
+			adminRoutes.GET("/messages/status", adminMessageHandler.Status)
+			adminRoutes.POST("/messages/:id/requeue", adminMessageHandler.Requeue)
+			adminRoutes.POST("/messages/:id/cancel", adminMessageHandler.Cancel)
+
+			adminRoutes.GET("/whatsapp", adminWhatsAppHandler.Show)
+			adminRoutes.GET("/whatsapp/status", adminWhatsAppHandler.Status)
+			adminRoutes.GET("/whatsapp/qr.png", adminWhatsAppHandler.QRCode)
+			adminRoutes.POST("/whatsapp/connect", adminWhatsAppHandler.Connect)
+			adminRoutes.POST("/whatsapp/logout", adminWhatsAppHandler.Logout)
 		}
 	}
 
@@ -114,3 +240,37 @@ func NewRouter(db *gorm.DB) *gin.Engine {
 
 	return r
 }
+
+// newWhatsApp starts the WhatsApp session, or returns nil when WhatsApp is turned
+// off or its session store cannot be opened.
+func newWhatsApp() *usecase.WhatsAppUsecase {
+	if !config.WhatsAppEnabled() {
+		return nil
+	}
+
+	wa, err := usecase.NewWhattsAppUsecase()
+	if err != nil {
+		log.Println("WhatsApp disabled:", err)
+		return nil
+	}
+	return wa
+}
+
+// newNotifier registers every notification channel that is configured. A missing
+// WhatsApp session or mail server only disables that channel.
+func newNotifier(wa *usecase.WhatsAppUsecase) *usecase.NotifierRouter {
+	notifier := usecase.NewNotifierRouter()
+	notifier.Register(model.NotifyChannelLog, usecase.NewLogNotifier())
+	notifier.Register(model.NotifyChannelWebhook, usecase.NewWebhookNotifier(config.NotifyWebhookSecret()))
+
+	if smtpConfig, ok := config.SMTP(); ok {
+		notifier.Register(model.NotifyChannelEmail, usecase.NewEmailNotifier(smtpConfig))
+	}
+
+	if wa != nil {
+		notifier.Register(model.NotifyChannelWhatsApp, wa)
+	}
+
+	log.Println("Notification channels:", notifier.Channels())
+	return notifier
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var nonDigits = regexp.MustCompile(`[^0-9]`)
+
+// NormalizePhone turns a phone number as typed ("0812-3456", "+62 812 3456") into
+// the international digits WhatsApp uses ("628123456").
+func NormalizePhone(phone string) string {
+	phone = nonDigits.ReplaceAllString(phone, "")
+	if strings.HasPrefix(phone, "0") {
+		phone = "62" + phone[1:]
+	}
+	return phone
+}
+
+var mobilePhone = regexp.MustCompile(`^628[0-9]{7,11}$`)
+
+// IsValidPhone reports whether a normalized number looks like an Indonesian
+// mobile number that can receive WhatsApp messages.
+func IsValidPhone(phone string) bool {
+	return mobilePhone.MatchString(phone)
+}
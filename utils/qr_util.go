@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+
+	"rsc.io/qr"
+)
+
+// qrQuietZone is the white border, in modules, that scanners need around a code.
+const qrQuietZone = 4
+
+// QRPNG renders text as a QR code PNG with scale pixels per module.
+func QRPNG(text string, scale int) ([]byte, error) {
+	code, err := qr.Encode(text, qr.M)
+	if err != nil {
+		return nil, err
+	}
+	code.Scale = scale
+	return code.PNG(), nil
+}
+
+// QRSVG renders text as a QR code SVG that scales to any print size.
+func QRSVG(text string) ([]byte, error) {
+	code, err := qr.Encode(text, qr.M)
+	if err != nil {
+		return nil, err
+	}
+
+	side := code.Size + 2*qrQuietZone
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, side, side)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, side, side)
+
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; {
+			if !code.Black(x, y) {
+				x++
+				continue
+			}
+			run := 1
+			for code.Black(x+run, y) {
+				run++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", x+qrQuietZone, y+qrQuietZone, run, run)
+			x += run
+		}
+	}
+
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes(), nil
+}
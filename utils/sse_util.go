@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseHeartbeat keeps idle event streams from being closed by proxies.
+const sseHeartbeat = 25 * time.Second
+
+// StreamEvents holds the request open as a server-sent event stream. It sends a
+// "ready" event, then calls emit for every value received until the client goes
+// away or events is closed.
+func StreamEvents[T any](c *gin.Context, events <-chan T, emit func(T)) {
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("ready", time.Now().Unix())
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			emit(event)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
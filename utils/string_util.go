@@ -0,0 +1,17 @@
+package utils
+
+// Truncate shortens s to at most max characters without splitting a multi-byte one.
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	count := 0
+	for i := range s {
+		if count == max {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
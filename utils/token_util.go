@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// SecureToken returns a random hex token of n bytes for links that grant access,
+// where RandomString is not unpredictable enough.
+func SecureToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// codeAlphabet leaves out characters that are easily misread: 0/O, 1/I/L.
+const codeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// OrderCode returns a new order code such as "ORD-7KQ2MZ9XHD", drawn from crypto/rand.
+// With 31^10 possible codes collisions are rare, but callers still retry on one.
+func OrderCode() string {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	for i := range b {
+		// 256 is not a multiple of 31, leaving a slight bias that does not matter here.
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+	return "ORD-" + string(b)
+}